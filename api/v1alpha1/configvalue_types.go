@@ -0,0 +1,52 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// JaasFileName is the JAAS configuration of the brokers, only generated with Kerberos
+	JaasFileName = "jaas.conf"
+
+	// KubedoopConfigValuesDir holds the Secret and ConfigMap keys referenced by serverPropertiesFrom
+	KubedoopConfigValuesDir = KubedoopRoot + "/config-values"
+)
+
+// ConfigValueFromSpec sets a server.properties entry to the value of a Secret or ConfigMap key.
+// The broker resolves the value at startup with a Kafka config provider,
+// it is never copied into the role group ConfigMap.
+type ConfigValueFromSpec struct {
+	// Name of the server.properties entry, e.g. `ssl.key.password`.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Source of the value, exactly one of its fields must be set.
+	// +kubebuilder:validation:Required
+	ValueFrom ConfigValueSource `json:"valueFrom"`
+}
+
+// ConfigValueSource selects a key of a Secret or ConfigMap in the namespace of the cluster.
+type ConfigValueSource struct {
+	// +kubebuilder:validation:Optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// JvmConfigFileName holds the effective JVM settings in the role group ConfigMap, for reference only
+const JvmConfigFileName = "jvm.env"
+
+// JvmSpec configures the JVM of the brokers.
+// The heap is sized from the memory limit of the role group.
+type JvmSpec struct {
+	// Heap size (-Xms and -Xmx), either a percentage of the memory limit, e.g. `50%`, or a quantity, e.g. `2Gi`.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^([0-9]{1,2}%|100%|[0-9]+(\.[0-9]+)?(Ki|Mi|Gi|Ti|k|M|G|T)?)$`
+	// +kubebuilder:default="50%"
+	Heap string `json:"heap,omitempty"`
+
+	// Memory of the limit kept free for the page cache Kafka relies on, either a percentage or a quantity.
+	// The heap is reduced if it would not leave this much memory.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^([0-9]{1,2}%|100%|[0-9]+(\.[0-9]+)?(Ki|Mi|Gi|Ti|k|M|G|T)?)$`
+	// +kubebuilder:default="25%"
+	PageCacheReservation string `json:"pageCacheReservation,omitempty"`
+
+	// Garbage collector options, e.g. `["-XX:+UseZGC"]`.
+	// They replace the GC options of the Kafka start script (KAFKA_JVM_PERFORMANCE_OPTS),
+	// so two collectors are never selected at once.
+	// +kubebuilder:validation:Optional
+	GcOptions []string `json:"gcOptions,omitempty"`
+
+	// Extra JVM arguments, appended after the arguments set by the operator.
+	// +kubebuilder:validation:Optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// JVM arguments set by the operator to remove, compared exactly.
+	// +kubebuilder:validation:Optional
+	RemoveArgs []string `json:"removeArgs,omitempty"`
+
+	// JVM arguments set by the operator to remove, matched by regular expression.
+	// +kubebuilder:validation:Optional
+	RemoveArgsRegex []string `json:"removeArgsRegex,omitempty"`
+}
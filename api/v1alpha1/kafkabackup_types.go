@@ -0,0 +1,156 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeBackupReady is true, when the last backup Job succeeded and its snapshot is stored
+	ConditionTypeBackupReady = "Ready"
+
+	// BackupLabel is set on the Jobs of a KafkaBackup, it holds the name of the backup
+	BackupLabel = "kafka.kubedoop.dev/backup"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterRef`
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Last Backup",type=date,JSONPath=`.status.lastBackupTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// KafkaBackup is the Schema for the kafkabackups API
+type KafkaBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBackupSpec   `json:"spec,omitempty"`
+	Status KafkaBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaBackupList contains a list of KafkaBackup
+type KafkaBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaBackup `json:"items"`
+}
+
+// KafkaBackupSpec defines the desired state of KafkaBackup
+type KafkaBackupSpec struct {
+	// Name of the KafkaCluster in the same namespace to back up.
+	// +kubebuilder:validation:Required
+	ClusterRef string `json:"clusterRef"`
+
+	// Cron schedule of the backups, e.g. `0 2 * * *`.
+	// If not set, the cluster is backed up once for every generation of the KafkaBackup.
+	// +kubebuilder:validation:Optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Target BackupTargetSpec `json:"target"`
+
+	// Number of snapshots to keep, older snapshots are deleted from the target.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=5
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+}
+
+// BackupTargetSpec is where the snapshots of a backup are stored. Exactly one target must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.configMap) != has(self.s3)",message="exactly one of configMap and s3 must be set"
+type BackupTargetSpec struct {
+	// Store every snapshot in a ConfigMap in the namespace of the backup, named after the snapshot.
+	// Snapshots are limited to 1MiB.
+	// +kubebuilder:validation:Optional
+	ConfigMap *ConfigMapBackupTargetSpec `json:"configMap,omitempty"`
+
+	// Store every snapshot as an object in an S3 compatible bucket, e.g. MinIO.
+	// +kubebuilder:validation:Optional
+	S3 *S3BackupTargetSpec `json:"s3,omitempty"`
+}
+
+type ConfigMapBackupTargetSpec struct {
+	// Labels of the snapshot ConfigMaps.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type S3BackupTargetSpec struct {
+	// URL of the S3 API, e.g. `http://minio:9000`. Buckets are addressed path style.
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Prefix of the object keys, e.g. `kafka/prod/`.
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="us-east-1"
+	Region string `json:"region,omitempty"`
+
+	// Name of the Secret in the same namespace holding the credentials in the keys `accessKey` and `secretKey`.
+	// +kubebuilder:validation:Required
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// KafkaBackupStatus defines the observed state of KafkaBackup
+type KafkaBackupStatus struct {
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// The snapshots kept in the target, oldest first.
+	// +kubebuilder:validation:Optional
+	Snapshots []BackupSnapshotStatus `json:"snapshots,omitempty"`
+}
+
+type BackupSnapshotStatus struct {
+	// Name of the snapshot, referenced by KafkaRestore.
+	Name string `json:"name"`
+
+	Time metav1.Time `json:"time"`
+
+	// +kubebuilder:validation:Optional
+	Topics int32 `json:"topics,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ACLs int32 `json:"acls,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Users int32 `json:"users,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ConsumerGroups int32 `json:"consumerGroups,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaBackup{}, &KafkaBackupList{})
+}
@@ -80,8 +80,51 @@ type KafkaCluster struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   KafkaClusterSpec `json:"spec,omitempty"`
-	Status status.Status    `json:"status,omitempty"`
+	Spec   KafkaClusterSpec   `json:"spec,omitempty"`
+	Status KafkaClusterStatus `json:"status,omitempty"`
+}
+
+// ConditionTypeVersionSupported is false if the product version is not in the version catalog of the operator,
+// nothing is reconciled until it is changed
+const ConditionTypeVersionSupported = "VersionSupported"
+
+const (
+	// ConditionTypeReconciliationPaused is true while clusterOperation.reconciliationPaused is set,
+	// the operator does not change any resource of the cluster
+	ConditionTypeReconciliationPaused = "ReconciliationPaused"
+	// ConditionTypeStopped is true while clusterOperation.stopped is set, the role groups are scaled to zero
+	// while their volumes, listeners and discovery ConfigMaps are kept
+	ConditionTypeStopped = "Stopped"
+)
+
+const (
+	// RestartAnnotationPrefix followed by the name of a broker role group is set on a KafkaCluster to restart
+	// the brokers of the role group, e.g. by `kubectl kafka restart`. Every new value rolls the pods once.
+	RestartAnnotationPrefix = "restart.kafka.kubedoop.dev/"
+	// RestartedAtAnnotation holds the value of the restart annotation of the role group on its pod template
+	RestartedAtAnnotation = "kafka.kubedoop.dev/restarted-at"
+)
+
+// KafkaClusterStatus defines the observed state of KafkaCluster
+type KafkaClusterStatus struct {
+	status.Status `json:",inline"`
+
+	// Broker ID offset of each broker role group. Offsets chosen by the operator are kept here,
+	// so they stay stable when role groups are added or removed.
+	// +kubebuilder:validation:Optional
+	BrokerIdOffsets map[string]int32 `json:"brokerIdOffsets,omitempty"`
+
+	// Broker ID of each broker pod, role groups keeping auto-generated IDs are not listed.
+	// +kubebuilder:validation:Optional
+	BrokerIds map[string]int32 `json:"brokerIds,omitempty"`
+
+	// Kafka version the brokers run, their inter.broker.protocol.version is the one of its release line.
+	// +kubebuilder:validation:Optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// Upgrade of the brokers to a new Kafka release in progress.
+	// +kubebuilder:validation:Optional
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -107,6 +150,10 @@ type KafkaClusterSpec struct {
 
 	// +kubebuilder:validation:Required
 	Brokers *BrokersSpec `json:"brokers,omitempty"`
+
+	// An optional REST proxy giving clients which can only speak HTTP access to the cluster.
+	// +kubebuilder:validation:Optional
+	RestProxy *RestProxySpec `json:"restProxy,omitempty"`
 }
 
 type ClusterConfigSpec struct {
@@ -125,6 +172,23 @@ type ClusterConfigSpec struct {
 
 	// +kubebuilder:validation:required
 	ZookeeperConfigMapName string `json:"zookeeperConfigMapName,omitempty"`
+
+	// Prometheus Operator monitors and alerts of the cluster.
+	// +kubebuilder:validation:Optional
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+
+	// Rack awareness of the brokers from node labels.
+	// +kubebuilder:validation:Optional
+	RackAwareness *RackAwarenessSpec `json:"rackAwareness,omitempty"`
+
+	// Upgrades of the brokers to a new Kafka release.
+	// +kubebuilder:validation:Optional
+	Upgrade *UpgradeSpec `json:"upgrade,omitempty"`
+
+	// Pre-populate the data volumes of the brokers from KafkaSnapshots, when the volumes are created.
+	// Existing volumes are not changed.
+	// +kubebuilder:validation:Optional
+	DataSnapshots []DataSnapshotSourceSpec `json:"dataSnapshots,omitempty"`
 }
 
 type KafkaTlsSpec struct {
@@ -177,9 +241,30 @@ type BrokersSpec struct {
 	// +kubebuilder:validation:Optional
 	Roleconfig *commonsv1alpha1.RoleConfigSpec `json:"roleconfig,omitempty"`
 
+	// How maxUnavailable of the broker PodDisruptionBudget is derived when
+	// `roleconfig.podDisruptionBudget.maxUnavailable` is not set:
+	//  - Fixed: one broker may be unavailable at a time.
+	//  - Durability: the lowest margin of replication factor over minimum ISR of the topics the brokers create,
+	//    i.e. `default.replication.factor` and the internal offsets and transaction topics, so a drain never makes
+	//    a partition drop below its minimum ISR. The settings are the `server.properties` overrides of all role
+	//    groups, falling back to the Kafka defaults. Topics created with their own settings and values set by
+	//    `serverPropertiesFrom` are not considered. Without a margin, e.g. with the Kafka defaults (both 1), one
+	//    broker may be unavailable like with Fixed, so node drains are not blocked.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Fixed;Durability
+	// +kubebuilder:default=Fixed
+	PodDisruptionBudgetPolicy PodDisruptionBudgetPolicy `json:"podDisruptionBudgetPolicy,omitempty"`
+
 	*commonsv1alpha1.OverridesSpec `json:",inline"`
 }
 
+type PodDisruptionBudgetPolicy string
+
+const (
+	PodDisruptionBudgetPolicyFixed      PodDisruptionBudgetPolicy = "Fixed"
+	PodDisruptionBudgetPolicyDurability PodDisruptionBudgetPolicy = "Durability"
+)
+
 type BrokersRoleGroupSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=1
@@ -188,6 +273,14 @@ type BrokersRoleGroupSpec struct {
 	// +kubebuilder:validation：Optional
 	Config *BrokersConfigSpec `json:"config,omitempty"`
 
+	// The `broker.id` of the pod with ordinal 0, the other pods get consecutive IDs.
+	// The ID ranges of the role groups must not overlap and must stay below `reserved.broker.max.id` (1000 by default).
+	// If not set, the operator picks the lowest free multiple of 100 and keeps it in the status.
+	// Role groups whose brokers already wrote data with IDs auto-generated by ZooKeeper keep them until an offset is set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	BrokerIdOffset *int32 `json:"brokerIdOffset,omitempty"`
+
 	*commonsv1alpha1.OverridesSpec `json:",inline"`
 }
 
@@ -208,6 +301,19 @@ type BrokersConfigSpec struct {
 	// Please note that this can be shortened by the `maxCertificateLifetime` setting on the SecretClass issuing the TLS certificate.
 	// +kubebuilder:validation:Optional
 	RequestedSecretLifeTime string `json:"requestedSecretLifeTime,omitempty"`
+
+	// The Prometheus metrics endpoint of the brokers.
+	// +kubebuilder:validation:Optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
+
+	// JVM and heap settings of the brokers.
+	// +kubebuilder:validation:Optional
+	Jvm *JvmSpec `json:"jvm,omitempty"`
+
+	// server.properties entries taken from Secret or ConfigMap keys, e.g. passwords.
+	// They take precedence over configOverrides.
+	// +kubebuilder:validation:Optional
+	ServerPropertiesFrom []ConfigValueFromSpec `json:"serverPropertiesFrom,omitempty"`
 }
 type ConfigOverridesSpec struct {
 	Server   map[string]string `json:"server.properties,omitempty"`
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/zncdatadev/operator-go/pkg/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+)
+
+const (
+	ConnectDistributedFileName = "connect-distributed.properties"
+)
+
+const (
+	ConnectRestPortName = "rest"
+	ConnectRestPort     = 8083
+)
+
+const (
+	KubedoopConnectPluginsDirName = "connect-plugins"
+	KubedoopListenerRest          = "listener-rest"
+
+	KubedoopConnectPluginsDir = KubedoopRoot + "/connect-plugins"
+	KubedoopListenerRestDir   = KubedoopRoot + "/listener-rest"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// KafkaConnectCluster is the Schema for the kafkaconnectclusters API
+type KafkaConnectCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaConnectClusterSpec `json:"spec,omitempty"`
+	Status status.Status           `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaConnectClusterList contains a list of KafkaConnectCluster
+type KafkaConnectClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaConnectCluster `json:"items"`
+}
+
+// KafkaConnectClusterSpec defines the desired state of KafkaConnectCluster
+type KafkaConnectClusterSpec struct {
+	// +kubebuilder:validation:Optional
+	// +default:value={"repo": "quay.io/zncdatadev", "pullPolicy": "IfNotPresent"}
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// +kubebuilder:validation:Required
+	ClusterConfig *KafkaConnectClusterConfigSpec `json:"clusterConfig,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ClusterOperation *commonsv1alpha1.ClusterOperationSpec `json:"clusterOperation,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Workers *KafkaConnectWorkersSpec `json:"workers,omitempty"`
+}
+
+type KafkaConnectClusterConfigSpec struct {
+	// Name of the KafkaCluster in the same namespace the workers connect to.
+	// Bootstrap servers are read from its discovery ConfigMap, and TLS and Kerberos
+	// settings are taken from its `clusterConfig`.
+	// +kubebuilder:validation:Required
+	KafkaClusterRef string `json:"kafkaClusterRef"`
+
+	// The Connect group id shared by all workers. Defaults to the name of the KafkaConnectCluster.
+	// +kubebuilder:validation:Optional
+	GroupID string `json:"groupId,omitempty"`
+
+	// Connector plugins downloaded into the plugin path of every worker before it starts.
+	// +kubebuilder:validation:Optional
+	Plugins []KafkaConnectPluginSpec `json:"plugins,omitempty"`
+
+	// Replication factors of the internal config, offset and status topics.
+	// Defaults to the number of brokers of the referenced KafkaCluster, but at most 3.
+	// +kubebuilder:validation:Optional
+	InternalTopics *KafkaConnectInternalTopicsSpec `json:"internalTopics,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	VectorAggregatorConfigMapName string `json:"vectorAggregatorConfigMapName,omitempty"`
+}
+
+type KafkaConnectPluginSpec struct {
+	// Name of the plugin, used as directory name in the plugin path.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Artifacts []KafkaConnectPluginArtifactSpec `json:"artifacts"`
+}
+
+type KafkaConnectPluginArtifactSpec struct {
+	// URL of a `.jar`, `.zip`, `.tar.gz` or `.tgz` artifact.
+	// Archives are extracted into the plugin directory, jars are copied as they are.
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+
+	// SHA-512 checksum of the artifact. If set, the download is verified before it is installed.
+	// +kubebuilder:validation:Optional
+	Sha512Sum string `json:"sha512sum,omitempty"`
+}
+
+type KafkaConnectInternalTopicsSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ConfigStorageReplicationFactor *int32 `json:"configStorageReplicationFactor,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	OffsetStorageReplicationFactor *int32 `json:"offsetStorageReplicationFactor,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	StatusStorageReplicationFactor *int32 `json:"statusStorageReplicationFactor,omitempty"`
+}
+
+type KafkaConnectWorkersSpec struct {
+	// +kubebuilder:validation:Optional
+	Config *KafkaConnectConfigSpec `json:"config,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RoleGroups map[string]*KafkaConnectRoleGroupSpec `json:"roleGroups,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RoleConfig *commonsv1alpha1.RoleConfigSpec `json:"roleConfig,omitempty"`
+
+	*commonsv1alpha1.OverridesSpec `json:",inline"`
+}
+
+type KafkaConnectRoleGroupSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=1
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Config *KafkaConnectConfigSpec `json:"config,omitempty"`
+
+	*commonsv1alpha1.OverridesSpec `json:",inline"`
+}
+
+type KafkaConnectConfigSpec struct {
+	*commonsv1alpha1.RoleGroupConfigSpec `json:",inline"`
+
+	// The ListenerClass used to expose the Connect REST API.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="cluster-internal"
+	RestListenerClass string `json:"restListenerClass,omitempty"`
+
+	// Request secret (currently only autoTls certificates) lifetime from the secret operator, e.g. `7d`, or `30d`.
+	// +kubebuilder:validation:Optional
+	RequestedSecretLifeTime string `json:"requestedSecretLifeTime,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaConnectCluster{}, &KafkaConnectClusterList{})
+}
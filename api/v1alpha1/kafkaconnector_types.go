@@ -0,0 +1,168 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaConnectorState is the desired state of a connector
+// +kubebuilder:validation:Enum=running;paused;stopped
+type KafkaConnectorState string
+
+const (
+	KafkaConnectorStateRunning KafkaConnectorState = "running"
+	KafkaConnectorStatePaused  KafkaConnectorState = "paused"
+	KafkaConnectorStateStopped KafkaConnectorState = "stopped"
+)
+
+const (
+	// KafkaConnectorFinalizer removes the connector from the Connect cluster before the resource is deleted
+	KafkaConnectorFinalizer = "kafka.kubedoop.dev/connector"
+
+	// ConditionTypeConnectorReady is true, when the connector and all tasks are in the desired state
+	ConditionTypeConnectorReady = "Ready"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.connectClusterRef`
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.class`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.connectorState`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// KafkaConnector is the Schema for the kafkaconnectors API
+type KafkaConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaConnectorSpec   `json:"spec,omitempty"`
+	Status KafkaConnectorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaConnectorList contains a list of KafkaConnector
+type KafkaConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaConnector `json:"items"`
+}
+
+// KafkaConnectorSpec defines the desired state of KafkaConnector
+type KafkaConnectorSpec struct {
+	// Name of the KafkaConnectCluster in the same namespace the connector runs on.
+	// +kubebuilder:validation:Required
+	ConnectClusterRef string `json:"connectClusterRef"`
+
+	// The connector class, e.g. `org.apache.kafka.connect.file.FileStreamSourceConnector`.
+	// +kubebuilder:validation:Required
+	Class string `json:"class"`
+
+	// Maximum number of tasks of the connector.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	TasksMax *int32 `json:"tasksMax,omitempty"`
+
+	// Connector configuration. `connector.class` and `tasks.max` are set from `class` and `tasksMax`.
+	// +kubebuilder:validation:Optional
+	Config map[string]string `json:"config,omitempty"`
+
+	// Connector configuration read from Secrets, e.g. credentials of external systems.
+	// Values are passed to the Connect REST API as they are, so they are stored in the config topic of the Connect cluster.
+	// +kubebuilder:validation:Optional
+	SecretConfig []KafkaConnectorSecretConfigSpec `json:"secretConfig,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="running"
+	State KafkaConnectorState `json:"state,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	AutoRestart *KafkaConnectorAutoRestartSpec `json:"autoRestart,omitempty"`
+}
+
+type KafkaConnectorSecretConfigSpec struct {
+	// The connector configuration key.
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+
+	// +kubebuilder:validation:Required
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// KafkaConnectorAutoRestartSpec restarts a failed connector and failed tasks with an exponential backoff.
+type KafkaConnectorAutoRestartSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Maximum number of consecutive restarts. The counter is reset once the connector and all tasks are running.
+	// Unlimited if not set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxRestarts *int32 `json:"maxRestarts,omitempty"`
+}
+
+// KafkaConnectorStatus defines the observed state of KafkaConnector
+type KafkaConnectorStatus struct {
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// State of the connector as reported by the Connect REST API, e.g. `RUNNING` or `FAILED`.
+	// +kubebuilder:validation:Optional
+	ConnectorState string `json:"connectorState,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	WorkerID string `json:"workerId,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Tasks []KafkaConnectorTaskStatus `json:"tasks,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	AutoRestart *KafkaConnectorAutoRestartStatus `json:"autoRestart,omitempty"`
+}
+
+type KafkaConnectorTaskStatus struct {
+	ID int32 `json:"id"`
+
+	State string `json:"state"`
+
+	// +kubebuilder:validation:Optional
+	WorkerID string `json:"workerId,omitempty"`
+
+	// The first line of the stack trace of a failed task.
+	// +kubebuilder:validation:Optional
+	Trace string `json:"trace,omitempty"`
+}
+
+type KafkaConnectorAutoRestartStatus struct {
+	// Number of consecutive restarts.
+	Count int32 `json:"count"`
+
+	// +kubebuilder:validation:Optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaConnector{}, &KafkaConnectorList{})
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/zncdatadev/operator-go/pkg/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+)
+
+const (
+	MirrorMaker2FileName = "mm2.properties"
+)
+
+type ReplicationPolicy string
+
+const (
+	// ReplicationPolicyDefault prefixes remote topics with the source cluster alias, e.g. `source.orders`
+	ReplicationPolicyDefault ReplicationPolicy = "default"
+	// ReplicationPolicyIdentity keeps the topic names of the source cluster
+	ReplicationPolicyIdentity ReplicationPolicy = "identity"
+)
+
+type OffsetSyncsTopicLocation string
+
+const (
+	OffsetSyncsTopicLocationSource OffsetSyncsTopicLocation = "source"
+	OffsetSyncsTopicLocationTarget OffsetSyncsTopicLocation = "target"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.clusterConfig.source.alias`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.clusterConfig.target.alias`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KafkaMirrorMaker2 is the Schema for the kafkamirrormaker2s API
+type KafkaMirrorMaker2 struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaMirrorMaker2Spec   `json:"spec,omitempty"`
+	Status KafkaMirrorMaker2Status `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaMirrorMaker2List contains a list of KafkaMirrorMaker2
+type KafkaMirrorMaker2List struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaMirrorMaker2 `json:"items"`
+}
+
+// KafkaMirrorMaker2Spec defines the desired state of KafkaMirrorMaker2
+type KafkaMirrorMaker2Spec struct {
+	// +kubebuilder:validation:Optional
+	// +default:value={"repo": "quay.io/zncdatadev", "pullPolicy": "IfNotPresent"}
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// +kubebuilder:validation:Required
+	ClusterConfig *KafkaMirrorMaker2ClusterConfigSpec `json:"clusterConfig,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ClusterOperation *commonsv1alpha1.ClusterOperationSpec `json:"clusterOperation,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Workers *KafkaMirrorMaker2WorkersSpec `json:"workers,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="self.source.alias != self.target.alias",message="source and target must have different aliases"
+type KafkaMirrorMaker2ClusterConfigSpec struct {
+	// The cluster topics are replicated from.
+	// +kubebuilder:validation:Required
+	Source *MirrorMaker2ClusterSpec `json:"source"`
+
+	// The cluster topics are replicated to. The workers store their internal topics in this cluster.
+	// +kubebuilder:validation:Required
+	Target *MirrorMaker2ClusterSpec `json:"target"`
+
+	// Topics to replicate, as regular expressions.
+	// +kubebuilder:validation:Optional
+	Topics *MirrorMaker2FilterSpec `json:"topics,omitempty"`
+
+	// Consumer groups whose offsets are checkpointed, as regular expressions.
+	// +kubebuilder:validation:Optional
+	Groups *MirrorMaker2FilterSpec `json:"groups,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Replication *MirrorMaker2ReplicationSpec `json:"replication,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	OffsetSync *MirrorMaker2OffsetSyncSpec `json:"offsetSync,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Checkpoints *MirrorMaker2CheckpointsSpec `json:"checkpoints,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	VectorAggregatorConfigMapName string `json:"vectorAggregatorConfigMapName,omitempty"`
+}
+
+// MirrorMaker2ClusterSpec is either a KafkaCluster in the same namespace, or an external cluster
+// reached through its bootstrap servers.
+// +kubebuilder:validation:XValidation:rule="has(self.kafkaClusterRef) != has(self.bootstrapServers)",message="exactly one of kafkaClusterRef or bootstrapServers must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.kafkaClusterRef) || (!has(self.tls) && !has(self.kerberos))",message="tls and kerberos are taken from the referenced KafkaCluster"
+type MirrorMaker2ClusterSpec struct {
+	// Alias of the cluster, used in the MirrorMaker2 configuration and as prefix of remote topics.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`
+	Alias string `json:"alias"`
+
+	// Name of a KafkaCluster in the same namespace.
+	// Bootstrap servers are read from its discovery ConfigMap, TLS and Kerberos settings are taken from its `clusterConfig`.
+	// +kubebuilder:validation:Optional
+	KafkaClusterRef string `json:"kafkaClusterRef,omitempty"`
+
+	// Bootstrap servers of an external cluster, e.g. `kafka-0.example.com:9093,kafka-1.example.com:9093`.
+	// +kubebuilder:validation:Optional
+	BootstrapServers string `json:"bootstrapServers,omitempty"`
+
+	// TLS settings of an external cluster. The truststore is provisioned by the secret-operator.
+	// +kubebuilder:validation:Optional
+	Tls *MirrorMaker2ClusterTlsSpec `json:"tls,omitempty"`
+
+	// Kerberos settings of an external cluster. The keytab is provisioned by the secret-operator.
+	// +kubebuilder:validation:Optional
+	Kerberos *MirrorMaker2ClusterKerberosSpec `json:"kerberos,omitempty"`
+
+	// Additional client properties of this cluster, e.g. `producer.compression.type`.
+	// They are prefixed with the alias of the cluster in the MirrorMaker2 configuration.
+	// +kubebuilder:validation:Optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+type MirrorMaker2ClusterTlsSpec struct {
+	// SecretClass providing the CA certificate of the brokers.
+	// +kubebuilder:validation:Required
+	ServerSecretClass string `json:"serverSecretClass"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="changeit"
+	SSLStorePassword string `json:"sslStorePassword,omitempty"`
+}
+
+type MirrorMaker2ClusterKerberosSpec struct {
+	// SecretClass providing the keytab of the workers.
+	// +kubebuilder:validation:Required
+	KerberosSecretClass string `json:"kerberosSecretClass"`
+
+	// Service name of the broker principals.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="kafka"
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+type MirrorMaker2FilterSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:={".*"}
+	Include []string `json:"include,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+type MirrorMaker2ReplicationSpec struct {
+	// `default` prefixes remote topics with the source alias, `identity` keeps the topic names.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=default;identity
+	// +kubebuilder:default:="default"
+	Policy ReplicationPolicy `json:"policy,omitempty"`
+
+	// Replication factor of the remote topics and of the internal topics.
+	// Defaults to the number of brokers of the target KafkaCluster, but at most 3.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+
+	// Whether topic configurations and ACLs are synced to the target cluster.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	SyncTopicConfigs *bool `json:"syncTopicConfigs,omitempty"`
+}
+
+type MirrorMaker2OffsetSyncSpec struct {
+	// Whether consumer group offsets are translated and written to the target cluster.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=60
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+
+	// The cluster storing the offset-syncs topic. Use `target` if the workers may not write to the source cluster.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=source;target
+	// +kubebuilder:default:="source"
+	OffsetSyncsTopicLocation OffsetSyncsTopicLocation `json:"offsetSyncsTopicLocation,omitempty"`
+}
+
+type MirrorMaker2CheckpointsSpec struct {
+	// Whether consumer group checkpoints are emitted to the target cluster.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=60
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+}
+
+type KafkaMirrorMaker2WorkersSpec struct {
+	// +kubebuilder:validation:Optional
+	Config *KafkaMirrorMaker2ConfigSpec `json:"config,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RoleGroups map[string]*KafkaMirrorMaker2RoleGroupSpec `json:"roleGroups,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RoleConfig *commonsv1alpha1.RoleConfigSpec `json:"roleConfig,omitempty"`
+
+	*commonsv1alpha1.OverridesSpec `json:",inline"`
+}
+
+type KafkaMirrorMaker2RoleGroupSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=1
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Config *KafkaMirrorMaker2ConfigSpec `json:"config,omitempty"`
+
+	*commonsv1alpha1.OverridesSpec `json:",inline"`
+}
+
+type KafkaMirrorMaker2ConfigSpec struct {
+	*commonsv1alpha1.RoleGroupConfigSpec `json:",inline"`
+
+	// Request secret (currently only autoTls certificates) lifetime from the secret operator, e.g. `7d`, or `30d`.
+	// +kubebuilder:validation:Optional
+	RequestedSecretLifeTime string `json:"requestedSecretLifeTime,omitempty"`
+}
+
+// KafkaMirrorMaker2Status defines the observed state of KafkaMirrorMaker2
+type KafkaMirrorMaker2Status struct {
+	status.Status `json:",inline"`
+
+	// Replication lag per replicated topic, scraped from the metrics of the workers.
+	// +kubebuilder:validation:Optional
+	Replication []MirrorMaker2TopicReplicationStatus `json:"replication,omitempty"`
+
+	// Time the replication lag was last scraped.
+	// +kubebuilder:validation:Optional
+	LastScrapeTime *metav1.Time `json:"lastScrapeTime,omitempty"`
+}
+
+type MirrorMaker2TopicReplicationStatus struct {
+	// Name of the topic in the source cluster.
+	Topic string `json:"topic"`
+
+	// Number of partitions replicated by the workers.
+	Partitions int32 `json:"partitions"`
+
+	// Maximum time in milliseconds it took records to be replicated to the target cluster, over all partitions.
+	ReplicationLatencyMs int64 `json:"replicationLatencyMs"`
+
+	// Maximum age in milliseconds of the records when they were replicated, over all partitions.
+	RecordAgeMs int64 `json:"recordAgeMs"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaMirrorMaker2{}, &KafkaMirrorMaker2List{})
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConflictPolicy decides what a restore does with topics, users and consumer groups already in the cluster
+// +kubebuilder:validation:Enum=Skip;Overwrite;Fail
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip keeps the existing entities
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// ConflictPolicyOverwrite replaces the existing entities with the ones of the snapshot
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+	// ConflictPolicyFail fails the restore without changing the cluster
+	ConflictPolicyFail ConflictPolicy = "Fail"
+)
+
+// RestorePhase is the step of a KafkaRestore
+type RestorePhase string
+
+const (
+	// RestorePhasePending waits for the KafkaCluster to be available
+	RestorePhasePending RestorePhase = "Pending"
+	// RestorePhaseInspecting reads the metadata of the cluster, to find the conflicts with the snapshot
+	RestorePhaseInspecting RestorePhase = "Inspecting"
+	// RestorePhaseRestoring replays the snapshot into the cluster
+	RestorePhaseRestoring RestorePhase = "Restoring"
+	RestorePhaseSucceeded RestorePhase = "Succeeded"
+	RestorePhaseFailed    RestorePhase = "Failed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterRef`
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshot`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KafkaRestore is the Schema for the kafkarestores API
+type KafkaRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaRestoreSpec   `json:"spec,omitempty"`
+	Status KafkaRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaRestoreList contains a list of KafkaRestore
+type KafkaRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaRestore `json:"items"`
+}
+
+// KafkaRestoreSpec defines the desired state of KafkaRestore.
+// A restore runs once, the spec is immutable.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type KafkaRestoreSpec struct {
+	// Name of the KafkaCluster in the same namespace to restore into, a new or an existing cluster.
+	// +kubebuilder:validation:Required
+	ClusterRef string `json:"clusterRef"`
+
+	// The target the snapshot was stored in by a KafkaBackup.
+	// +kubebuilder:validation:Required
+	Target BackupTargetSpec `json:"target"`
+
+	// Name of the snapshot, as listed in the status of the KafkaBackup.
+	// +kubebuilder:validation:Required
+	Snapshot string `json:"snapshot"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="Skip"
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// KafkaRestoreStatus defines the observed state of KafkaRestore
+type KafkaRestoreStatus struct {
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// Why the restore is pending or failed.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// Entities of the snapshot replayed into the cluster.
+	// +kubebuilder:validation:Optional
+	Applied []string `json:"applied,omitempty"`
+
+	// Entities of the snapshot left out, because they exist in the cluster or are not applicable.
+	// +kubebuilder:validation:Optional
+	Skipped []string `json:"skipped,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaRestore{}, &KafkaRestoreList{})
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SnapshotHoldAnnotation is set by the operator on a broker pod to stop the broker while its data volume
+	// is snapshotted, the broker waits until the annotation is removed before starting again
+	SnapshotHoldAnnotation = "kafka.kubedoop.dev/snapshot-hold"
+
+	KubedoopSnapshotHoldDirName = "snapshot-hold"
+	KubedoopSnapshotHoldDir     = "/kubedoop/snapshot-hold"
+	SnapshotHoldFileName        = "hold"
+
+	// KafkaSnapshotFinalizer releases the held broker, if a KafkaSnapshot with controlled shutdown is deleted while it runs
+	KafkaSnapshotFinalizer = "kafka.kubedoop.dev/snapshot"
+
+	// SnapshotLabel is set on the VolumeSnapshots of a KafkaSnapshot, it holds the name of the KafkaSnapshot
+	SnapshotLabel = "kafka.kubedoop.dev/snapshot"
+)
+
+// SnapshotPhase is the step of a KafkaSnapshot
+type SnapshotPhase string
+
+const (
+	// SnapshotPhasePending waits for the role group to be available
+	SnapshotPhasePending SnapshotPhase = "Pending"
+	// SnapshotPhaseSnapshotting creates the VolumeSnapshots and waits until they are ready to use
+	SnapshotPhaseSnapshotting SnapshotPhase = "Snapshotting"
+	SnapshotPhaseSucceeded    SnapshotPhase = "Succeeded"
+	SnapshotPhaseFailed       SnapshotPhase = "Failed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterRef`
+// +kubebuilder:printcolumn:name="Role Group",type=string,JSONPath=`.spec.roleGroup`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KafkaSnapshot is the Schema for the kafkasnapshots API
+type KafkaSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaSnapshotSpec   `json:"spec,omitempty"`
+	Status KafkaSnapshotStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaSnapshotList contains a list of KafkaSnapshot
+type KafkaSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaSnapshot `json:"items"`
+}
+
+// KafkaSnapshotSpec defines the desired state of KafkaSnapshot.
+// A snapshot is taken once, the spec is immutable.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type KafkaSnapshotSpec struct {
+	// Name of the KafkaCluster in the same namespace.
+	// +kubebuilder:validation:Required
+	ClusterRef string `json:"clusterRef"`
+
+	// The broker role group, a VolumeSnapshot of the data volume of every broker of the role group is created.
+	// +kubebuilder:validation:Required
+	RoleGroup string `json:"roleGroup"`
+
+	// The VolumeSnapshotClass of the VolumeSnapshots, the default class of the CSI driver if not set.
+	// +kubebuilder:validation:Optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Stop one broker at a time with a controlled shutdown while its volume is snapshotted,
+	// so every snapshot holds the logs of a cleanly shut down broker.
+	// Otherwise all volumes are snapshotted at once while the brokers run, the snapshots are crash-consistent.
+	// The brokers of the role group roll to support the hold when the first such KafkaSnapshot of it is created,
+	// and again once the last one is deleted. Scaling the role group during the snapshot fails it.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=false
+	ControlledShutdown bool `json:"controlledShutdown,omitempty"`
+}
+
+// KafkaSnapshotStatus defines the observed state of KafkaSnapshot
+type KafkaSnapshotStatus struct {
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Phase SnapshotPhase `json:"phase,omitempty"`
+
+	// Why the snapshot is pending or failed.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// The VolumeSnapshot of the data volume of every broker of the role group, by pod ordinal.
+	// +kubebuilder:validation:Optional
+	Volumes []KafkaVolumeSnapshotStatus `json:"volumes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+type KafkaVolumeSnapshotStatus struct {
+	// Ordinal of the broker pod in the StatefulSet of the role group.
+	Ordinal int32 `json:"ordinal"`
+
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	VolumeSnapshot string `json:"volumeSnapshot"`
+
+	// When the storage system took the snapshot.
+	// +kubebuilder:validation:Optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+
+	// Minimum size of a volume restored from the snapshot.
+	// +kubebuilder:validation:Optional
+	RestoreSize *resource.Quantity `json:"restoreSize,omitempty"`
+}
+
+// DataSnapshotSourceSpec pre-populates the data volumes of the brokers of a role group from a KafkaSnapshot.
+// The data volumes hold the cluster ID and the broker ID, so the cluster must use the ZooKeeper data of the
+// snapshotted cluster and the same broker IDs, i.e. the same role group name and broker ID range.
+type DataSnapshotSourceSpec struct {
+	// +kubebuilder:validation:Required
+	RoleGroup string `json:"roleGroup"`
+
+	// Name of a succeeded KafkaSnapshot in the same namespace.
+	// +kubebuilder:validation:Required
+	KafkaSnapshot string `json:"kafkaSnapshot"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaSnapshot{}, &KafkaSnapshotList{})
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	JmxExporterFileName = "jmx-exporter.yaml"
+
+	KubedoopMetricsTlsName = "metrics-tls"
+	KubedoopMetricsTlsDir  = KubedoopRoot + "/metrics-tls"
+)
+
+// MetricsSpec configures the Prometheus JMX exporter running as java agent in the broker containers.
+type MetricsSpec struct {
+	// Whether the JMX exporter and the `-metrics` Service of the role group are deployed.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// The port of the metrics endpoint.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default:=9606
+	Port int32 `json:"port,omitempty"`
+
+	// Exporter rules evaluated before the default Kafka rules, the first matching rule wins.
+	// See https://github.com/prometheus/jmx_exporter for the rule format.
+	// +kubebuilder:validation:Optional
+	Rules []JmxExporterRule `json:"rules,omitempty"`
+
+	// Only use `rules`, without the default Kafka rules.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=false
+	ReplaceDefaultRules bool `json:"replaceDefaultRules,omitempty"`
+
+	// Serve the metrics endpoint over https, the certificate is requested from the secret operator.
+	// +kubebuilder:validation:Optional
+	Tls *MetricsTlsSpec `json:"tls,omitempty"`
+}
+
+type MetricsTlsSpec struct {
+	// The SecretClass issuing the certificate of the metrics endpoint.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="tls"
+	SecretClass string `json:"secretClass,omitempty"`
+
+	// The password of the PKCS12 keystore.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="changeit"
+	SSLStorePassword string `json:"sslStorePassword,omitempty"`
+}
+
+// JmxExporterRule is a rule of the JMX exporter, mapping MBean attributes to Prometheus metrics.
+type JmxExporterRule struct {
+	// Regex matched against the MBean attribute, e.g. `kafka.server<type=(.+), name=(.+)><>Value`.
+	// +kubebuilder:validation:Required
+	Pattern string `json:"pattern"`
+
+	// The metric name, may reference capture groups of the pattern.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// The metric value, defaults to the value of the attribute.
+	// +kubebuilder:validation:Optional
+	Value string `json:"value,omitempty"`
+
+	// Factor the value is multiplied with, e.g. `0.001` to convert milliseconds to seconds.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?[0-9]+(\.[0-9]+)?([eE]-?[0-9]+)?$`
+	ValueFactor string `json:"valueFactor,omitempty"`
+
+	// The help text of the metric.
+	// +kubebuilder:validation:Optional
+	Help string `json:"help,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=GAUGE;COUNTER;UNTYPED
+	Type string `json:"type,omitempty"`
+
+	// Labels of the metric, names and values may reference capture groups of the pattern.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
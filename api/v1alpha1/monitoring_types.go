@@ -0,0 +1,82 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// MonitorKind is the kind of the Prometheus Operator object scraping the brokers
+// +kubebuilder:validation:Enum=None;ServiceMonitor;PodMonitor
+type MonitorKind string
+
+const (
+	MonitorKindNone           MonitorKind = "None"
+	MonitorKindServiceMonitor MonitorKind = "ServiceMonitor"
+	MonitorKindPodMonitor     MonitorKind = "PodMonitor"
+)
+
+// MonitoringSpec configures the Prometheus Operator objects of the cluster.
+// They are only created if the Prometheus Operator CRDs are installed.
+type MonitoringSpec struct {
+	// Create a ServiceMonitor or PodMonitor per broker role group, scraping the metrics endpoint of the brokers.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="None"
+	Monitor MonitorKind `json:"monitor,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="30s"
+	ScrapeInterval string `json:"scrapeInterval,omitempty"`
+
+	// Labels added to the monitors and the PrometheusRule, e.g. to match the selectors of the Prometheus instance.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Create a PrometheusRule with alerts on the health of the cluster.
+	// +kubebuilder:validation:Optional
+	Alerts *KafkaAlertsSpec `json:"alerts,omitempty"`
+}
+
+// KafkaAlertsSpec configures the thresholds of the alerts in the PrometheusRule.
+type KafkaAlertsSpec struct {
+	// How long a condition must hold before the alert fires.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="5m"
+	For string `json:"for,omitempty"`
+
+	// Alert if more partitions than this are offline.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=0
+	OfflinePartitionsThreshold int32 `json:"offlinePartitionsThreshold,omitempty"`
+
+	// Alert if a broker has more under-replicated partitions than this.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=0
+	UnderReplicatedPartitionsThreshold int32 `json:"underReplicatedPartitionsThreshold,omitempty"`
+
+	// Alert if the used space of a broker data volume exceeds this percentage.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=80
+	DiskUsagePercent int32 `json:"diskUsagePercent,omitempty"`
+
+	// Alert if the average idle ratio of the request handler threads of a broker drops below this percentage.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=30
+	RequestHandlerIdlePercent int32 `json:"requestHandlerIdlePercent,omitempty"`
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// RackAnnotation is set by the operator on the broker pods to the rack of their node
+	RackAnnotation = "kafka.kubedoop.dev/rack"
+
+	KubedoopRackDirName = "rack"
+	KubedoopRackDir     = "/kubedoop/rack"
+	RackFileName        = "rack"
+)
+
+// RackAwarenessSpec sets `broker.rack` of every broker to the value of a label of the node it runs on.
+// Pods can not read node labels, so the operator copies the label value to the pod annotation
+// `kafka.kubedoop.dev/rack` once the pod is scheduled, and the broker waits for it before starting.
+// A broker without a rack after 5 minutes, e.g. as its node has no label, starts without `broker.rack`.
+type RackAwarenessSpec struct {
+	// The node label holding the rack, e.g. `topology.kubernetes.io/zone`.
+	// The brokers are also spread evenly across its values.
+	// +kubebuilder:validation:Required
+	NodeLabel string `json:"nodeLabel"`
+}
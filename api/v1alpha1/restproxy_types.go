@@ -0,0 +1,78 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+)
+
+const (
+	RestProxyFileName = "kafka-rest.properties"
+)
+
+const (
+	RestProxyPortName = "http"
+	RestProxyPort     = 8082
+)
+
+const (
+	KubedoopListenerRestProxy    = "listener-rest-proxy"
+	KubedoopListenerRestProxyDir = KubedoopRoot + "/listener-rest-proxy"
+)
+
+type RestProxySpec struct {
+	// The REST proxy image. The Kafka product image does not ship a REST proxy,
+	// so the Confluent REST proxy image is used by default.
+	// +kubebuilder:validation:Optional
+	// +default:value={"custom": "confluentinc/cp-kafka-rest:7.7.1", "pullPolicy": "IfNotPresent"}
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Config *RestProxyConfigSpec `json:"config,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RoleGroups map[string]*RestProxyRoleGroupSpec `json:"roleGroups,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RoleConfig *commonsv1alpha1.RoleConfigSpec `json:"roleConfig,omitempty"`
+
+	*commonsv1alpha1.OverridesSpec `json:",inline"`
+}
+
+type RestProxyRoleGroupSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=1
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Config *RestProxyConfigSpec `json:"config,omitempty"`
+
+	*commonsv1alpha1.OverridesSpec `json:",inline"`
+}
+
+type RestProxyConfigSpec struct {
+	*commonsv1alpha1.RoleGroupConfigSpec `json:",inline"`
+
+	// The ListenerClass used to expose the HTTP endpoint of the REST proxy.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="cluster-internal"
+	ListenerClass string `json:"listenerClass,omitempty"`
+
+	// Request secret (currently only autoTls certificates) lifetime from the secret operator, e.g. `7d`, or `30d`.
+	// +kubebuilder:validation:Optional
+	RequestedSecretLifeTime string `json:"requestedSecretLifeTime,omitempty"`
+}
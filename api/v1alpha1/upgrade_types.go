@@ -0,0 +1,62 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// ConditionTypeUpgrading is true while the brokers are upgraded to a new Kafka release
+const ConditionTypeUpgrading = "Upgrading"
+
+// UpgradeRevisionAnnotation is set by the operator on the broker StatefulSets to the Kafka release and pinned
+// inter.broker.protocol.version their pod template was written for
+const UpgradeRevisionAnnotation = "kafka.kubedoop.dev/upgrade-revision"
+
+// UpgradePhase is the step of an upgrade of the brokers to a new Kafka release
+type UpgradePhase string
+
+const (
+	// UpgradePhaseRollingBinaries rolls the brokers to the new release, the protocol version stays the one of the former release
+	UpgradePhaseRollingBinaries UpgradePhase = "RollingBinaries"
+	// UpgradePhaseAwaitingFinalization waits for autoFinalize, the brokers can still be downgraded
+	UpgradePhaseAwaitingFinalization UpgradePhase = "AwaitingFinalization"
+	// UpgradePhaseFinalizing rolls the brokers to the protocol version of the new release, they can no longer be downgraded
+	UpgradePhaseFinalizing UpgradePhase = "Finalizing"
+)
+
+// UpgradeSpec controls upgrades of the brokers to a new Kafka release.
+// An upgrade starts when the product version changes to a new release line, patch releases are rolled out directly.
+type UpgradeSpec struct {
+	// Finalize an upgrade as soon as all brokers run the new release and are ready,
+	// bumping inter.broker.protocol.version in a second rolling restart.
+	// If false, the upgrade waits until it is set to true.
+	// Until the upgrade is finalized, reverting the product version downgrades the brokers.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	AutoFinalize *bool `json:"autoFinalize,omitempty"`
+}
+
+// UpgradeStatus is the state of an upgrade in progress
+type UpgradeStatus struct {
+	Phase UpgradePhase `json:"phase"`
+
+	// Kafka version the brokers are upgraded from.
+	FromVersion string `json:"fromVersion"`
+
+	// Kafka version the brokers are upgraded to.
+	ToVersion string `json:"toVersion"`
+
+	// inter.broker.protocol.version the brokers currently run with.
+	ProtocolVersion string `json:"protocolVersion"`
+}
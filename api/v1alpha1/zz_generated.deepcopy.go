@@ -23,9 +23,51 @@ package v1alpha1
 import (
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSnapshotStatus) DeepCopyInto(out *BackupSnapshotStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSnapshotStatus.
+func (in *BackupSnapshotStatus) DeepCopy() *BackupSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetSpec) DeepCopyInto(out *BackupTargetSpec) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapBackupTargetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupTargetSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetSpec.
+func (in *BackupTargetSpec) DeepCopy() *BackupTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokersConfigSpec) DeepCopyInto(out *BrokersConfigSpec) {
 	*out = *in
@@ -34,6 +76,23 @@ func (in *BrokersConfigSpec) DeepCopyInto(out *BrokersConfigSpec) {
 		*out = new(commonsv1alpha1.RoleGroupConfigSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(MetricsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Jvm != nil {
+		in, out := &in.Jvm, &out.Jvm
+		*out = new(JvmSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerPropertiesFrom != nil {
+		in, out := &in.ServerPropertiesFrom, &out.ServerPropertiesFrom
+		*out = make([]ConfigValueFromSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokersConfigSpec.
@@ -54,6 +113,11 @@ func (in *BrokersRoleGroupSpec) DeepCopyInto(out *BrokersRoleGroupSpec) {
 		*out = new(BrokersConfigSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BrokerIdOffset != nil {
+		in, out := &in.BrokerIdOffset, &out.BrokerIdOffset
+		*out = new(int32)
+		**out = **in
+	}
 	if in.OverridesSpec != nil {
 		in, out := &in.OverridesSpec, &out.OverridesSpec
 		*out = new(commonsv1alpha1.OverridesSpec)
@@ -132,6 +196,26 @@ func (in *ClusterConfigSpec) DeepCopyInto(out *ClusterConfigSpec) {
 		*out = new(KafkaTlsSpec)
 		**out = **in
 	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RackAwareness != nil {
+		in, out := &in.RackAwareness, &out.RackAwareness
+		*out = new(RackAwarenessSpec)
+		**out = **in
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataSnapshots != nil {
+		in, out := &in.DataSnapshots, &out.DataSnapshots
+		*out = make([]DataSnapshotSourceSpec, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConfigSpec.
@@ -144,6 +228,28 @@ func (in *ClusterConfigSpec) DeepCopy() *ClusterConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapBackupTargetSpec) DeepCopyInto(out *ConfigMapBackupTargetSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapBackupTargetSpec.
+func (in *ConfigMapBackupTargetSpec) DeepCopy() *ConfigMapBackupTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapBackupTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigOverridesSpec) DeepCopyInto(out *ConfigOverridesSpec) {
 	*out = *in
@@ -173,6 +279,62 @@ func (in *ConfigOverridesSpec) DeepCopy() *ConfigOverridesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigValueFromSpec) DeepCopyInto(out *ConfigValueFromSpec) {
+	*out = *in
+	in.ValueFrom.DeepCopyInto(&out.ValueFrom)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigValueFromSpec.
+func (in *ConfigValueFromSpec) DeepCopy() *ConfigValueFromSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigValueFromSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigValueSource) DeepCopyInto(out *ConfigValueSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigValueSource.
+func (in *ConfigValueSource) DeepCopy() *ConfigValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSnapshotSourceSpec) DeepCopyInto(out *DataSnapshotSourceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSnapshotSourceSpec.
+func (in *DataSnapshotSourceSpec) DeepCopy() *DataSnapshotSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSnapshotSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -193,6 +355,78 @@ func (in *ImageSpec) DeepCopy() *ImageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JmxExporterRule) DeepCopyInto(out *JmxExporterRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JmxExporterRule.
+func (in *JmxExporterRule) DeepCopy() *JmxExporterRule {
+	if in == nil {
+		return nil
+	}
+	out := new(JmxExporterRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JvmSpec) DeepCopyInto(out *JvmSpec) {
+	*out = *in
+	if in.GcOptions != nil {
+		in, out := &in.GcOptions, &out.GcOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveArgs != nil {
+		in, out := &in.RemoveArgs, &out.RemoveArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveArgsRegex != nil {
+		in, out := &in.RemoveArgsRegex, &out.RemoveArgsRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JvmSpec.
+func (in *JvmSpec) DeepCopy() *JvmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JvmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaAlertsSpec) DeepCopyInto(out *KafkaAlertsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaAlertsSpec.
+func (in *KafkaAlertsSpec) DeepCopy() *KafkaAlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaAlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaAuthenticationSpec) DeepCopyInto(out *KafkaAuthenticationSpec) {
 	*out = *in
@@ -213,6 +447,119 @@ func (in *KafkaAuthenticationSpec) DeepCopy() *KafkaAuthenticationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBackup) DeepCopyInto(out *KafkaBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBackup.
+func (in *KafkaBackup) DeepCopy() *KafkaBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBackupList) DeepCopyInto(out *KafkaBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBackupList.
+func (in *KafkaBackupList) DeepCopy() *KafkaBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBackupSpec) DeepCopyInto(out *KafkaBackupSpec) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBackupSpec.
+func (in *KafkaBackupSpec) DeepCopy() *KafkaBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBackupStatus) DeepCopyInto(out *KafkaBackupStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]BackupSnapshotStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBackupStatus.
+func (in *KafkaBackupStatus) DeepCopy() *KafkaBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaCluster) DeepCopyInto(out *KafkaCluster) {
 	*out = *in
@@ -295,6 +642,11 @@ func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
 		*out = new(BrokersSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestProxy != nil {
+		in, out := &in.RestProxy, &out.RestProxy
+		*out = new(RestProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
@@ -308,31 +660,1451 @@ func (in *KafkaClusterSpec) DeepCopy() *KafkaClusterSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaTlsSpec) DeepCopyInto(out *KafkaTlsSpec) {
+func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
 	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.BrokerIdOffsets != nil {
+		in, out := &in.BrokerIdOffsets, &out.BrokerIdOffsets
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BrokerIds != nil {
+		in, out := &in.BrokerIds, &out.BrokerIds
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeStatus)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTlsSpec.
-func (in *KafkaTlsSpec) DeepCopy() *KafkaTlsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
+func (in *KafkaClusterStatus) DeepCopy() *KafkaClusterStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaTlsSpec)
+	out := new(KafkaClusterStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KerberosAuthenticationProviderSpec) DeepCopyInto(out *KerberosAuthenticationProviderSpec) {
+func (in *KafkaConnectCluster) DeepCopyInto(out *KafkaConnectCluster) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KerberosAuthenticationProviderSpec.
-func (in *KerberosAuthenticationProviderSpec) DeepCopy() *KerberosAuthenticationProviderSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectCluster.
+func (in *KafkaConnectCluster) DeepCopy() *KafkaConnectCluster {
 	if in == nil {
 		return nil
 	}
-	out := new(KerberosAuthenticationProviderSpec)
+	out := new(KafkaConnectCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnectCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectClusterConfigSpec) DeepCopyInto(out *KafkaConnectClusterConfigSpec) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]KafkaConnectPluginSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InternalTopics != nil {
+		in, out := &in.InternalTopics, &out.InternalTopics
+		*out = new(KafkaConnectInternalTopicsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectClusterConfigSpec.
+func (in *KafkaConnectClusterConfigSpec) DeepCopy() *KafkaConnectClusterConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectClusterConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectClusterList) DeepCopyInto(out *KafkaConnectClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaConnectCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectClusterList.
+func (in *KafkaConnectClusterList) DeepCopy() *KafkaConnectClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnectClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectClusterSpec) DeepCopyInto(out *KafkaConnectClusterSpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterConfig != nil {
+		in, out := &in.ClusterConfig, &out.ClusterConfig
+		*out = new(KafkaConnectClusterConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterOperation != nil {
+		in, out := &in.ClusterOperation, &out.ClusterOperation
+		*out = new(commonsv1alpha1.ClusterOperationSpec)
+		**out = **in
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(KafkaConnectWorkersSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectClusterSpec.
+func (in *KafkaConnectClusterSpec) DeepCopy() *KafkaConnectClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectConfigSpec) DeepCopyInto(out *KafkaConnectConfigSpec) {
+	*out = *in
+	if in.RoleGroupConfigSpec != nil {
+		in, out := &in.RoleGroupConfigSpec, &out.RoleGroupConfigSpec
+		*out = new(commonsv1alpha1.RoleGroupConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectConfigSpec.
+func (in *KafkaConnectConfigSpec) DeepCopy() *KafkaConnectConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectInternalTopicsSpec) DeepCopyInto(out *KafkaConnectInternalTopicsSpec) {
+	*out = *in
+	if in.ConfigStorageReplicationFactor != nil {
+		in, out := &in.ConfigStorageReplicationFactor, &out.ConfigStorageReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OffsetStorageReplicationFactor != nil {
+		in, out := &in.OffsetStorageReplicationFactor, &out.OffsetStorageReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StatusStorageReplicationFactor != nil {
+		in, out := &in.StatusStorageReplicationFactor, &out.StatusStorageReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectInternalTopicsSpec.
+func (in *KafkaConnectInternalTopicsSpec) DeepCopy() *KafkaConnectInternalTopicsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectInternalTopicsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectPluginArtifactSpec) DeepCopyInto(out *KafkaConnectPluginArtifactSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectPluginArtifactSpec.
+func (in *KafkaConnectPluginArtifactSpec) DeepCopy() *KafkaConnectPluginArtifactSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectPluginArtifactSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectPluginSpec) DeepCopyInto(out *KafkaConnectPluginSpec) {
+	*out = *in
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]KafkaConnectPluginArtifactSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectPluginSpec.
+func (in *KafkaConnectPluginSpec) DeepCopy() *KafkaConnectPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectRoleGroupSpec) DeepCopyInto(out *KafkaConnectRoleGroupSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(KafkaConnectConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridesSpec != nil {
+		in, out := &in.OverridesSpec, &out.OverridesSpec
+		*out = new(commonsv1alpha1.OverridesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectRoleGroupSpec.
+func (in *KafkaConnectRoleGroupSpec) DeepCopy() *KafkaConnectRoleGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectRoleGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectWorkersSpec) DeepCopyInto(out *KafkaConnectWorkersSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(KafkaConnectConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleGroups != nil {
+		in, out := &in.RoleGroups, &out.RoleGroups
+		*out = make(map[string]*KafkaConnectRoleGroupSpec, len(*in))
+		for key, val := range *in {
+			var outVal *KafkaConnectRoleGroupSpec
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(KafkaConnectRoleGroupSpec)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.RoleConfig != nil {
+		in, out := &in.RoleConfig, &out.RoleConfig
+		*out = new(commonsv1alpha1.RoleConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridesSpec != nil {
+		in, out := &in.OverridesSpec, &out.OverridesSpec
+		*out = new(commonsv1alpha1.OverridesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectWorkersSpec.
+func (in *KafkaConnectWorkersSpec) DeepCopy() *KafkaConnectWorkersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectWorkersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnector) DeepCopyInto(out *KafkaConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnector.
+func (in *KafkaConnector) DeepCopy() *KafkaConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorAutoRestartSpec) DeepCopyInto(out *KafkaConnectorAutoRestartSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxRestarts != nil {
+		in, out := &in.MaxRestarts, &out.MaxRestarts
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorAutoRestartSpec.
+func (in *KafkaConnectorAutoRestartSpec) DeepCopy() *KafkaConnectorAutoRestartSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorAutoRestartSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorAutoRestartStatus) DeepCopyInto(out *KafkaConnectorAutoRestartStatus) {
+	*out = *in
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorAutoRestartStatus.
+func (in *KafkaConnectorAutoRestartStatus) DeepCopy() *KafkaConnectorAutoRestartStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorAutoRestartStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorList) DeepCopyInto(out *KafkaConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorList.
+func (in *KafkaConnectorList) DeepCopy() *KafkaConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorSecretConfigSpec) DeepCopyInto(out *KafkaConnectorSecretConfigSpec) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorSecretConfigSpec.
+func (in *KafkaConnectorSecretConfigSpec) DeepCopy() *KafkaConnectorSecretConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorSecretConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorSpec) DeepCopyInto(out *KafkaConnectorSpec) {
+	*out = *in
+	if in.TasksMax != nil {
+		in, out := &in.TasksMax, &out.TasksMax
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretConfig != nil {
+		in, out := &in.SecretConfig, &out.SecretConfig
+		*out = make([]KafkaConnectorSecretConfigSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutoRestart != nil {
+		in, out := &in.AutoRestart, &out.AutoRestart
+		*out = new(KafkaConnectorAutoRestartSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorSpec.
+func (in *KafkaConnectorSpec) DeepCopy() *KafkaConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorStatus) DeepCopyInto(out *KafkaConnectorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]KafkaConnectorTaskStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoRestart != nil {
+		in, out := &in.AutoRestart, &out.AutoRestart
+		*out = new(KafkaConnectorAutoRestartStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorStatus.
+func (in *KafkaConnectorStatus) DeepCopy() *KafkaConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorTaskStatus) DeepCopyInto(out *KafkaConnectorTaskStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorTaskStatus.
+func (in *KafkaConnectorTaskStatus) DeepCopy() *KafkaConnectorTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2) DeepCopyInto(out *KafkaMirrorMaker2) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2.
+func (in *KafkaMirrorMaker2) DeepCopy() *KafkaMirrorMaker2 {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaMirrorMaker2) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2ClusterConfigSpec) DeepCopyInto(out *KafkaMirrorMaker2ClusterConfigSpec) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(MirrorMaker2ClusterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(MirrorMaker2ClusterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = new(MirrorMaker2FilterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = new(MirrorMaker2FilterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(MirrorMaker2ReplicationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OffsetSync != nil {
+		in, out := &in.OffsetSync, &out.OffsetSync
+		*out = new(MirrorMaker2OffsetSyncSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Checkpoints != nil {
+		in, out := &in.Checkpoints, &out.Checkpoints
+		*out = new(MirrorMaker2CheckpointsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2ClusterConfigSpec.
+func (in *KafkaMirrorMaker2ClusterConfigSpec) DeepCopy() *KafkaMirrorMaker2ClusterConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2ClusterConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2ConfigSpec) DeepCopyInto(out *KafkaMirrorMaker2ConfigSpec) {
+	*out = *in
+	if in.RoleGroupConfigSpec != nil {
+		in, out := &in.RoleGroupConfigSpec, &out.RoleGroupConfigSpec
+		*out = new(commonsv1alpha1.RoleGroupConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2ConfigSpec.
+func (in *KafkaMirrorMaker2ConfigSpec) DeepCopy() *KafkaMirrorMaker2ConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2ConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2List) DeepCopyInto(out *KafkaMirrorMaker2List) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaMirrorMaker2, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2List.
+func (in *KafkaMirrorMaker2List) DeepCopy() *KafkaMirrorMaker2List {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2List)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaMirrorMaker2List) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2RoleGroupSpec) DeepCopyInto(out *KafkaMirrorMaker2RoleGroupSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(KafkaMirrorMaker2ConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridesSpec != nil {
+		in, out := &in.OverridesSpec, &out.OverridesSpec
+		*out = new(commonsv1alpha1.OverridesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2RoleGroupSpec.
+func (in *KafkaMirrorMaker2RoleGroupSpec) DeepCopy() *KafkaMirrorMaker2RoleGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2RoleGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2Spec) DeepCopyInto(out *KafkaMirrorMaker2Spec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterConfig != nil {
+		in, out := &in.ClusterConfig, &out.ClusterConfig
+		*out = new(KafkaMirrorMaker2ClusterConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterOperation != nil {
+		in, out := &in.ClusterOperation, &out.ClusterOperation
+		*out = new(commonsv1alpha1.ClusterOperationSpec)
+		**out = **in
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(KafkaMirrorMaker2WorkersSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2Spec.
+func (in *KafkaMirrorMaker2Spec) DeepCopy() *KafkaMirrorMaker2Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2Status) DeepCopyInto(out *KafkaMirrorMaker2Status) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = make([]MirrorMaker2TopicReplicationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScrapeTime != nil {
+		in, out := &in.LastScrapeTime, &out.LastScrapeTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2Status.
+func (in *KafkaMirrorMaker2Status) DeepCopy() *KafkaMirrorMaker2Status {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2Status)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMirrorMaker2WorkersSpec) DeepCopyInto(out *KafkaMirrorMaker2WorkersSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(KafkaMirrorMaker2ConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleGroups != nil {
+		in, out := &in.RoleGroups, &out.RoleGroups
+		*out = make(map[string]*KafkaMirrorMaker2RoleGroupSpec, len(*in))
+		for key, val := range *in {
+			var outVal *KafkaMirrorMaker2RoleGroupSpec
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(KafkaMirrorMaker2RoleGroupSpec)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.RoleConfig != nil {
+		in, out := &in.RoleConfig, &out.RoleConfig
+		*out = new(commonsv1alpha1.RoleConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridesSpec != nil {
+		in, out := &in.OverridesSpec, &out.OverridesSpec
+		*out = new(commonsv1alpha1.OverridesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMirrorMaker2WorkersSpec.
+func (in *KafkaMirrorMaker2WorkersSpec) DeepCopy() *KafkaMirrorMaker2WorkersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMirrorMaker2WorkersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRestore) DeepCopyInto(out *KafkaRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRestore.
+func (in *KafkaRestore) DeepCopy() *KafkaRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRestoreList) DeepCopyInto(out *KafkaRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRestoreList.
+func (in *KafkaRestoreList) DeepCopy() *KafkaRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRestoreSpec) DeepCopyInto(out *KafkaRestoreSpec) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRestoreSpec.
+func (in *KafkaRestoreSpec) DeepCopy() *KafkaRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRestoreStatus) DeepCopyInto(out *KafkaRestoreStatus) {
+	*out = *in
+	if in.Applied != nil {
+		in, out := &in.Applied, &out.Applied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Skipped != nil {
+		in, out := &in.Skipped, &out.Skipped
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRestoreStatus.
+func (in *KafkaRestoreStatus) DeepCopy() *KafkaRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSnapshot) DeepCopyInto(out *KafkaSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSnapshot.
+func (in *KafkaSnapshot) DeepCopy() *KafkaSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSnapshotList) DeepCopyInto(out *KafkaSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSnapshotList.
+func (in *KafkaSnapshotList) DeepCopy() *KafkaSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSnapshotSpec) DeepCopyInto(out *KafkaSnapshotSpec) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSnapshotSpec.
+func (in *KafkaSnapshotSpec) DeepCopy() *KafkaSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSnapshotStatus) DeepCopyInto(out *KafkaSnapshotStatus) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]KafkaVolumeSnapshotStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSnapshotStatus.
+func (in *KafkaSnapshotStatus) DeepCopy() *KafkaSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTlsSpec) DeepCopyInto(out *KafkaTlsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTlsSpec.
+func (in *KafkaTlsSpec) DeepCopy() *KafkaTlsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTlsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaVolumeSnapshotStatus) DeepCopyInto(out *KafkaVolumeSnapshotStatus) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RestoreSize != nil {
+		in, out := &in.RestoreSize, &out.RestoreSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaVolumeSnapshotStatus.
+func (in *KafkaVolumeSnapshotStatus) DeepCopy() *KafkaVolumeSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaVolumeSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KerberosAuthenticationProviderSpec) DeepCopyInto(out *KerberosAuthenticationProviderSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KerberosAuthenticationProviderSpec.
+func (in *KerberosAuthenticationProviderSpec) DeepCopy() *KerberosAuthenticationProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KerberosAuthenticationProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsSpec) DeepCopyInto(out *MetricsSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]JmxExporterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tls != nil {
+		in, out := &in.Tls, &out.Tls
+		*out = new(MetricsTlsSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsSpec.
+func (in *MetricsSpec) DeepCopy() *MetricsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsTlsSpec) DeepCopyInto(out *MetricsTlsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsTlsSpec.
+func (in *MetricsTlsSpec) DeepCopy() *MetricsTlsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsTlsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2CheckpointsSpec) DeepCopyInto(out *MirrorMaker2CheckpointsSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2CheckpointsSpec.
+func (in *MirrorMaker2CheckpointsSpec) DeepCopy() *MirrorMaker2CheckpointsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2CheckpointsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2ClusterKerberosSpec) DeepCopyInto(out *MirrorMaker2ClusterKerberosSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2ClusterKerberosSpec.
+func (in *MirrorMaker2ClusterKerberosSpec) DeepCopy() *MirrorMaker2ClusterKerberosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2ClusterKerberosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2ClusterSpec) DeepCopyInto(out *MirrorMaker2ClusterSpec) {
+	*out = *in
+	if in.Tls != nil {
+		in, out := &in.Tls, &out.Tls
+		*out = new(MirrorMaker2ClusterTlsSpec)
+		**out = **in
+	}
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(MirrorMaker2ClusterKerberosSpec)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2ClusterSpec.
+func (in *MirrorMaker2ClusterSpec) DeepCopy() *MirrorMaker2ClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2ClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2ClusterTlsSpec) DeepCopyInto(out *MirrorMaker2ClusterTlsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2ClusterTlsSpec.
+func (in *MirrorMaker2ClusterTlsSpec) DeepCopy() *MirrorMaker2ClusterTlsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2ClusterTlsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2FilterSpec) DeepCopyInto(out *MirrorMaker2FilterSpec) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2FilterSpec.
+func (in *MirrorMaker2FilterSpec) DeepCopy() *MirrorMaker2FilterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2FilterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2OffsetSyncSpec) DeepCopyInto(out *MirrorMaker2OffsetSyncSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2OffsetSyncSpec.
+func (in *MirrorMaker2OffsetSyncSpec) DeepCopy() *MirrorMaker2OffsetSyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2OffsetSyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2ReplicationSpec) DeepCopyInto(out *MirrorMaker2ReplicationSpec) {
+	*out = *in
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SyncTopicConfigs != nil {
+		in, out := &in.SyncTopicConfigs, &out.SyncTopicConfigs
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2ReplicationSpec.
+func (in *MirrorMaker2ReplicationSpec) DeepCopy() *MirrorMaker2ReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2ReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMaker2TopicReplicationStatus) DeepCopyInto(out *MirrorMaker2TopicReplicationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMaker2TopicReplicationStatus.
+func (in *MirrorMaker2TopicReplicationStatus) DeepCopy() *MirrorMaker2TopicReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMaker2TopicReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(KafkaAlertsSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RackAwarenessSpec) DeepCopyInto(out *RackAwarenessSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RackAwarenessSpec.
+func (in *RackAwarenessSpec) DeepCopy() *RackAwarenessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RackAwarenessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestProxyConfigSpec) DeepCopyInto(out *RestProxyConfigSpec) {
+	*out = *in
+	if in.RoleGroupConfigSpec != nil {
+		in, out := &in.RoleGroupConfigSpec, &out.RoleGroupConfigSpec
+		*out = new(commonsv1alpha1.RoleGroupConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestProxyConfigSpec.
+func (in *RestProxyConfigSpec) DeepCopy() *RestProxyConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestProxyConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestProxyRoleGroupSpec) DeepCopyInto(out *RestProxyRoleGroupSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(RestProxyConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridesSpec != nil {
+		in, out := &in.OverridesSpec, &out.OverridesSpec
+		*out = new(commonsv1alpha1.OverridesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestProxyRoleGroupSpec.
+func (in *RestProxyRoleGroupSpec) DeepCopy() *RestProxyRoleGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestProxyRoleGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestProxySpec) DeepCopyInto(out *RestProxySpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(RestProxyConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleGroups != nil {
+		in, out := &in.RoleGroups, &out.RoleGroups
+		*out = make(map[string]*RestProxyRoleGroupSpec, len(*in))
+		for key, val := range *in {
+			var outVal *RestProxyRoleGroupSpec
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(RestProxyRoleGroupSpec)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.RoleConfig != nil {
+		in, out := &in.RoleConfig, &out.RoleConfig
+		*out = new(commonsv1alpha1.RoleConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridesSpec != nil {
+		in, out := &in.OverridesSpec, &out.OverridesSpec
+		*out = new(commonsv1alpha1.OverridesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestProxySpec.
+func (in *RestProxySpec) DeepCopy() *RestProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupTargetSpec) DeepCopyInto(out *S3BackupTargetSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupTargetSpec.
+func (in *S3BackupTargetSpec) DeepCopy() *S3BackupTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeSpec) DeepCopyInto(out *UpgradeSpec) {
+	*out = *in
+	if in.AutoFinalize != nil {
+		in, out := &in.AutoFinalize, &out.AutoFinalize
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeSpec.
+func (in *UpgradeSpec) DeepCopy() *UpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
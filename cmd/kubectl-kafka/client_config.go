@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/controller"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+func newBootstrapCommand(o *options) *cobra.Command {
+	var listenerClass string
+	cmd := &cobra.Command{
+		Use:   "bootstrap NAME",
+		Short: "Print the bootstrap servers of a KafkaCluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cluster, err := o.kafkaCluster(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			servers, err := bootstrapServers(cmd.Context(), c, cluster, listenerClass)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), servers)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&listenerClass, "listener-class", "",
+		"Read the discovery ConfigMap of this bootstrap ListenerClass instead of the primary one.")
+	return cmd
+}
+
+func newClientConfigCommand(o *options) *cobra.Command {
+	var listenerClass, principal string
+	cmd := &cobra.Command{
+		Use:   "client-config NAME",
+		Short: "Print the client properties to connect to a KafkaCluster",
+		Long: `Print the client properties to connect to a KafkaCluster, e.g. as --command-config of the Kafka tools.
+The truststore and keytab paths are the ones of the secret-operator volumes of clients deployed by the operator,
+the required volumes are listed as comments.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cluster, err := o.kafkaCluster(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			servers, err := bootstrapServers(cmd.Context(), c, cluster, listenerClass)
+			if err != nil {
+				return err
+			}
+			return printClientConfig(cmd.OutOrStdout(), cluster, servers, principal)
+		},
+	}
+	cmd.Flags().StringVar(&listenerClass, "listener-class", "",
+		"Read the discovery ConfigMap of this bootstrap ListenerClass instead of the primary one.")
+	cmd.Flags().StringVar(&principal, "principal", "", "The Kerberos principal of the client, required for Kerberos clusters.")
+	return cmd
+}
+
+// bootstrapServers reads the bootstrap servers from the discovery ConfigMap of the cluster
+func bootstrapServers(ctx context.Context, c ctrlclient.Client, cluster *kafkav1alpha1.KafkaCluster, listenerClass string) (string, error) {
+	name := cluster.Name
+	if listenerClass != "" {
+		name = controller.DiscoveryConfigMapName(cluster.Name, listenerClass)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: name}, cm); err != nil {
+		return "", fmt.Errorf("failed to get discovery ConfigMap %s/%s: %w", cluster.Namespace, name, err)
+	}
+	servers := cm.Data[controller.KafkaDiscoveryKey]
+	if servers == "" {
+		return "", fmt.Errorf("discovery ConfigMap %s/%s has no bootstrap servers yet", cluster.Namespace, name)
+	}
+	return servers, nil
+}
+
+func printClientConfig(out io.Writer, cluster *kafkav1alpha1.KafkaCluster, servers, principal string) error {
+	kafkaSecurity := security.NewKafkaSecurity(cluster)
+	clientSecurity := kafkaSecurity.Client()
+	if clientSecurity.IsKerberosEnabled() && principal == "" {
+		return fmt.Errorf("KafkaCluster %s uses Kerberos authentication, --principal is required", cluster.Name)
+	}
+
+	settings := clientSecurity.ConfigSettings("")
+	settings["bootstrap.servers"] = servers
+	if clientSecurity.IsKerberosEnabled() {
+		settings["sasl.jaas.config"] = clientSecurity.KerberosJaasConfig(principal)
+	}
+
+	fmt.Fprintf(out, "# KafkaCluster %s/%s\n", cluster.Namespace, cluster.Name)
+	if clientSecurity.ServerSecretClass != "" {
+		fmt.Fprintf(out, "# truststore: %s of SecretClass %s (format tls-pkcs12)\n", clientSecurity.TrustStoreDir(), clientSecurity.ServerSecretClass)
+	}
+	if clientSecurity.IsKerberosEnabled() {
+		fmt.Fprintf(out, "# keytab and krb5.conf: %s of SecretClass %s (format kerberos)\n", clientSecurity.KerberosDir(), clientSecurity.KerberosSecretClass)
+	}
+	if kafkaSecurity.TlsClientAuthenticationClass() != "" {
+		fmt.Fprintf(out, "# the brokers require a client certificate of AuthenticationClass %s\n", kafkaSecurity.TlsClientAuthenticationClass())
+	}
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		if _, err := fmt.Fprintf(out, "%s=%s\n", key, settings[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-kafka inspects and operates the KafkaClusters of the operator.
+// On the PATH it runs as the kubectl plugin `kubectl kafka`.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/util/version"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kafkav1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// options are the connection flags shared by all commands
+type options struct {
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+func newRootCommand() *cobra.Command {
+	o := &options{}
+	cmd := &cobra.Command{
+		Use:           "kubectl-kafka",
+		Short:         "Inspect and operate KafkaClusters",
+		Version:       version.NewAppInfo("kubectl-kafka").String(),
+		SilenceUsage:  true,
+		SilenceErrors: false,
+	}
+	cmd.PersistentFlags().StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	cmd.PersistentFlags().StringVar(&o.context, "context", "", "The kubeconfig context to use.")
+	cmd.PersistentFlags().StringVarP(&o.namespace, "namespace", "n", "", "The namespace of the KafkaCluster.")
+
+	cmd.AddCommand(
+		newStatusCommand(o),
+		newBootstrapCommand(o),
+		newClientConfigCommand(o),
+		newRestartCommand(o),
+		newTopicsCommand(o),
+		newLagCommand(o),
+	)
+	return cmd
+}
+
+func (o *options) clientConfig() clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.context}
+	overrides.Context.Namespace = o.namespace
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
+
+func (o *options) restConfig() (*rest.Config, error) {
+	return o.clientConfig().ClientConfig()
+}
+
+func (o *options) client() (ctrlclient.Client, error) {
+	config, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ctrlclient.New(config, ctrlclient.Options{Scheme: scheme})
+}
+
+// kafkaCluster returns the client and the KafkaCluster `name` in the namespace of the flags or the kubeconfig context
+func (o *options) kafkaCluster(ctx context.Context, name string) (ctrlclient.Client, *kafkav1alpha1.KafkaCluster, error) {
+	namespace, _, err := o.clientConfig().Namespace()
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := o.client()
+	if err != nil {
+		return nil, nil, err
+	}
+	cluster := &kafkav1alpha1.KafkaCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cluster); err != nil {
+		return nil, nil, fmt.Errorf("failed to get KafkaCluster %s/%s: %w", namespace, name, err)
+	}
+	return c, cluster, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/admin"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+// tlsOptions are the flags to verify the brokers of a cluster with server TLS
+type tlsOptions struct {
+	caFile             string
+	serverName         string
+	insecureSkipVerify bool
+}
+
+func (t *tlsOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&t.caFile, "ca-file", "", "The CA certificate of the SecretClass of the brokers, for clusters with server TLS.")
+	cmd.Flags().StringVar(&t.serverName, "tls-server-name", "", "The server name to verify the broker certificates with, the pod DNS name by default.")
+	cmd.Flags().BoolVar(&t.insecureSkipVerify, "insecure-skip-tls-verify", false, "Do not verify the broker certificates.")
+}
+
+func (t *tlsOptions) config() (*tls.Config, error) {
+	if t.insecureSkipVerify {
+		return &tls.Config{InsecureSkipVerify: true}, nil // #nosec G402 -- explicitly requested
+	}
+	if t.caFile == "" {
+		return nil, errors.New("the brokers use server TLS, --ca-file or --insecure-skip-tls-verify is required")
+	}
+	pem, err := os.ReadFile(t.caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", t.caFile)
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// brokerTunnel forwards a local port to the client port of every running broker
+type brokerTunnel struct {
+	ports       map[int32]uint16
+	serverNames map[int32]string
+	tlsConfig   *tls.Config
+	stop        chan struct{}
+}
+
+// openBrokerTunnel forwards the brokers of the cluster, the tunnel must be closed by the caller
+func openBrokerTunnel(
+	ctx context.Context,
+	o *options,
+	c ctrlclient.Client,
+	cluster *kafkav1alpha1.KafkaCluster,
+	tlsOpts *tlsOptions,
+) (*brokerTunnel, error) {
+	kafkaSecurity := security.NewKafkaSecurity(cluster)
+	if kafkaSecurity.IsKerberosEnabled() {
+		return nil, fmt.Errorf("KafkaCluster %s uses Kerberos authentication, which is not supported", cluster.Name)
+	}
+	if kafkaSecurity.TlsClientAuthenticationClass() != "" {
+		return nil, fmt.Errorf("KafkaCluster %s requires client certificates, which is not supported", cluster.Name)
+	}
+	tunnel := &brokerTunnel{
+		ports:       map[int32]uint16{},
+		serverNames: map[int32]string{},
+		stop:        make(chan struct{}),
+	}
+	if kafkaSecurity.TlsServerSecretClass() != "" {
+		tlsConfig, err := tlsOpts.config()
+		if err != nil {
+			return nil, err
+		}
+		tunnel.tlsConfig = tlsConfig
+	}
+
+	config, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for podName, nodeId := range cluster.Status.BrokerIds {
+		pod := &corev1.Pod{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: podName}, pod); err != nil {
+			if ctrlclient.IgnoreNotFound(err) == nil {
+				continue
+			}
+			tunnel.Close()
+			return nil, err
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		url := clientset.CoreV1().RESTClient().Post().
+			Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+		dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+		port, err := forwardPort(dialer, kafkaSecurity.ClientPort(), tunnel.stop)
+		if err != nil {
+			tunnel.Close()
+			return nil, fmt.Errorf("failed to forward broker pod %s: %w", pod.Name, err)
+		}
+		tunnel.ports[nodeId] = port
+		tunnel.serverNames[nodeId] = podDNSName(pod)
+	}
+	if len(tunnel.ports) == 0 {
+		tunnel.Close()
+		return nil, fmt.Errorf("KafkaCluster %s has no running broker", cluster.Name)
+	}
+	if tlsOpts.serverName != "" {
+		for nodeId := range tunnel.serverNames {
+			tunnel.serverNames[nodeId] = tlsOpts.serverName
+		}
+	}
+	return tunnel, nil
+}
+
+// forwardPort forwards a random local port to the pod port until stop is closed
+func forwardPort(dialer httpstream.Dialer, podPort int, stop chan struct{}) (uint16, error) {
+	ready := make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", podPort)}, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return 0, err
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- fw.ForwardPorts() }()
+	select {
+	case <-ready:
+	case err := <-errs:
+		return 0, err
+	}
+	ports, err := fw.GetPorts()
+	if err != nil {
+		return 0, err
+	}
+	return ports[0].Local, nil
+}
+
+// podDNSName returns the DNS name of a StatefulSet pod behind its headless service
+func podDNSName(pod *corev1.Pod) string {
+	if pod.Spec.Subdomain == "" {
+		return pod.Name
+	}
+	return fmt.Sprintf("%s.%s.%s.svc.cluster.local", pod.Spec.Hostname, pod.Spec.Subdomain, pod.Namespace)
+}
+
+// NodeIds returns the node IDs of the forwarded brokers, to bootstrap an admin client
+func (t *brokerTunnel) NodeIds() []int32 {
+	return slices.Sorted(maps.Keys(t.ports))
+}
+
+// Dial implements admin.Dialer, the advertised addresses of the brokers are replaced by the forwarded ports
+func (t *brokerTunnel) Dial(ctx context.Context, nodeId int32) (net.Conn, error) {
+	port, ok := t.ports[nodeId]
+	if !ok {
+		return nil, fmt.Errorf("broker %d is not forwarded", nodeId)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if err != nil || t.tlsConfig == nil {
+		return conn, err
+	}
+	config := t.tlsConfig.Clone()
+	config.ServerName = t.serverNames[nodeId]
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func (t *brokerTunnel) Close() {
+	close(t.stop)
+}
+
+// adminClient forwards the brokers of the cluster and returns an admin client over the tunnel
+func adminClient(ctx context.Context, o *options, name string, tlsOpts *tlsOptions) (*admin.Client, func(), error) {
+	c, cluster, err := o.kafkaCluster(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	tunnel, err := openBrokerTunnel(ctx, o, c, cluster, tlsOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := admin.NewClient(tunnel.Dial, tunnel.NodeIds())
+	return client, func() {
+		client.Close()
+		tunnel.Close()
+	}, nil
+}
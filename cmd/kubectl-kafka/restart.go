@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	appv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+// restartPollInterval is how often the rollout of a restarted role group is checked
+const restartPollInterval = 5 * time.Second
+
+func newRestartCommand(o *options) *cobra.Command {
+	var roleGroups []string
+	var waitRollout bool
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "restart NAME",
+		Short: "Roll the brokers of a KafkaCluster",
+		Long: `Roll the brokers of a KafkaCluster one role group after the other.
+The restart is requested on the KafkaCluster, so the operator keeps it on later reconciliations.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cluster, err := o.kafkaCluster(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if cluster.Spec.Brokers == nil {
+				return fmt.Errorf("KafkaCluster %s has no brokers", cluster.Name)
+			}
+			if len(roleGroups) == 0 {
+				roleGroups = slices.Sorted(maps.Keys(cluster.Spec.Brokers.RoleGroups))
+			}
+			for _, name := range roleGroups {
+				if _, ok := cluster.Spec.Brokers.RoleGroups[name]; !ok {
+					return fmt.Errorf("KafkaCluster %s has no role group %s", cluster.Name, name)
+				}
+			}
+			for _, name := range roleGroups {
+				if err := restartRoleGroup(cmd.Context(), cmd.OutOrStdout(), c, cluster, name, waitRollout, timeout); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&roleGroups, "role-group", nil, "The role groups to restart, all by default.")
+	cmd.Flags().BoolVar(&waitRollout, "wait", true, "Wait for the rollout of a role group before restarting the next one.")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Minute, "How long to wait for the rollout of a role group.")
+	return cmd
+}
+
+func restartRoleGroup(
+	ctx context.Context,
+	out io.Writer,
+	c ctrlclient.Client,
+	cluster *kafkav1alpha1.KafkaCluster,
+	roleGroup string,
+	waitRollout bool,
+	timeout time.Duration,
+) error {
+	statefulSets, err := brokerStatefulSets(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	sts, ok := statefulSets[roleGroup]
+	if !ok {
+		return fmt.Errorf("role group %s of KafkaCluster %s has no StatefulSet yet", roleGroup, cluster.Name)
+	}
+	generation := sts.Generation
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				kafkav1alpha1.RestartAnnotationPrefix + roleGroup: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(ctx, cluster, ctrlclient.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("failed to request the restart of role group %s: %w", roleGroup, err)
+	}
+	fmt.Fprintf(out, "restart of role group %s requested\n", roleGroup)
+	if !waitRollout {
+		return nil
+	}
+
+	key := ctrlclient.ObjectKeyFromObject(sts)
+	err = wait.PollUntilContextTimeout(ctx, restartPollInterval, timeout, false, func(ctx context.Context) (bool, error) {
+		current := &appv1.StatefulSet{}
+		if err := c.Get(ctx, key, current); err != nil {
+			return false, err
+		}
+		return current.Generation > generation && rolledOut(current), nil
+	})
+	if err != nil {
+		return fmt.Errorf("role group %s did not roll out: %w", roleGroup, err)
+	}
+	fmt.Fprintf(out, "role group %s restarted\n", roleGroup)
+	return nil
+}
+
+// rolledOut reports whether all pods of the StatefulSet run its current template and are ready
+func rolledOut(sts *appv1.StatefulSet) bool {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return sts.Status.ObservedGeneration == sts.Generation &&
+		sts.Status.UpdatedReplicas == replicas &&
+		sts.Status.ReadyReplicas == replicas &&
+		sts.Status.CurrentRevision == sts.Status.UpdateRevision
+}
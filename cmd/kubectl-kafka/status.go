@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	appv1 "k8s.io/api/apps/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/controller"
+)
+
+func newStatusCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status NAME",
+		Short: "Show the conditions, version and role groups of a KafkaCluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cluster, err := o.kafkaCluster(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printStatus(cmd.Context(), cmd.OutOrStdout(), c, cluster)
+		},
+	}
+}
+
+func printStatus(ctx context.Context, out io.Writer, c ctrlclient.Client, cluster *kafkav1alpha1.KafkaCluster) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", cluster.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", cluster.Namespace)
+	fmt.Fprintf(w, "Version:\t%s\n", cluster.Status.CurrentVersion)
+	if upgrade := cluster.Status.Upgrade; upgrade != nil {
+		fmt.Fprintf(w, "Upgrade:\t%s -> %s (%s)\n", upgrade.FromVersion, upgrade.ToVersion, upgrade.Phase)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "CONDITION\tSTATUS\tREASON\tMESSAGE")
+	for _, condition := range cluster.Status.Conditions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+	fmt.Fprintln(w)
+
+	statefulSets, err := brokerStatefulSets(ctx, c, cluster)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "ROLE GROUP\tREPLICAS\tREADY\tUP-TO-DATE\tBROKER IDS")
+	if cluster.Spec.Brokers != nil {
+		for _, name := range slices.Sorted(maps.Keys(cluster.Spec.Brokers.RoleGroups)) {
+			replicas := cluster.Spec.Brokers.RoleGroups[name].Replicas
+			ready, updated := "-", "-"
+			if sts, ok := statefulSets[name]; ok {
+				ready = fmt.Sprint(sts.Status.ReadyReplicas)
+				updated = fmt.Sprint(sts.Status.UpdatedReplicas)
+			}
+			ids := "-"
+			if offset, ok := cluster.Status.BrokerIdOffsets[name]; ok && replicas > 0 {
+				ids = fmt.Sprintf("%d-%d", offset, offset+replicas-1)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", name, replicas, ready, updated, ids)
+		}
+	}
+	return w.Flush()
+}
+
+// brokerStatefulSets returns the broker StatefulSets of the cluster by role group
+func brokerStatefulSets(ctx context.Context, c ctrlclient.Client, cluster *kafkav1alpha1.KafkaCluster) (map[string]*appv1.StatefulSet, error) {
+	list := &appv1.StatefulSetList{}
+	if err := c.List(ctx, list, ctrlclient.InNamespace(cluster.Namespace), ctrlclient.MatchingLabels(controller.BrokerPodLabels(cluster.Name))); err != nil {
+		return nil, err
+	}
+	statefulSets := make(map[string]*appv1.StatefulSet, len(list.Items))
+	for i := range list.Items {
+		statefulSets[list.Items[i].Labels[constants.LabelKubernetesRoleGroup]] = &list.Items[i]
+	}
+	return statefulSets, nil
+}
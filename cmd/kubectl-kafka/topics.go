@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zncdatadev/kafka-operator/internal/admin"
+)
+
+func newTopicsCommand(o *options) *cobra.Command {
+	var internal bool
+	tlsOpts := &tlsOptions{}
+	cmd := &cobra.Command{
+		Use:   "topics NAME",
+		Short: "List the topics of a KafkaCluster through a port-forward to its brokers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeClient, err := adminClient(cmd.Context(), o, args[0], tlsOpts)
+			if err != nil {
+				return err
+			}
+			defer closeClient()
+			metadata, err := client.Metadata(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printTopics(cmd.OutOrStdout(), metadata, internal)
+		},
+	}
+	cmd.Flags().BoolVar(&internal, "internal", false, "Also list the internal topics, e.g. __consumer_offsets.")
+	tlsOpts.addFlags(cmd)
+	return cmd
+}
+
+func printTopics(out io.Writer, metadata *admin.Metadata, internal bool) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITIONS\tREPLICATION-FACTOR\tUNDER-REPLICATED")
+	for _, topic := range metadata.Topics {
+		if topic.Internal && !internal {
+			continue
+		}
+		if topic.Err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t%v\n", topic.Name, topic.Err)
+			continue
+		}
+		replicationFactor, underReplicated := 0, 0
+		for _, partition := range topic.Partitions {
+			replicationFactor = max(replicationFactor, len(partition.Replicas))
+			if len(partition.Isr) < len(partition.Replicas) {
+				underReplicated++
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", topic.Name, len(topic.Partitions), replicationFactor, underReplicated)
+	}
+	return w.Flush()
+}
+
+func newLagCommand(o *options) *cobra.Command {
+	var groupIds []string
+	tlsOpts := &tlsOptions{}
+	cmd := &cobra.Command{
+		Use:   "lag NAME",
+		Short: "Show the lag of the consumer groups of a KafkaCluster through a port-forward to its brokers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, closeClient, err := adminClient(cmd.Context(), o, args[0], tlsOpts)
+			if err != nil {
+				return err
+			}
+			defer closeClient()
+			metadata, err := client.Metadata(cmd.Context())
+			if err != nil {
+				return err
+			}
+			groups, err := client.ConsumerGroups(cmd.Context(), metadata.Brokers)
+			if err != nil {
+				return err
+			}
+			if len(groupIds) > 0 {
+				groups = slices.DeleteFunc(groups, func(g admin.Group) bool { return !slices.Contains(groupIds, g.Id) })
+			}
+			lags, err := client.Lag(cmd.Context(), metadata, groups)
+			if err != nil {
+				return err
+			}
+			return printLag(cmd.OutOrStdout(), lags)
+		},
+	}
+	cmd.Flags().StringSliceVar(&groupIds, "group", nil, "The consumer groups to show, all by default.")
+	tlsOpts.addFlags(cmd)
+	return cmd
+}
+
+func printLag(out io.Writer, lags []admin.PartitionLag) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tTOPIC\tPARTITION\tCOMMITTED\tEND\tLAG")
+	for _, lag := range lags {
+		end := "-"
+		if lag.End >= 0 {
+			end = fmt.Sprint(lag.End)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%d\n", lag.Group, lag.Topic, lag.Partition, lag.Committed, end, lag.Lag())
+	}
+	return w.Flush()
+}
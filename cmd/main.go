@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
@@ -27,18 +28,25 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
 	"github.com/zncdatadev/kafka-operator/internal/controller"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+	kafkametrics "github.com/zncdatadev/kafka-operator/internal/metrics"
+	"github.com/zncdatadev/kafka-operator/internal/render"
 	"github.com/zncdatadev/kafka-operator/internal/util/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -58,6 +66,11 @@ func init() {
 }
 
 func main() {
+	// `render` prints the resources of a KafkaCluster instead of running the operator
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(runRender(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -166,13 +179,20 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		WebhookServer:          webhookServer,
 		LeaderElectionID:       "6e8ac606.kubedoop.dev",
+		// the operator only reads its own pods, caching every pod of the cluster is too expensive
+		Cache: cache.Options{
+			ByObject: map[ctrlclient.Object]cache.ByObject{
+				&corev1.Pod{}: {Label: controller.ManagedPodSelector()},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -191,16 +211,88 @@ func main() {
 	}
 
 	if err = (&controller.KafkaClusterReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      setupLog,
+		Recorder: event.NewRecorder(mgr.GetEventRecorder("kafka-operator"), event.DefaultDedupInterval),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaCluster")
+		os.Exit(1)
+	}
+
+	if err = (&controller.KafkaConnectClusterReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Log:    setupLog,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "KafkaCluster")
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaConnectCluster")
+		os.Exit(1)
+	}
+
+	if err = (&controller.KafkaConnectorReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Log:    setupLog,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaConnector")
+		os.Exit(1)
+	}
+
+	if err = (&controller.KafkaMirrorMaker2Reconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Log:    setupLog,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaMirrorMaker2")
+		os.Exit(1)
+	}
+
+	podLogs, err := controller.NewPodLogsFunc(restConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create pod logs client")
+		os.Exit(1)
+	}
+
+	if err = (&controller.KafkaBackupReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      setupLog,
+		Recorder: event.NewRecorder(mgr.GetEventRecorder("kafka-operator"), event.DefaultDedupInterval),
+		PodLogs:  podLogs,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaBackup")
+		os.Exit(1)
+	}
+
+	if err = (&controller.KafkaRestoreReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      setupLog,
+		Recorder: event.NewRecorder(mgr.GetEventRecorder("kafka-operator"), event.DefaultDedupInterval),
+		PodLogs:  podLogs,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaRestore")
+		os.Exit(1)
+	}
+
+	if err = (&controller.KafkaSnapshotReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      setupLog,
+		Recorder: event.NewRecorder(mgr.GetEventRecorder("kafka-operator"), event.DefaultDedupInterval),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaSnapshot")
 		os.Exit(1)
 	}
 
 	// +kubebuilder:scaffold:builder
 
+	// operator metrics about the managed clusters, served by the metrics server of the manager
+	if err := ctrlmetrics.Registry.Register(kafkametrics.NewClusterCollector(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -216,3 +308,58 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// runRender renders the KafkaCluster of the files, see package render. It returns the exit code.
+func runRender(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: manager render [--crd-dir DIR] FILE...")
+		fmt.Fprintln(flags.Output(), "Prints the resources the operator creates for the KafkaCluster of the YAML files, '-' reads stdin.")
+		fmt.Fprintln(flags.Output(), "The other objects of the files stand in for the objects the KafkaCluster depends on,")
+		fmt.Fprintln(flags.Output(), "e.g. the ZooKeeper and vector aggregator discovery ConfigMaps or Listeners with their status.")
+		flags.PrintDefaults()
+	}
+	crdDir := flags.String("crd-dir", "config/crd/bases", "The directory of the CRDs, their defaults are applied like the API server does.")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	defaults, err := render.LoadDefaults(*crdDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var objects []ctrlclient.Object
+	for _, name := range flags.Args() {
+		decoded, err := decodeFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to decode %s: %v\n", name, err)
+			return 1
+		}
+		objects = append(objects, decoded...)
+	}
+	rendered, err := render.Render(context.Background(), scheme, defaults, objects, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := render.Write(os.Stdout, rendered); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func decodeFile(name string) ([]ctrlclient.Object, error) {
+	if name == "-" {
+		return render.Decode(scheme, os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return render.Decode(scheme, f)
+}
@@ -0,0 +1,340 @@
+// Package admin implements the few requests of the Kafka protocol needed to inspect a cluster:
+// its topics and the lag of its consumer groups. Authentication is not supported: neither SASL, e.g. Kerberos,
+// nor TLS client certificates, callers must only connect to listeners without client authentication.
+package admin
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"syscall"
+)
+
+const defaultClientId = "kubectl-kafka"
+
+// latestTimestamp requests the log end offset in a ListOffsets request
+const latestTimestamp int64 = -1
+
+// ErrAuthenticationRequired is returned if a broker closes a new connection without answering the first request,
+// as brokers do on listeners expecting a SASL handshake first
+var ErrAuthenticationRequired = errors.New("broker closed the connection without a response, " +
+	"its listener probably requires SASL authentication, which is not supported")
+
+// Dialer opens a connection to the broker with the node ID
+type Dialer func(ctx context.Context, nodeId int32) (net.Conn, error)
+
+type Broker struct {
+	NodeId int32
+	Host   string
+	Port   int32
+	Rack   string
+}
+
+type Partition struct {
+	Id       int32
+	Leader   int32
+	Replicas []int32
+	Isr      []int32
+	Err      error
+}
+
+type Topic struct {
+	Name       string
+	Internal   bool
+	Partitions []Partition
+	Err        error
+}
+
+type Metadata struct {
+	ClusterId    string
+	ControllerId int32
+	Brokers      []Broker
+	Topics       []Topic
+}
+
+// Group is a consumer group and the broker coordinating it
+type Group struct {
+	Id           string
+	ProtocolType string
+	Coordinator  int32
+}
+
+type TopicPartition struct {
+	Topic     string
+	Partition int32
+}
+
+// PartitionLag is the lag of a consumer group on a partition, the end offset is -1 if it is unknown
+type PartitionLag struct {
+	Group string
+	TopicPartition
+	Committed int64
+	End       int64
+}
+
+// Lag returns the number of records the group did not consume yet, 0 if the end offset is unknown
+func (l PartitionLag) Lag() int64 {
+	if l.End < 0 || l.Committed < 0 {
+		return 0
+	}
+	return max(l.End-l.Committed, 0)
+}
+
+// Client sends requests to the brokers of a cluster, it is not safe for concurrent use
+type Client struct {
+	dial Dialer
+	// brokers tried in order for requests any broker can answer
+	bootstrap []int32
+	ClientId  string
+
+	conns map[int32]*conn
+}
+
+// NewClient returns a client connecting to the brokers with dial, metadata is requested from the bootstrap brokers
+func NewClient(dial Dialer, bootstrap []int32) *Client {
+	return &Client{
+		dial:      dial,
+		bootstrap: bootstrap,
+		ClientId:  defaultClientId,
+		conns:     map[int32]*conn{},
+	}
+}
+
+// Close closes the connections to all brokers
+func (c *Client) Close() error {
+	var errs []error
+	for nodeId, conn := range c.conns {
+		errs = append(errs, conn.Close())
+		delete(c.conns, nodeId)
+	}
+	return errors.Join(errs...)
+}
+
+// request sends a request to a broker and returns a decoder of the response,
+// the connection is closed on errors so the next request opens a new one
+func (c *Client) request(ctx context.Context, nodeId int32, apiKey, apiVersion int16, body []byte) (*decoder, error) {
+	cn, ok := c.conns[nodeId]
+	opened := !ok
+	if !ok {
+		netConn, err := c.dial(ctx, nodeId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to broker %d: %w", nodeId, err)
+		}
+		cn = &conn{Conn: netConn, clientId: c.ClientId}
+		c.conns[nodeId] = cn
+	}
+	response, err := cn.roundTrip(ctx, apiKey, apiVersion, body)
+	if err != nil {
+		_ = cn.Close()
+		delete(c.conns, nodeId)
+		if opened && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)) {
+			return nil, fmt.Errorf("request to broker %d failed: %w: %w", nodeId, ErrAuthenticationRequired, err)
+		}
+		return nil, fmt.Errorf("request to broker %d failed: %w", nodeId, err)
+	}
+	return &decoder{buf: response}, nil
+}
+
+// Metadata returns the brokers and all topics of the cluster, asking the bootstrap brokers in order
+func (c *Client) Metadata(ctx context.Context) (*Metadata, error) {
+	body := &encoder{}
+	body.arrayLen(-1) // all topics
+	body.bool(false)  // allow_auto_topic_creation
+
+	var errs []error
+	for _, nodeId := range c.bootstrap {
+		d, err := c.request(ctx, nodeId, apiKeyMetadata, versionMetadata, body.buf)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return decodeMetadata(d)
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no bootstrap broker")
+	}
+	return nil, errors.Join(errs...)
+}
+
+func decodeMetadata(d *decoder) (*Metadata, error) {
+	metadata := &Metadata{}
+	d.int32() // throttle_time_ms
+	for range d.arrayLen() {
+		broker := Broker{NodeId: d.int32(), Host: d.string(), Port: d.int32(), Rack: d.string()}
+		metadata.Brokers = append(metadata.Brokers, broker)
+	}
+	metadata.ClusterId = d.string()
+	metadata.ControllerId = d.int32()
+	for range d.arrayLen() {
+		topic := Topic{Err: errorCode(d.int16()), Name: d.string(), Internal: d.bool()}
+		for range d.arrayLen() {
+			partition := Partition{Err: errorCode(d.int16()), Id: d.int32(), Leader: d.int32()}
+			partition.Replicas = d.int32Array()
+			partition.Isr = d.int32Array()
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		slices.SortFunc(topic.Partitions, func(a, b Partition) int { return cmp.Compare(a.Id, b.Id) })
+		metadata.Topics = append(metadata.Topics, topic)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid metadata response: %w", d.err)
+	}
+	slices.SortFunc(metadata.Brokers, func(a, b Broker) int { return cmp.Compare(a.NodeId, b.NodeId) })
+	slices.SortFunc(metadata.Topics, func(a, b Topic) int { return cmp.Compare(a.Name, b.Name) })
+	return metadata, nil
+}
+
+// ConsumerGroups returns the groups coordinated by the brokers, sorted by ID.
+// Every broker only lists the groups it coordinates, so all of them are asked.
+func (c *Client) ConsumerGroups(ctx context.Context, brokers []Broker) ([]Group, error) {
+	var groups []Group
+	for _, broker := range brokers {
+		d, err := c.request(ctx, broker.NodeId, apiKeyListGroups, versionListGroups, nil)
+		if err != nil {
+			return nil, err
+		}
+		d.int32() // throttle_time_ms
+		if err := errorCode(d.int16()); err != nil {
+			return nil, fmt.Errorf("failed to list the groups of broker %d: %w", broker.NodeId, err)
+		}
+		for range d.arrayLen() {
+			groups = append(groups, Group{Id: d.string(), ProtocolType: d.string(), Coordinator: broker.NodeId})
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("invalid list groups response of broker %d: %w", broker.NodeId, d.err)
+		}
+	}
+	slices.SortFunc(groups, func(a, b Group) int { return cmp.Compare(a.Id, b.Id) })
+	return groups, nil
+}
+
+// CommittedOffsets returns the committed offsets of a group on all partitions it committed to
+func (c *Client) CommittedOffsets(ctx context.Context, group Group) (map[TopicPartition]int64, error) {
+	body := &encoder{}
+	body.string(group.Id)
+	body.arrayLen(-1) // all topics
+	d, err := c.request(ctx, group.Coordinator, apiKeyOffsetFetch, versionOffsetFetch, body.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := map[TopicPartition]int64{}
+	var errs []error
+	for range d.arrayLen() {
+		topic := d.string()
+		for range d.arrayLen() {
+			tp := TopicPartition{Topic: topic, Partition: d.int32()}
+			offset := d.int64()
+			d.string() // metadata
+			if err := errorCode(d.int16()); err != nil {
+				errs = append(errs, fmt.Errorf("%s-%d: %w", tp.Topic, tp.Partition, err))
+				continue
+			}
+			if offset >= 0 {
+				offsets[tp] = offset
+			}
+		}
+	}
+	if err := errorCode(d.int16()); err != nil {
+		errs = append(errs, err)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid offset fetch response of group %s: %w", group.Id, d.err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to fetch the offsets of group %s: %w", group.Id, errors.Join(errs...))
+	}
+	return offsets, nil
+}
+
+// EndOffsets returns the log end offsets of the partitions, asking the leader of every partition.
+// Partitions without a leader are missing in the result.
+func (c *Client) EndOffsets(ctx context.Context, metadata *Metadata, partitions []TopicPartition) (map[TopicPartition]int64, error) {
+	leaders := map[TopicPartition]int32{}
+	for _, topic := range metadata.Topics {
+		for _, partition := range topic.Partitions {
+			leaders[TopicPartition{Topic: topic.Name, Partition: partition.Id}] = partition.Leader
+		}
+	}
+
+	byLeader := map[int32]map[string][]int32{}
+	for _, tp := range partitions {
+		leader, ok := leaders[tp]
+		if !ok || leader < 0 {
+			continue
+		}
+		if byLeader[leader] == nil {
+			byLeader[leader] = map[string][]int32{}
+		}
+		byLeader[leader][tp.Topic] = append(byLeader[leader][tp.Topic], tp.Partition)
+	}
+
+	offsets := map[TopicPartition]int64{}
+	for _, leader := range slices.Sorted(maps.Keys(byLeader)) {
+		topics := byLeader[leader]
+		body := &encoder{}
+		body.int32(-1) // replica_id of a consumer
+		body.arrayLen(len(topics))
+		for _, topic := range slices.Sorted(maps.Keys(topics)) {
+			body.string(topic)
+			body.arrayLen(len(topics[topic]))
+			for _, partition := range topics[topic] {
+				body.int32(partition)
+				body.int64(latestTimestamp)
+			}
+		}
+
+		d, err := c.request(ctx, leader, apiKeyListOffsets, versionListOffsets, body.buf)
+		if err != nil {
+			return nil, err
+		}
+		for range d.arrayLen() {
+			topic := d.string()
+			for range d.arrayLen() {
+				tp := TopicPartition{Topic: topic, Partition: d.int32()}
+				code := d.int16()
+				d.int64() // timestamp
+				offset := d.int64()
+				if code == 0 {
+					offsets[tp] = offset
+				}
+			}
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("invalid list offsets response of broker %d: %w", leader, d.err)
+		}
+	}
+	return offsets, nil
+}
+
+// Lag returns the lag of the groups on every partition they committed to, sorted by group, topic and partition
+func (c *Client) Lag(ctx context.Context, metadata *Metadata, groups []Group) ([]PartitionLag, error) {
+	var lags []PartitionLag
+	for _, group := range groups {
+		committed, err := c.CommittedOffsets(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		partitions := slices.Collect(maps.Keys(committed))
+		end, err := c.EndOffsets(ctx, metadata, partitions)
+		if err != nil {
+			return nil, err
+		}
+		for _, tp := range partitions {
+			lag := PartitionLag{Group: group.Id, TopicPartition: tp, Committed: committed[tp], End: -1}
+			if offset, ok := end[tp]; ok {
+				lag.End = offset
+			}
+			lags = append(lags, lag)
+		}
+	}
+	slices.SortFunc(lags, func(a, b PartitionLag) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+	})
+	return lags, nil
+}
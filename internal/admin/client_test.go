@@ -0,0 +1,101 @@
+package admin_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zncdatadev/kafka-operator/internal/admin"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx     context.Context
+		cluster *kafkaCluster
+		client  *admin.Client
+	)
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+
+		cluster = newKafkaCluster(0, 1, 2)
+		DeferCleanup(cluster.Close)
+		cluster.topics["orders"] = []int32{0, 1, 2}
+		cluster.topics["__consumer_offsets"] = []int32{2}
+
+		client = admin.NewClient(cluster.Dial, []int32{0, 1, 2})
+		DeferCleanup(client.Close)
+	})
+
+	It("reads the brokers and topics", func() {
+		metadata, err := client.Metadata(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata.ClusterId).To(Equal("cluster-id"))
+		Expect(metadata.Brokers).To(HaveLen(3))
+		Expect(metadata.Brokers[1]).To(Equal(admin.Broker{NodeId: 1, Host: "broker-1.kafka.svc", Port: 9092}))
+
+		Expect(metadata.Topics).To(HaveLen(2))
+		Expect(metadata.Topics[0].Name).To(Equal("__consumer_offsets"))
+		Expect(metadata.Topics[0].Internal).To(BeTrue())
+		orders := metadata.Topics[1]
+		Expect(orders.Name).To(Equal("orders"))
+		Expect(orders.Partitions).To(HaveLen(3))
+		Expect(orders.Partitions[2]).To(Equal(admin.Partition{Id: 2, Leader: 2, Replicas: []int32{2}, Isr: []int32{2}}))
+	})
+
+	It("falls back to the next bootstrap broker", func() {
+		client = admin.NewClient(cluster.Dial, []int32{5, 1})
+		DeferCleanup(client.Close)
+
+		metadata, err := client.Metadata(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata.Brokers).To(HaveLen(3))
+		Expect(cluster.Requests()).To(Equal([]string{"1 3"}))
+	})
+
+	It("computes the lag of the consumer groups", func() {
+		cluster.groups["billing"] = &standinGroup{coordinator: 1, committed: map[admin.TopicPartition]int64{
+			{Topic: "orders", Partition: 0}: 10,
+			{Topic: "orders", Partition: 2}: 25,
+		}}
+		cluster.groups["audit"] = &standinGroup{coordinator: 2, committed: map[admin.TopicPartition]int64{
+			{Topic: "orders", Partition: 1}: 7,
+		}}
+		cluster.endOffsets[admin.TopicPartition{Topic: "orders", Partition: 0}] = 15
+		cluster.endOffsets[admin.TopicPartition{Topic: "orders", Partition: 1}] = 7
+		cluster.endOffsets[admin.TopicPartition{Topic: "orders", Partition: 2}] = 40
+
+		metadata, err := client.Metadata(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		groups, err := client.ConsumerGroups(ctx, metadata.Brokers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]admin.Group{
+			{Id: "audit", ProtocolType: "consumer", Coordinator: 2},
+			{Id: "billing", ProtocolType: "consumer", Coordinator: 1},
+		}))
+
+		lags, err := client.Lag(ctx, metadata, groups)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lags).To(Equal([]admin.PartitionLag{
+			{Group: "audit", TopicPartition: admin.TopicPartition{Topic: "orders", Partition: 1}, Committed: 7, End: 7},
+			{Group: "billing", TopicPartition: admin.TopicPartition{Topic: "orders", Partition: 0}, Committed: 10, End: 15},
+			{Group: "billing", TopicPartition: admin.TopicPartition{Topic: "orders", Partition: 2}, Committed: 25, End: 40},
+		}))
+		Expect(lags[2].Lag()).To(BeEquivalentTo(15))
+
+		// the offsets are fetched from the coordinator, the end offsets from the leaders
+		Expect(cluster.Requests()).To(ContainElements("2 9", "1 2", "1 9", "0 2", "2 2"))
+	})
+
+	It("fails on group errors", func() {
+		cluster.groups["billing"] = &standinGroup{coordinator: 0, errorCode: 30}
+
+		_, err := client.CommittedOffsets(ctx, admin.Group{Id: "billing", Coordinator: 0})
+		Expect(err).To(MatchError(ContainSubstring("GROUP_AUTHORIZATION_FAILED")))
+		Expect(err).To(MatchError(admin.Error(30)))
+	})
+})
@@ -0,0 +1,231 @@
+package admin
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// API keys and versions of the requests sent by the client.
+// The versions are the lowest ones supported by Kafka 2.x up to 4.x, none of them uses the flexible encoding.
+const (
+	apiKeyListOffsets  int16 = 2
+	apiKeyMetadata     int16 = 3
+	apiKeyOffsetFetch  int16 = 9
+	apiKeyListGroups   int16 = 16
+	versionListOffsets int16 = 1
+	versionMetadata    int16 = 4
+	versionOffsetFetch int16 = 2
+	versionListGroups  int16 = 1
+)
+
+// maxResponseSize guards against reading garbage, e.g. a TLS alert, as the size of a response
+const maxResponseSize = 100 << 20
+
+var errShortResponse = errors.New("kafka response is truncated")
+
+// Error is a Kafka protocol error code
+type Error int16
+
+var errorNames = map[Error]string{
+	-1: "UNKNOWN_SERVER_ERROR",
+	3:  "UNKNOWN_TOPIC_OR_PARTITION",
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_OR_FOLLOWER",
+	7:  "REQUEST_TIMED_OUT",
+	14: "COORDINATOR_LOAD_IN_PROGRESS",
+	15: "COORDINATOR_NOT_AVAILABLE",
+	16: "NOT_COORDINATOR",
+	29: "TOPIC_AUTHORIZATION_FAILED",
+	30: "GROUP_AUTHORIZATION_FAILED",
+	31: "CLUSTER_AUTHORIZATION_FAILED",
+	35: "UNSUPPORTED_VERSION",
+	69: "GROUP_ID_NOT_FOUND",
+}
+
+func (e Error) Error() string {
+	if name, ok := errorNames[e]; ok {
+		return fmt.Sprintf("kafka error %d %s", int16(e), name)
+	}
+	return fmt.Sprintf("kafka error %d", int16(e))
+}
+
+// errorCode returns the error of a code of a response, nil for 0
+func errorCode(code int16) error {
+	if code == 0 {
+		return nil
+	}
+	return Error(code)
+}
+
+// encoder appends the primitive types of the Kafka protocol to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.int8(1)
+	} else {
+		e.int8(0)
+	}
+}
+
+func (e *encoder) int16(v int16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+}
+
+func (e *encoder) int32(v int32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *encoder) int64(v int64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v))
+}
+
+func (e *encoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) nullableString(v *string) {
+	if v == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*v)
+}
+
+// arrayLen writes the length of an array, -1 is a null array
+func (e *encoder) arrayLen(n int) {
+	e.int32(int32(n))
+}
+
+// decoder reads the primitive types of the Kafka protocol, the first error is kept and stops decoding
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = errShortResponse
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) bool() bool {
+	b := d.take(1)
+	return b != nil && b[0] != 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// string reads a string, a null string is returned as empty
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+// arrayLen reads the length of an array, a null array has no elements
+func (d *decoder) arrayLen() int {
+	n := int(d.int32())
+	if n < 0 {
+		return 0
+	}
+	// every element takes at least one byte, a larger length is garbage
+	if d.err == nil && n > len(d.buf) {
+		d.err = errShortResponse
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) int32Array() []int32 {
+	n := d.arrayLen()
+	values := make([]int32, 0, n)
+	for range n {
+		values = append(values, d.int32())
+	}
+	return values
+}
+
+// conn is a connection to a broker, requests are sent one at a time
+type conn struct {
+	net.Conn
+	clientId      string
+	correlationId int32
+}
+
+// roundTrip sends a request with the header v1 and returns the body of the response after the header v0
+func (c *conn) roundTrip(ctx context.Context, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	c.correlationId++
+	header := &encoder{buf: make([]byte, 4, 64+len(body))}
+	header.int16(apiKey)
+	header.int16(apiVersion)
+	header.int32(c.correlationId)
+	header.string(c.clientId)
+	request := append(header.buf, body...)
+	binary.BigEndian.PutUint32(request, uint32(len(request)-4))
+	if _, err := c.Write(request); err != nil {
+		return nil, err
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(c, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n < 4 || n > maxResponseSize {
+		return nil, fmt.Errorf("invalid kafka response size %d", n)
+	}
+	response := make([]byte, n)
+	if _, err := io.ReadFull(c, response); err != nil {
+		return nil, err
+	}
+	if id := int32(binary.BigEndian.Uint32(response)); id != c.correlationId {
+		return nil, fmt.Errorf("kafka response has correlation ID %d, expected %d", id, c.correlationId)
+	}
+	return response[4:], nil
+}
@@ -0,0 +1,223 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zncdatadev/kafka-operator/internal/admin"
+)
+
+// exchange is a request expected on the wire, including its size and header, and the response body returned for it
+type exchange struct {
+	request  []byte
+	response []byte
+}
+
+// clientIdBytes is the client ID of the request header v1
+var clientIdBytes = []byte{0x00, 0x0d, 'k', 'u', 'b', 'e', 'c', 't', 'l', '-', 'k', 'a', 'f', 'k', 'a'}
+
+// frame returns a request with its size, header v1 and body
+func frame(apiKey, apiVersion int16, correlationId int32, body ...byte) []byte {
+	request := binary.BigEndian.AppendUint16(nil, uint16(apiKey))
+	request = binary.BigEndian.AppendUint16(request, uint16(apiVersion))
+	request = binary.BigEndian.AppendUint32(request, uint32(correlationId))
+	request = append(request, clientIdBytes...)
+	request = append(request, body...)
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(request))), request...)
+}
+
+var (
+	// Metadata v4 of all topics without creating them
+	metadataRequest = frame(3, 4, 1,
+		0xff, 0xff, 0xff, 0xff, // topics: null
+		0x00, // allow_auto_topic_creation
+	)
+	metadataResponse = []byte{
+		0x00, 0x00, 0x00, 0x00, // throttle_time_ms
+		0x00, 0x00, 0x00, 0x01, // brokers
+		0x00, 0x00, 0x00, 0x01, // node_id
+		0x00, 0x02, 'b', '1', // host
+		0x00, 0x00, 0x23, 0x84, // port 9092
+		0xff, 0xff, // rack: null
+		0x00, 0x07, 'c', 'l', 'u', 's', 't', 'e', 'r', // cluster_id
+		0x00, 0x00, 0x00, 0x01, // controller_id
+		0x00, 0x00, 0x00, 0x01, // topics
+		0x00, 0x00, // error_code
+		0x00, 0x06, 'o', 'r', 'd', 'e', 'r', 's', // name
+		0x00,                   // is_internal
+		0x00, 0x00, 0x00, 0x02, // partitions
+		0x00, 0x00, // error_code
+		0x00, 0x00, 0x00, 0x01, // partition_index
+		0x00, 0x00, 0x00, 0x01, // leader_id
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, // replica_nodes [1]
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, // isr_nodes [1]
+		0x00, 0x05, // error_code LEADER_NOT_AVAILABLE
+		0x00, 0x00, 0x00, 0x00, // partition_index
+		0xff, 0xff, 0xff, 0xff, // leader_id
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, // replica_nodes [2]
+		0x00, 0x00, 0x00, 0x00, // isr_nodes []
+	}
+
+	// ListGroups v1 has no body
+	listGroupsRequest  = frame(16, 1, 2)
+	listGroupsResponse = []byte{
+		0x00, 0x00, 0x00, 0x00, // throttle_time_ms
+		0x00, 0x00, // error_code
+		0x00, 0x00, 0x00, 0x01, // groups
+		0x00, 0x07, 'b', 'i', 'l', 'l', 'i', 'n', 'g', // group_id
+		0x00, 0x08, 'c', 'o', 'n', 's', 'u', 'm', 'e', 'r', // protocol_type
+	}
+
+	// OffsetFetch v2 of all topics of the group
+	offsetFetchRequest = frame(9, 2, 3,
+		0x00, 0x07, 'b', 'i', 'l', 'l', 'i', 'n', 'g', // group_id
+		0xff, 0xff, 0xff, 0xff, // topics: null
+	)
+	offsetFetchResponse = []byte{
+		0x00, 0x00, 0x00, 0x01, // topics
+		0x00, 0x06, 'o', 'r', 'd', 'e', 'r', 's', // name
+		0x00, 0x00, 0x00, 0x02, // partitions
+		0x00, 0x00, 0x00, 0x01, // partition_index
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2a, // committed_offset 42
+		0xff, 0xff, // metadata: null
+		0x00, 0x00, // error_code
+		0x00, 0x00, 0x00, 0x00, // partition_index
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // committed_offset: none
+		0x00, 0x00, // metadata
+		0x00, 0x00, // error_code
+		0x00, 0x00, // error_code
+	}
+
+	// ListOffsets v1 of the latest offset of the partition led by broker 1
+	listOffsetsRequest = frame(2, 1, 4,
+		0xff, 0xff, 0xff, 0xff, // replica_id
+		0x00, 0x00, 0x00, 0x01, // topics
+		0x00, 0x06, 'o', 'r', 'd', 'e', 'r', 's', // name
+		0x00, 0x00, 0x00, 0x01, // partitions
+		0x00, 0x00, 0x00, 0x01, // partition_index
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // timestamp: latest
+	)
+	listOffsetsResponse = []byte{
+		0x00, 0x00, 0x00, 0x01, // topics
+		0x00, 0x06, 'o', 'r', 'd', 'e', 'r', 's', // name
+		0x00, 0x00, 0x00, 0x01, // partitions
+		0x00, 0x00, 0x00, 0x01, // partition_index
+		0x00, 0x00, // error_code
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // timestamp
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, // offset 100
+	}
+)
+
+// wireBroker answers the exchanges in order on a single connection and closes it on any other request.
+// It returns the error of the connection once it is closed.
+func wireBroker(exchanges ...exchange) (admin.Dialer, <-chan error) {
+	client, server := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		defer func() { _ = server.Close() }()
+		done <- serveExchanges(server, exchanges)
+	}()
+	dial := func(ctx context.Context, nodeId int32) (net.Conn, error) {
+		return client, nil
+	}
+	return dial, done
+}
+
+func serveExchanges(conn net.Conn, exchanges []exchange) error {
+	for _, e := range exchanges {
+		request := make([]byte, len(e.request))
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return err
+		}
+		if !bytes.Equal(request, e.request) {
+			return fmt.Errorf("unexpected request\n% x\nexpected\n% x", request, e.request)
+		}
+		correlationId := request[8:12]
+		response := binary.BigEndian.AppendUint32(nil, uint32(len(correlationId)+len(e.response)))
+		response = append(response, correlationId...)
+		if _, err := conn.Write(append(response, e.response...)); err != nil {
+			return err
+		}
+	}
+	// like a broker, the connection is closed after reading an unexpected request
+	request := make([]byte, 1024)
+	n, err := conn.Read(request)
+	if err == io.EOF {
+		return nil
+	}
+	return fmt.Errorf("unexpected request % x", request[:n])
+}
+
+var _ = Describe("Protocol", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("encodes the requests and decodes the responses of a lag query", func() {
+		dial, done := wireBroker(
+			exchange{metadataRequest, metadataResponse},
+			exchange{listGroupsRequest, listGroupsResponse},
+			exchange{offsetFetchRequest, offsetFetchResponse},
+			exchange{listOffsetsRequest, listOffsetsResponse},
+		)
+		client := admin.NewClient(dial, []int32{1})
+		DeferCleanup(client.Close)
+
+		metadata, err := client.Metadata(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata).To(Equal(&admin.Metadata{
+			ClusterId:    "cluster",
+			ControllerId: 1,
+			Brokers:      []admin.Broker{{NodeId: 1, Host: "b1", Port: 9092}},
+			Topics: []admin.Topic{{Name: "orders", Partitions: []admin.Partition{
+				{Id: 0, Leader: -1, Replicas: []int32{2}, Isr: []int32{}, Err: admin.Error(5)},
+				{Id: 1, Leader: 1, Replicas: []int32{1}, Isr: []int32{1}},
+			}}},
+		}))
+
+		groups, err := client.ConsumerGroups(ctx, metadata.Brokers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]admin.Group{{Id: "billing", ProtocolType: "consumer", Coordinator: 1}}))
+
+		// partitions without a committed offset are skipped
+		lags, err := client.Lag(ctx, metadata, groups)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lags).To(Equal([]admin.PartitionLag{
+			{Group: "billing", TopicPartition: admin.TopicPartition{Topic: "orders", Partition: 1}, Committed: 42, End: 100},
+		}))
+
+		Expect(client.Close()).To(Succeed())
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("fails on truncated responses", func() {
+		dial, _ := wireBroker(exchange{metadataRequest, metadataResponse[:len(metadataResponse)-2]})
+		client := admin.NewClient(dial, []int32{1})
+		DeferCleanup(client.Close)
+
+		_, err := client.Metadata(ctx)
+		Expect(err).To(MatchError(ContainSubstring("invalid metadata response: kafka response is truncated")))
+	})
+
+	It("reports brokers closing the connection as requiring authentication", func() {
+		// like a SASL listener receiving a request before the handshake
+		dial, _ := wireBroker()
+		client := admin.NewClient(dial, []int32{1})
+		DeferCleanup(client.Close)
+
+		_, err := client.Metadata(ctx)
+		Expect(err).To(MatchError(admin.ErrAuthenticationRequired))
+	})
+})
@@ -0,0 +1,286 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/zncdatadev/kafka-operator/internal/admin"
+)
+
+// kafkaCluster is a local stand-in of the brokers of a Kafka cluster, answering the requests of the admin client
+// from the topics, offsets and groups set by the tests. Every broker listens on its own port.
+type kafkaCluster struct {
+	mu        sync.Mutex
+	listeners map[int32]net.Listener
+
+	// leader of every partition of a topic
+	topics map[string][]int32
+	// log end offset of every partition
+	endOffsets map[admin.TopicPartition]int64
+	groups     map[string]*standinGroup
+
+	// requests in the form `<nodeId> <apiKey>`
+	requests []string
+}
+
+type standinGroup struct {
+	coordinator int32
+	committed   map[admin.TopicPartition]int64
+	errorCode   int16
+}
+
+func newKafkaCluster(nodeIds ...int32) *kafkaCluster {
+	c := &kafkaCluster{
+		listeners:  map[int32]net.Listener{},
+		topics:     map[string][]int32{},
+		endOffsets: map[admin.TopicPartition]int64{},
+		groups:     map[string]*standinGroup{},
+	}
+	for _, nodeId := range nodeIds {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			panic(err)
+		}
+		c.listeners[nodeId] = listener
+		go c.serve(nodeId, listener)
+	}
+	return c
+}
+
+func (c *kafkaCluster) Close() {
+	for _, listener := range c.listeners {
+		_ = listener.Close()
+	}
+}
+
+// Dial connects to the broker, like a port-forward to its pod
+func (c *kafkaCluster) Dial(ctx context.Context, nodeId int32) (net.Conn, error) {
+	listener, ok := c.listeners[nodeId]
+	if !ok {
+		return nil, fmt.Errorf("no broker %d", nodeId)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", listener.Addr().String())
+}
+
+func (c *kafkaCluster) Requests() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.requests)
+}
+
+func (c *kafkaCluster) serve(nodeId int32, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go c.handle(nodeId, conn)
+	}
+}
+
+func (c *kafkaCluster) handle(nodeId int32, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		request := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		r := &reader{buf: request}
+		apiKey := r.int16()
+		r.int16() // api_version
+		correlationId := r.int32()
+		r.string() // client_id
+
+		c.mu.Lock()
+		c.requests = append(c.requests, fmt.Sprintf("%d %d", nodeId, apiKey))
+		w := &writer{}
+		w.int32(correlationId)
+		switch apiKey {
+		case 2:
+			c.listOffsets(nodeId, r, w)
+		case 3:
+			c.metadata(w)
+		case 9:
+			c.offsetFetch(r, w)
+		case 16:
+			c.listGroups(nodeId, w)
+		}
+		c.mu.Unlock()
+
+		response := binary.BigEndian.AppendUint32(nil, uint32(len(w.buf)))
+		if _, err := conn.Write(append(response, w.buf...)); err != nil {
+			return
+		}
+	}
+}
+
+// metadata answers a Metadata v4 request
+func (c *kafkaCluster) metadata(w *writer) {
+	w.int32(0) // throttle_time_ms
+	nodeIds := make([]int32, 0, len(c.listeners))
+	for nodeId := range c.listeners {
+		nodeIds = append(nodeIds, nodeId)
+	}
+	slices.Sort(nodeIds)
+	w.int32(int32(len(nodeIds)))
+	for _, nodeId := range nodeIds {
+		w.int32(nodeId)
+		w.string(fmt.Sprintf("broker-%d.kafka.svc", nodeId))
+		w.int32(9092)
+		w.int16(-1) // rack
+	}
+	w.string("cluster-id")
+	w.int32(nodeIds[0])
+	w.int32(int32(len(c.topics)))
+	for topic, leaders := range c.topics {
+		w.int16(0)
+		w.string(topic)
+		w.bool(topic == "__consumer_offsets")
+		w.int32(int32(len(leaders)))
+		// in reverse order, the client sorts them
+		for i := len(leaders) - 1; i >= 0; i-- {
+			w.int16(0)
+			w.int32(int32(i))
+			w.int32(leaders[i])
+			w.int32(1)
+			w.int32(leaders[i])
+			w.int32(1)
+			w.int32(leaders[i])
+		}
+	}
+}
+
+// listGroups answers a ListGroups v1 request with the groups coordinated by the broker
+func (c *kafkaCluster) listGroups(nodeId int32, w *writer) {
+	var ids []string
+	for id, group := range c.groups {
+		if group.coordinator == nodeId {
+			ids = append(ids, id)
+		}
+	}
+	w.int32(0) // throttle_time_ms
+	w.int16(0)
+	w.int32(int32(len(ids)))
+	for _, id := range ids {
+		w.string(id)
+		w.string("consumer")
+	}
+}
+
+// offsetFetch answers an OffsetFetch v2 request for all topics
+func (c *kafkaCluster) offsetFetch(r *reader, w *writer) {
+	group, ok := c.groups[r.string()]
+	if !ok {
+		w.int32(0)
+		w.int16(0)
+		return
+	}
+	w.int32(int32(len(group.committed)))
+	for tp, offset := range group.committed {
+		w.string(tp.Topic)
+		w.int32(1)
+		w.int32(tp.Partition)
+		w.int64(offset)
+		w.string("")
+		w.int16(0)
+	}
+	w.int16(group.errorCode)
+}
+
+// listOffsets answers a ListOffsets v1 request, partitions not led by the broker get NOT_LEADER_OR_FOLLOWER
+func (c *kafkaCluster) listOffsets(nodeId int32, r *reader, w *writer) {
+	r.int32() // replica_id
+	topics := r.int32()
+	w.int32(topics)
+	for range topics {
+		topic := r.string()
+		w.string(topic)
+		partitions := r.int32()
+		w.int32(partitions)
+		for range partitions {
+			partition := r.int32()
+			r.int64() // timestamp
+			w.int32(partition)
+			if leaders := c.topics[topic]; int(partition) >= len(leaders) || leaders[partition] != nodeId {
+				w.int16(6)
+				w.int64(-1)
+				w.int64(-1)
+				continue
+			}
+			w.int16(0)
+			w.int64(-1)
+			w.int64(c.endOffsets[admin.TopicPartition{Topic: topic, Partition: partition}])
+		}
+	}
+}
+
+type reader struct {
+	buf []byte
+}
+
+func (r *reader) int16() int16 {
+	v := int16(binary.BigEndian.Uint16(r.buf))
+	r.buf = r.buf[2:]
+	return v
+}
+
+func (r *reader) int32() int32 {
+	v := int32(binary.BigEndian.Uint32(r.buf))
+	r.buf = r.buf[4:]
+	return v
+}
+
+func (r *reader) int64() int64 {
+	v := int64(binary.BigEndian.Uint64(r.buf))
+	r.buf = r.buf[8:]
+	return v
+}
+
+func (r *reader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	v := string(r.buf[:n])
+	r.buf = r.buf[n:]
+	return v
+}
+
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) bool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *writer) int16(v int16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(v))
+}
+
+func (w *writer) int32(v int32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v))
+}
+
+func (w *writer) int64(v int64) {
+	w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v))
+}
+
+func (w *writer) string(v string) {
+	w.int16(int16(len(v)))
+	w.buf = append(w.buf, v...)
+}
@@ -0,0 +1,265 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// sections of the dump, each holding the output of a Kafka command line tool
+const (
+	sectionTopics         = "topics"
+	sectionTopicConfigs   = "topic-configs"
+	sectionBrokerConfigs  = "broker-configs"
+	sectionACLs           = "acls"
+	sectionUsers          = "users"
+	sectionConsumerGroups = "consumer-groups"
+)
+
+var sections = []string{sectionTopics, sectionTopicConfigs, sectionBrokerConfigs, sectionACLs, sectionUsers, sectionConsumerGroups}
+
+// sectionPrefix starts the lines of the dump, the output of every tool is base64 encoded on a single line,
+// so it can be told apart from anything else in the log of the pod
+const sectionPrefix = "kafka-backup:"
+
+// DumpScript returns the shell script printing the metadata of the cluster, run in the Kafka image.
+// It connects to the brokers in $KAFKA with the client properties at commandConfig,
+// and reads the user entities from the ZooKeeper connection string in $ZOOKEEPER.
+func DumpScript(commandConfig string) string {
+	admin := fmt.Sprintf(`--bootstrap-server "$KAFKA" --command-config %s`, commandConfig)
+	return `set -u
+
+# prints the output of a command as a section of the dump, the output is only shown on failure
+dump() {
+  section=$1
+  shift
+  if ! "$@" > /tmp/section.out 2> /tmp/section.err; then
+    echo "dumping $section failed" >&2
+    cat /tmp/section.out /tmp/section.err >&2
+    exit 1
+  fi
+  echo "` + sectionPrefix + `$section:$(base64 -w0 < /tmp/section.out)"
+}
+
+# clusters without an authorizer have no ACLs
+list_acls() {
+  if ! bin/kafka-acls.sh ` + admin + ` --list > /tmp/acls.out 2>&1; then
+    grep -q "No Authorizer is configured" /tmp/acls.out || { cat /tmp/acls.out; return 1; }
+    return 0
+  fi
+  cat /tmp/acls.out
+}
+
+# user entities are read from ZooKeeper, the admin API does not return the salted credentials
+list_users() {
+  users=$(bin/zookeeper-shell.sh "$ZOOKEEPER" ls /config/users 2>/dev/null | grep '^\[' | tr -d '[] ' | tr ',' ' ')
+  for user in $users; do
+    printf '%s\t' "$user"
+    bin/zookeeper-shell.sh "$ZOOKEEPER" get "/config/users/$user" 2>/dev/null | grep '^{' || echo
+  done
+}
+
+dump ` + sectionTopics + ` bin/kafka-topics.sh ` + admin + ` --describe --exclude-internal
+dump ` + sectionTopicConfigs + ` bin/kafka-configs.sh ` + admin + ` --describe --entity-type topics
+dump ` + sectionBrokerConfigs + ` bin/kafka-configs.sh ` + admin + ` --describe --entity-type brokers
+dump ` + sectionACLs + ` list_acls
+dump ` + sectionUsers + ` list_users
+dump ` + sectionConsumerGroups + ` bin/kafka-consumer-groups.sh ` + admin + ` --describe --all-groups --offsets
+`
+}
+
+// ParseDump parses the output of DumpScript, lines not belonging to the dump are ignored
+func ParseDump(cluster, output string) (*Snapshot, error) {
+	found := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		rest, ok := strings.CutPrefix(line, sectionPrefix)
+		if !ok {
+			continue
+		}
+		section, encoded, _ := strings.Cut(rest, ":")
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("section %s of the dump is not base64 encoded: %w", section, err)
+		}
+		found[section] = string(decoded)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		if _, ok := found[section]; !ok {
+			return nil, fmt.Errorf("the dump has no section %s", section)
+		}
+	}
+
+	snapshot := &Snapshot{Cluster: cluster}
+	snapshot.Topics = parseTopics(found[sectionTopics])
+	topicConfigs := parseConfigs(found[sectionTopicConfigs], topicConfigsHeader)
+	for i := range snapshot.Topics {
+		snapshot.Topics[i].Configs = topicConfigs[snapshot.Topics[i].Name]
+	}
+	snapshot.BrokerConfigs = parseConfigs(found[sectionBrokerConfigs], brokerConfigsHeader)
+	snapshot.ACLs = parseACLs(found[sectionACLs])
+	snapshot.Users = parseUsers(found[sectionUsers])
+	snapshot.ConsumerGroups = parseConsumerGroups(found[sectionConsumerGroups])
+	return snapshot, nil
+}
+
+// parseTopics parses `kafka-topics.sh --describe`, e.g.
+// `Topic: orders	TopicId: Hc1...	PartitionCount: 3	ReplicationFactor: 2	Configs: cleanup.policy=compact`
+func parseTopics(output string) []Topic {
+	var topics []Topic
+	for line := range strings.Lines(output) {
+		if !strings.HasPrefix(line, "Topic:") || !strings.Contains(line, "PartitionCount:") {
+			continue
+		}
+		fields := map[string]string{}
+		for field := range strings.SplitSeq(strings.TrimSpace(line), "\t") {
+			key, value, _ := strings.Cut(field, ":")
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		partitions, _ := strconv.ParseInt(fields["PartitionCount"], 10, 32)
+		replicationFactor, _ := strconv.ParseInt(fields["ReplicationFactor"], 10, 32)
+		topics = append(topics, Topic{
+			Name:              fields["Topic"],
+			Partitions:        int32(partitions),
+			ReplicationFactor: int32(replicationFactor),
+		})
+	}
+	slices.SortFunc(topics, func(a, b Topic) int { return strings.Compare(a.Name, b.Name) })
+	return topics
+}
+
+var (
+	topicConfigsHeader  = regexp.MustCompile(`^Dynamic configs for topic (\S+) are:`)
+	brokerConfigsHeader = regexp.MustCompile(`^(?:Dynamic configs for broker (\S+)|Default configs for brokers in the cluster) are:`)
+)
+
+// parseConfigs parses `kafka-configs.sh --describe`, the entity name is the first group of header,
+// DefaultBroker for the header of the cluster-wide defaults. Sensitive values are not returned by the brokers
+// and skipped.
+//
+//	Dynamic configs for topic orders are:
+//	  cleanup.policy=compact sensitive=false synonyms={DYNAMIC_TOPIC_CONFIG:cleanup.policy=compact, ...}
+func parseConfigs(output string, header *regexp.Regexp) map[string]map[string]string {
+	configs := map[string]map[string]string{}
+	var entity *string
+	for line := range strings.Lines(output) {
+		line = strings.TrimRight(line, "\r\n")
+		if match := header.FindStringSubmatch(line); match != nil {
+			name := match[1]
+			entity = &name
+			// brokers without dynamic configs are listed as well, a restore only targets the listed brokers
+			configs[name] = map[string]string{}
+			continue
+		}
+		if entity == nil || !strings.HasPrefix(line, "  ") {
+			continue
+		}
+		entry := strings.TrimSpace(line)
+		sensitive := strings.LastIndex(entry, " sensitive=")
+		if sensitive < 0 {
+			continue
+		}
+		if strings.HasPrefix(entry[sensitive:], " sensitive=true") {
+			continue
+		}
+		key, value, ok := strings.Cut(entry[:sensitive], "=")
+		if !ok {
+			continue
+		}
+		configs[*entity][key] = value
+	}
+	return configs
+}
+
+var aclResourceHeader = regexp.MustCompile("^Current ACLs for resource `ResourcePattern\\((.*)\\)`:")
+
+// parseACLs parses `kafka-acls.sh --list`
+//
+//	Current ACLs for resource `ResourcePattern(resourceType=TOPIC, name=orders, patternType=LITERAL)`:
+//	 	(principal=User:alice, host=*, operation=READ, permissionType=ALLOW)
+func parseACLs(output string) []ACL {
+	var acls []ACL
+	var resource map[string]string
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if match := aclResourceHeader.FindStringSubmatch(line); match != nil {
+			resource = parseFields(match[1])
+			continue
+		}
+		if resource == nil || !strings.HasPrefix(line, "(principal=") {
+			continue
+		}
+		entry := parseFields(strings.TrimSuffix(strings.TrimPrefix(line, "("), ")"))
+		acls = append(acls, ACL{
+			ResourceType:   resource["resourceType"],
+			ResourceName:   resource["name"],
+			PatternType:    resource["patternType"],
+			Principal:      entry["principal"],
+			Host:           entry["host"],
+			Operation:      entry["operation"],
+			PermissionType: entry["permissionType"],
+		})
+	}
+	return acls
+}
+
+// parseFields parses `key=value, key=value`
+func parseFields(s string) map[string]string {
+	fields := map[string]string{}
+	for field := range strings.SplitSeq(s, ", ") {
+		key, value, _ := strings.Cut(field, "=")
+		fields[key] = value
+	}
+	return fields
+}
+
+// parseUsers parses the `<name>\t<ZooKeeper node>` lines printed by the dump
+func parseUsers(output string) []User {
+	var users []User
+	for line := range strings.Lines(output) {
+		name, config, ok := strings.Cut(strings.TrimRight(line, "\r\n"), "\t")
+		if !ok || name == "" || !strings.HasPrefix(config, "{") {
+			continue
+		}
+		users = append(users, User{Name: name, Config: config})
+	}
+	return users
+}
+
+// parseConsumerGroups parses `kafka-consumer-groups.sh --describe --all-groups --offsets`,
+// partitions without committed offset are skipped
+//
+//	GROUP     TOPIC     PARTITION  CURRENT-OFFSET  LOG-END-OFFSET  LAG  CONSUMER-ID  HOST  CLIENT-ID
+//	billing   orders    0          42              50              8    -            -     -
+func parseConsumerGroups(output string) []ConsumerGroup {
+	var groups []ConsumerGroup
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "GROUP" {
+			continue
+		}
+		partition, err := strconv.ParseInt(fields[2], 10, 32)
+		if err != nil {
+			continue
+		}
+		offset, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != fields[0] {
+			groups = append(groups, ConsumerGroup{Name: fields[0]})
+		}
+		group := &groups[len(groups)-1]
+		group.Offsets = append(group.Offsets, PartitionOffset{Topic: fields[1], Partition: int32(partition), Offset: offset})
+	}
+	return groups
+}
@@ -0,0 +1,117 @@
+package backup_test
+
+import (
+	"encoding/base64"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zncdatadev/kafka-operator/internal/backup"
+)
+
+const (
+	topicsOutput = "Topic: orders\tTopicId: Hc1rD5ZrQxS2wYoKmCL6Gg\tPartitionCount: 3\tReplicationFactor: 2\tConfigs: cleanup.policy=compact\n" +
+		"\tTopic: orders\tPartition: 0\tLeader: 1\tReplicas: 1,2\tIsr: 1,2\n" +
+		"Topic: audit\tTopicId: 8mVb1dXjQbe5q4yHqQ8p1A\tPartitionCount: 1\tReplicationFactor: 1\tConfigs: \n"
+
+	topicConfigsOutput = `Dynamic configs for topic audit are:
+Dynamic configs for topic orders are:
+  cleanup.policy=compact sensitive=false synonyms={DYNAMIC_TOPIC_CONFIG:cleanup.policy=compact, DEFAULT_CONFIG:log.cleanup.policy=delete}
+  follower.replication.throttled.replicas=0:1,1:2 sensitive=false synonyms={DYNAMIC_TOPIC_CONFIG:follower.replication.throttled.replicas=0:1,1:2}
+`
+
+	brokerConfigsOutput = `Dynamic configs for broker 1 are:
+  log.cleaner.threads=2 sensitive=false synonyms={DYNAMIC_BROKER_CONFIG:log.cleaner.threads=2, DEFAULT_CONFIG:log.cleaner.threads=1}
+  listener.name.internal.ssl.key.password=null sensitive=true synonyms={DYNAMIC_BROKER_CONFIG:listener.name.internal.ssl.key.password=null}
+Dynamic configs for broker 2 are:
+Default configs for brokers in the cluster are:
+  max.connections=1000 sensitive=false synonyms={DYNAMIC_DEFAULT_BROKER_CONFIG:max.connections=1000}
+`
+
+	aclsOutput = "Current ACLs for resource `ResourcePattern(resourceType=TOPIC, name=orders, patternType=LITERAL)`: \n" +
+		" \t(principal=User:alice, host=*, operation=READ, permissionType=ALLOW)\n" +
+		" \t(principal=User:bob, host=10.0.0.1, operation=WRITE, permissionType=DENY) \n" +
+		"\n" +
+		"Current ACLs for resource `ResourcePattern(resourceType=CLUSTER, name=kafka-cluster, patternType=LITERAL)`: \n" +
+		" \t(principal=User:admin, host=*, operation=ALL, permissionType=ALLOW)\n"
+
+	usersOutput = "alice\t" + `{"version":1,"config":{"SCRAM-SHA-512":"salt=c2FsdA==,stored_key=c3RvcmVk,server_key=c2VydmVy,iterations=4096"}}` + "\n" +
+		"ghost\t\n"
+
+	consumerGroupsOutput = `
+Consumer group 'idle' has no active members.
+
+GROUP           TOPIC           PARTITION  CURRENT-OFFSET  LOG-END-OFFSET  LAG             CONSUMER-ID     HOST            CLIENT-ID
+billing         orders          0          42              50              8               -               -               -
+billing         orders          1          7               7               0               -               -               -
+billing         orders          2          -               3               -               -               -               -
+`
+)
+
+// dumpLog returns the log of a dump pod printing the outputs as sections, mixed with other lines
+func dumpLog(outputs map[string]string) string {
+	var log strings.Builder
+	log.WriteString("[2024-01-01 00:00:00,000] WARN some tool warning\n")
+	for _, section := range []string{"topics", "topic-configs", "broker-configs", "acls", "users", "consumer-groups"} {
+		log.WriteString("kafka-backup:" + section + ":" + base64.StdEncoding.EncodeToString([]byte(outputs[section])) + "\n")
+	}
+	return log.String()
+}
+
+var _ = Describe("ParseDump", func() {
+	It("should parse the outputs of the Kafka tools", func() {
+		// given
+		log := dumpLog(map[string]string{
+			"topics":          topicsOutput,
+			"topic-configs":   topicConfigsOutput,
+			"broker-configs":  brokerConfigsOutput,
+			"acls":            aclsOutput,
+			"users":           usersOutput,
+			"consumer-groups": consumerGroupsOutput,
+		})
+
+		// when
+		snapshot, err := backup.ParseDump("events", log)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Cluster).To(Equal("events"))
+		Expect(snapshot.Topics).To(Equal([]backup.Topic{
+			{Name: "audit", Partitions: 1, ReplicationFactor: 1, Configs: map[string]string{}},
+			{Name: "orders", Partitions: 3, ReplicationFactor: 2, Configs: map[string]string{
+				"cleanup.policy": "compact",
+				"follower.replication.throttled.replicas": "0:1,1:2",
+			}},
+		}))
+		Expect(snapshot.BrokerConfigs).To(Equal(map[string]map[string]string{
+			"1":                  {"log.cleaner.threads": "2"},
+			"2":                  {},
+			backup.DefaultBroker: {"max.connections": "1000"},
+		}))
+		Expect(snapshot.ACLs).To(Equal([]backup.ACL{
+			{ResourceType: "TOPIC", ResourceName: "orders", PatternType: "LITERAL", Principal: "User:alice", Host: "*", Operation: "READ", PermissionType: "ALLOW"},
+			{ResourceType: "TOPIC", ResourceName: "orders", PatternType: "LITERAL", Principal: "User:bob", Host: "10.0.0.1", Operation: "WRITE", PermissionType: "DENY"},
+			{ResourceType: "CLUSTER", ResourceName: "kafka-cluster", PatternType: "LITERAL", Principal: "User:admin", Host: "*", Operation: "ALL", PermissionType: "ALLOW"},
+		}))
+		Expect(snapshot.Users).To(Equal([]backup.User{{
+			Name:   "alice",
+			Config: `{"version":1,"config":{"SCRAM-SHA-512":"salt=c2FsdA==,stored_key=c3RvcmVk,server_key=c2VydmVy,iterations=4096"}}`,
+		}}))
+		Expect(snapshot.ConsumerGroups).To(Equal([]backup.ConsumerGroup{{
+			Name: "billing",
+			Offsets: []backup.PartitionOffset{
+				{Topic: "orders", Partition: 0, Offset: 42},
+				{Topic: "orders", Partition: 1, Offset: 7},
+			},
+		}}))
+	})
+
+	It("should fail if a section is missing", func() {
+		// when
+		_, err := backup.ParseDump("events", "kafka-backup:topics:\n")
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("no section topic-configs")))
+	})
+})
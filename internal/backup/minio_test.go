@@ -0,0 +1,188 @@
+package backup_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minioServer is a local stand-in of a MinIO server, keeping the objects of a single bucket in memory.
+// Like MinIO, it verifies the Signature Version 4 of every request against its credentials.
+type minioServer struct {
+	mu        sync.Mutex
+	server    *httptest.Server
+	bucket    string
+	accessKey string
+	secretKey string
+	objects   map[string][]byte
+}
+
+func newMinio(bucket, accessKey, secretKey string) *minioServer {
+	m := &minioServer{bucket: bucket, accessKey: accessKey, secretKey: secretKey, objects: map[string][]byte{}}
+	m.server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+func (m *minioServer) Close() {
+	m.server.Close()
+}
+
+func (m *minioServer) URL() string {
+	return m.server.URL
+}
+
+// Objects returns the keys of the objects in the bucket
+func (m *minioServer) Objects() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.objects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (m *minioServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	body, err := m.verify(r, body)
+	if err != nil {
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != m.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		m.objects[key] = body
+	case http.MethodGet:
+		object, ok := m.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		// the MinIO client requires the metadata of the object
+		w.Header().Set("Last-Modified", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"`+sha256Hex(object)[:32]+`"`)
+		_, _ = w.Write(object)
+	case http.MethodDelete:
+		delete(m.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// verify recomputes the signature of the request and returns its payload. Like MinIO, payloads sent with
+// `STREAMING-AWS4-HMAC-SHA256-PAYLOAD` are decoded from their chunks, verifying the signature of every chunk.
+func (m *minioServer) verify(r *http.Request, body []byte) ([]byte, error) {
+	var credential, signedHeaders, signature string
+	for part := range strings.SplitSeq(strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 "), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+	accessKey, scope, _ := strings.Cut(credential, "/")
+	if accessKey != m.accessKey {
+		return nil, fmt.Errorf("unknown access key %q", accessKey)
+	}
+	contentHash := r.Header.Get("X-Amz-Content-Sha256")
+	streaming := contentHash == streamingPayload
+	if !streaming && contentHash != sha256Hex(body) {
+		return nil, fmt.Errorf("payload hash does not match")
+	}
+
+	var canonicalHeaders strings.Builder
+	for name := range strings.SplitSeq(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+	}
+	canonicalRequest := strings.Join([]string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		canonicalHeaders.String(), signedHeaders, contentHash}, "\n")
+	amzDate := r.Header.Get("X-Amz-Date")
+	key := []byte("AWS4" + m.secretKey)
+	for part := range strings.SplitSeq(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	expected := hex.EncodeToString(hmacSHA256(key,
+		"AWS4-HMAC-SHA256\n"+amzDate+"\n"+scope+"\n"+sha256Hex([]byte(canonicalRequest))))
+	if signature != expected {
+		return nil, fmt.Errorf("signature %s does not match %s", signature, expected)
+	}
+	if !streaming {
+		return body, nil
+	}
+
+	// every chunk is `<hex size>;chunk-signature=<signature>\r\n<data>\r\n`, the last one is empty
+	var payload []byte
+	previous := signature
+	for {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		sizeHex, chunkSignature, _ := strings.Cut(string(header), ";chunk-signature=")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if !ok || err != nil || int64(len(rest)) < size+2 {
+			return nil, fmt.Errorf("malformed chunk %q", header)
+		}
+		chunk := rest[:size]
+		expected := hex.EncodeToString(hmacSHA256(key, strings.Join([]string{"AWS4-HMAC-SHA256-PAYLOAD",
+			amzDate, scope, previous, sha256Hex(nil), sha256Hex(chunk)}, "\n")))
+		if chunkSignature != expected {
+			return nil, fmt.Errorf("chunk signature %s does not match %s", chunkSignature, expected)
+		}
+		if size == 0 {
+			break
+		}
+		payload = append(payload, chunk...)
+		previous = chunkSignature
+		body = rest[size+2:]
+	}
+	if decoded := r.Header.Get("X-Amz-Decoded-Content-Length"); decoded != strconv.Itoa(len(payload)) {
+		return nil, fmt.Errorf("decoded content length %s does not match %d", decoded, len(payload))
+	}
+	return payload, nil
+}
+
+const streamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
@@ -0,0 +1,262 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ConflictPolicy decides what a restore does with entities existing in the cluster with a different definition
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip keeps the entities of the cluster
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// ConflictPolicyOverwrite replaces the entities of the cluster with the ones of the snapshot
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+	// ConflictPolicyFail fails the restore before changing anything
+	ConflictPolicyFail ConflictPolicy = "Fail"
+)
+
+var ErrConflict = errors.New("the snapshot conflicts with the cluster")
+
+// RestorePlan is the script replaying a snapshot into a cluster
+type RestorePlan struct {
+	// Script is run in the Kafka image, with the same environment as DumpScript
+	Script string
+	// Applied and Skipped are the entities restored and kept, e.g. `topic orders`
+	Applied []string
+	Skipped []string
+}
+
+// Plan returns the restore of snapshot into the cluster with the metadata current.
+// ACLs are only added, the ACLs of the cluster missing in the snapshot are kept.
+// Partitions of existing topics are only increased, the replication factor is not changed.
+func Plan(snapshot, current *Snapshot, policy ConflictPolicy, commandConfig string) (*RestorePlan, error) {
+	p := &planner{plan: &RestorePlan{}, policy: policy, commandConfig: commandConfig}
+
+	for _, topic := range snapshot.Topics {
+		p.topic(topic, current.topic(topic.Name))
+	}
+	for _, broker := range slices.Sorted(maps.Keys(snapshot.BrokerConfigs)) {
+		existing, ok := current.BrokerConfigs[broker]
+		if !ok {
+			p.plan.Skipped = append(p.plan.Skipped, brokerEntity(broker)+" (not in the cluster)")
+			continue
+		}
+		p.brokerConfigs(broker, snapshot.BrokerConfigs[broker], existing)
+	}
+	for _, acl := range snapshot.ACLs {
+		if !slices.Contains(current.ACLs, acl) {
+			p.acl(acl)
+		}
+	}
+	for _, user := range snapshot.Users {
+		p.user(user, current.user(user.Name))
+	}
+	for _, group := range snapshot.ConsumerGroups {
+		p.consumerGroup(group, current.consumerGroup(group.Name))
+	}
+
+	if len(p.conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrConflict, strings.Join(p.conflicts, ", "))
+	}
+	p.plan.Script = p.header() + p.script.String()
+	return p.plan, nil
+}
+
+type planner struct {
+	plan          *RestorePlan
+	policy        ConflictPolicy
+	commandConfig string
+
+	script    strings.Builder
+	conflicts []string
+	files     int
+}
+
+// conflict records an entity differing from the snapshot, it returns whether the entity is restored anyway
+func (p *planner) conflict(entity string) bool {
+	switch p.policy {
+	case ConflictPolicyOverwrite:
+		return true
+	case ConflictPolicyFail:
+		p.conflicts = append(p.conflicts, entity)
+	default:
+		p.plan.Skipped = append(p.plan.Skipped, entity)
+	}
+	return false
+}
+
+func (p *planner) step(entity string, commands ...string) {
+	p.plan.Applied = append(p.plan.Applied, entity)
+	fmt.Fprintf(&p.script, "\necho %s\n", quote("restoring "+entity))
+	for _, command := range commands {
+		p.script.WriteString(command + "\n")
+	}
+}
+
+// propertiesFile returns the command writing properties to a new file and the path of the file,
+// config values may contain commas, which are not allowed in `--add-config`
+func (p *planner) propertiesFile(properties map[string]string) (string, string) {
+	p.files++
+	path := fmt.Sprintf("/tmp/restore-%d.properties", p.files)
+	var content strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		fmt.Fprintf(&content, "%s=%s\n", key, properties[key])
+	}
+	return fmt.Sprintf("cat > %s <<'EOF'\n%sEOF", path, content.String()), path
+}
+
+func (p *planner) topic(topic Topic, existing *Topic) {
+	entity := "topic " + topic.Name
+	if existing == nil {
+		command := fmt.Sprintf("topics --create --topic %s --partitions %d --replication-factor %d",
+			quote(topic.Name), topic.Partitions, topic.ReplicationFactor)
+		for _, key := range slices.Sorted(maps.Keys(topic.Configs)) {
+			command += " --config " + quote(key+"="+topic.Configs[key])
+		}
+		p.step(entity, command)
+		return
+	}
+
+	configsChanged := !maps.Equal(topic.Configs, existing.Configs)
+	morePartitions := topic.Partitions > existing.Partitions
+	if !configsChanged && !morePartitions || !p.conflict(entity) {
+		return
+	}
+	var commands []string
+	if configsChanged {
+		commands = append(commands, p.alterConfigs("--entity-type topics --entity-name "+quote(topic.Name), topic.Configs, existing.Configs)...)
+	}
+	if morePartitions {
+		commands = append(commands, fmt.Sprintf("topics --alter --topic %s --partitions %d", quote(topic.Name), topic.Partitions))
+	}
+	p.step(entity, commands...)
+}
+
+// alterConfigs returns the commands replacing the configs existing of an entity with configs
+func (p *planner) alterConfigs(entity string, configs, existing map[string]string) []string {
+	var commands []string
+	if len(configs) > 0 {
+		write, path := p.propertiesFile(configs)
+		commands = append(commands, write, fmt.Sprintf("configs --alter %s --add-config-file %s", entity, path))
+	}
+	var removed []string
+	for _, key := range slices.Sorted(maps.Keys(existing)) {
+		if _, ok := configs[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	if len(removed) > 0 {
+		commands = append(commands, fmt.Sprintf("configs --alter %s --delete-config %s", entity, quote(strings.Join(removed, ","))))
+	}
+	return commands
+}
+
+func brokerEntity(broker string) string {
+	if broker == DefaultBroker {
+		return "default broker configs"
+	}
+	return "configs of broker " + broker
+}
+
+// brokerConfigs restores the configs of the snapshot, other dynamic configs of the broker are kept
+func (p *planner) brokerConfigs(broker string, configs, existing map[string]string) {
+	entity := brokerEntity(broker)
+	changes := map[string]string{}
+	conflicting := false
+	for key, value := range configs {
+		current, ok := existing[key]
+		if !ok || current != value {
+			changes[key] = value
+		}
+		conflicting = conflicting || ok && current != value
+	}
+	if len(changes) == 0 || conflicting && !p.conflict(entity) {
+		return
+	}
+	args := "--entity-type brokers --entity-default"
+	if broker != DefaultBroker {
+		args = "--entity-type brokers --entity-name " + quote(broker)
+	}
+	write, path := p.propertiesFile(changes)
+	p.step(entity, write, fmt.Sprintf("configs --alter %s --add-config-file %s", args, path))
+}
+
+// aclResourceOptions maps the resource types of kafka-acls.sh --list to the options of --add
+var aclResourceOptions = map[string]string{
+	"TOPIC":            "--topic",
+	"GROUP":            "--group",
+	"TRANSACTIONAL_ID": "--transactional-id",
+	"DELEGATION_TOKEN": "--delegation-token",
+	"USER":             "--user-principal",
+}
+
+func (p *planner) acl(acl ACL) {
+	entity := fmt.Sprintf("acl %s %s %s on %s %s", acl.PermissionType, acl.Operation, acl.Principal, acl.ResourceType, acl.ResourceName)
+	permission := "allow"
+	if acl.PermissionType == "DENY" {
+		permission = "deny"
+	}
+	resource := "--cluster"
+	if option, ok := aclResourceOptions[acl.ResourceType]; ok {
+		resource = option + " " + quote(acl.ResourceName)
+	}
+	p.step(entity, fmt.Sprintf("acls --add --%s-principal %s --%s-host %s --operation %s %s --resource-pattern-type %s",
+		permission, quote(acl.Principal), permission, quote(acl.Host), acl.Operation, resource, acl.PatternType))
+}
+
+// user writes the user entity to ZooKeeper and notifies the brokers the way `kafka-configs.sh` does
+func (p *planner) user(user User, existing *User) {
+	entity := "user " + user.Name
+	node := "/config/users/" + user.Name
+	change := fmt.Sprintf(`{"version":2,"entity_path":"users/%s"}`, user.Name)
+	notify := "zk create -s /config/changes/config_change_ " + quote(change)
+	if existing == nil {
+		p.step(entity, "zk create /config/users '' > /dev/null 2>&1 || true", "zk create "+node+" "+quote(user.Config), notify)
+		return
+	}
+	if existing.Config == user.Config || !p.conflict(entity) {
+		return
+	}
+	p.step(entity, "zk set "+node+" "+quote(user.Config), notify)
+}
+
+// consumerGroup resets the offsets of the group, the group must not have active members
+func (p *planner) consumerGroup(group ConsumerGroup, existing *ConsumerGroup) {
+	entity := "consumer group " + group.Name
+	if existing != nil {
+		if slices.Equal(group.Offsets, existing.Offsets) || !p.conflict(entity) {
+			return
+		}
+	}
+	p.files++
+	path := fmt.Sprintf("/tmp/restore-%d.csv", p.files)
+	var offsets strings.Builder
+	for _, offset := range group.Offsets {
+		fmt.Fprintf(&offsets, "%s,%d,%d\n", offset.Topic, offset.Partition, offset.Offset)
+	}
+	p.step(entity,
+		fmt.Sprintf("cat > %s <<'EOF'\n%sEOF", path, offsets.String()),
+		fmt.Sprintf("groups --reset-offsets --group %s --from-file %s --execute > /dev/null", quote(group.Name), path))
+}
+
+func (p *planner) header() string {
+	admin := fmt.Sprintf(`--bootstrap-server "$KAFKA" --command-config %s`, p.commandConfig)
+	return `set -eu
+
+topics() { bin/kafka-topics.sh ` + admin + ` "$@"; }
+configs() { bin/kafka-configs.sh ` + admin + ` "$@"; }
+acls() { bin/kafka-acls.sh ` + admin + ` "$@"; }
+groups() { bin/kafka-consumer-groups.sh ` + admin + ` "$@"; }
+zk() { bin/zookeeper-shell.sh "$ZOOKEEPER" "$@"; }
+`
+}
+
+// quote returns s as a single quoted shell word
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,114 @@
+package backup_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zncdatadev/kafka-operator/internal/backup"
+)
+
+const commandConfig = "/kubedoop/backup/client.properties"
+
+func snapshotFixture() *backup.Snapshot {
+	return &backup.Snapshot{
+		Cluster: "events",
+		Topics: []backup.Topic{
+			{Name: "orders", Partitions: 3, ReplicationFactor: 2, Configs: map[string]string{"cleanup.policy": "compact"}},
+		},
+		BrokerConfigs: map[string]map[string]string{
+			backup.DefaultBroker: {"max.connections": "1000"},
+			"7":                  {"log.cleaner.threads": "2"},
+		},
+		ACLs: []backup.ACL{
+			{ResourceType: "TOPIC", ResourceName: "orders", PatternType: "LITERAL", Principal: "User:alice", Host: "*", Operation: "READ", PermissionType: "ALLOW"},
+		},
+		Users: []backup.User{{Name: "alice", Config: `{"version":1,"config":{"SCRAM-SHA-512":"salt=c2FsdA=="}}`}},
+		ConsumerGroups: []backup.ConsumerGroup{
+			{Name: "billing", Offsets: []backup.PartitionOffset{{Topic: "orders", Partition: 0, Offset: 42}}},
+		},
+	}
+}
+
+var _ = Describe("Plan", func() {
+	It("should restore everything into an empty cluster", func() {
+		// given
+		current := &backup.Snapshot{BrokerConfigs: map[string]map[string]string{backup.DefaultBroker: {}}}
+
+		// when
+		plan, err := backup.Plan(snapshotFixture(), current, backup.ConflictPolicySkip, commandConfig)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Applied).To(Equal([]string{
+			"topic orders",
+			"default broker configs",
+			"acl ALLOW READ User:alice on TOPIC orders",
+			"user alice",
+			"consumer group billing",
+		}))
+		Expect(plan.Skipped).To(Equal([]string{"configs of broker 7 (not in the cluster)"}))
+		Expect(plan.Script).To(ContainSubstring(`--bootstrap-server "$KAFKA" --command-config ` + commandConfig))
+		Expect(plan.Script).To(ContainSubstring("topics --create --topic 'orders' --partitions 3 --replication-factor 2 --config 'cleanup.policy=compact'"))
+		Expect(plan.Script).To(ContainSubstring("max.connections=1000\nEOF\nconfigs --alter --entity-type brokers --entity-default --add-config-file /tmp/restore-1.properties"))
+		Expect(plan.Script).To(ContainSubstring("acls --add --allow-principal 'User:alice' --allow-host '*' --operation READ --topic 'orders' --resource-pattern-type LITERAL"))
+		Expect(plan.Script).To(ContainSubstring(`zk create /config/users/alice '{"version":1,"config":{"SCRAM-SHA-512":"salt=c2FsdA=="}}'`))
+		Expect(plan.Script).To(ContainSubstring(`zk create -s /config/changes/config_change_ '{"version":2,"entity_path":"users/alice"}'`))
+		Expect(plan.Script).To(ContainSubstring("orders,0,42\nEOF\ngroups --reset-offsets --group 'billing' --from-file /tmp/restore-2.csv --execute"))
+	})
+
+	It("should not restore what the cluster already has", func() {
+		// given
+		current := snapshotFixture()
+
+		// when
+		plan, err := backup.Plan(snapshotFixture(), current, backup.ConflictPolicyFail, commandConfig)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Applied).To(BeEmpty())
+	})
+
+	Context("with conflicting entities", func() {
+		var current *backup.Snapshot
+
+		BeforeEach(func() {
+			current = snapshotFixture()
+			current.Topics[0].Partitions = 1
+			current.Topics[0].Configs = map[string]string{"retention.ms": "1000"}
+			current.Users[0].Config = `{"version":1,"config":{}}`
+			current.ConsumerGroups[0].Offsets[0].Offset = 99
+		})
+
+		It("should keep them with the Skip policy", func() {
+			// when
+			plan, err := backup.Plan(snapshotFixture(), current, backup.ConflictPolicySkip, commandConfig)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.Applied).To(BeEmpty())
+			Expect(plan.Skipped).To(Equal([]string{"topic orders", "user alice", "consumer group billing"}))
+		})
+
+		It("should replace them with the Overwrite policy", func() {
+			// when
+			plan, err := backup.Plan(snapshotFixture(), current, backup.ConflictPolicyOverwrite, commandConfig)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.Applied).To(Equal([]string{"topic orders", "user alice", "consumer group billing"}))
+			Expect(plan.Script).To(ContainSubstring("configs --alter --entity-type topics --entity-name 'orders' --add-config-file /tmp/restore-1.properties"))
+			Expect(plan.Script).To(ContainSubstring("configs --alter --entity-type topics --entity-name 'orders' --delete-config 'retention.ms'"))
+			Expect(plan.Script).To(ContainSubstring("topics --alter --topic 'orders' --partitions 3"))
+			Expect(plan.Script).To(ContainSubstring("zk set /config/users/alice"))
+		})
+
+		It("should fail with the Fail policy", func() {
+			// when
+			_, err := backup.Plan(snapshotFixture(), current, backup.ConflictPolicyFail, commandConfig)
+
+			// then
+			Expect(err).To(MatchError(backup.ErrConflict))
+			Expect(err).To(MatchError(ContainSubstring("topic orders, user alice, consumer group billing")))
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const DefaultS3Region = "us-east-1"
+
+// S3Store keeps every snapshot in an object `<prefix><name>.json` of an S3 compatible bucket, e.g. MinIO.
+// Buckets are addressed path style and requests signed with AWS Signature Version 4 by the MinIO client.
+type S3Store struct {
+	// Endpoint is the URL of the S3 API, e.g. `http://minio:9000`
+	Endpoint  string
+	Bucket    string
+	Prefix    string
+	Region    string
+	AccessKey string
+	SecretKey string
+
+	// Transport is used for the requests to the S3 API, defaults to the transport of the MinIO client
+	Transport http.RoundTripper
+}
+
+var _ Store = &S3Store{}
+
+func (s *S3Store) Put(ctx context.Context, name string, data []byte) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+	_, err = client.PutObject(ctx, s.Bucket, s.objectKey(name), bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: "application/json"})
+	return err
+}
+
+func (s *S3Store) Get(ctx context.Context, name string) ([]byte, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	object, err := client.GetObject(ctx, s.Bucket, s.objectKey(name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = object.Close() }()
+	// the request is only sent on the first read
+	data, err := io.ReadAll(object)
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil, fmt.Errorf("%w: %s/%s", ErrSnapshotNotFound, s.Bucket, s.objectKey(name))
+	}
+	return data, err
+}
+
+// Delete removes the object of a snapshot, S3 does not report missing objects
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+	return client.RemoveObject(ctx, s.Bucket, s.objectKey(name), minio.RemoveObjectOptions{})
+}
+
+func (s *S3Store) objectKey(name string) string {
+	return s.Prefix + name + ".json"
+}
+
+func (s *S3Store) client() (*minio.Client, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", s.Endpoint, err)
+	}
+	if endpoint.Host == "" || endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: expected http(s)://<host>[:<port>]", s.Endpoint)
+	}
+	region := s.Region
+	if region == "" {
+		region = DefaultS3Region
+	}
+	return minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(s.AccessKey, s.SecretKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+		Transport:    s.Transport,
+	})
+}
@@ -0,0 +1,78 @@
+package backup_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/zncdatadev/kafka-operator/internal/backup"
+)
+
+var _ = Describe("S3Store", func() {
+	var (
+		server *minioServer
+		store  *backup.S3Store
+		ctx    = context.Background()
+	)
+
+	BeforeEach(func() {
+		server = newMinio("kafka-backups", "minioadmin", "minio-secret")
+		DeferCleanup(server.Close)
+		store = &backup.S3Store{
+			Endpoint:  server.URL(),
+			Bucket:    "kafka-backups",
+			Prefix:    "prod/",
+			AccessKey: "minioadmin",
+			SecretKey: "minio-secret",
+		}
+	})
+
+	It("should store and read a snapshot", func() {
+		// when
+		err := store.Put(ctx, "events-20240101-000000", []byte(`{"cluster":"events"}`))
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Objects()).To(Equal([]string{"prod/events-20240101-000000.json"}))
+		data, err := store.Get(ctx, "events-20240101-000000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"cluster":"events"}`))
+	})
+
+	It("should report missing snapshots", func() {
+		// when
+		_, err := store.Get(ctx, "missing")
+
+		// then
+		Expect(err).To(MatchError(backup.ErrSnapshotNotFound))
+	})
+
+	It("should delete a snapshot", func() {
+		// given
+		Expect(store.Put(ctx, "old", []byte("{}"))).To(Succeed())
+
+		// when
+		err := store.Delete(ctx, "old")
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Objects()).To(BeEmpty())
+		Expect(store.Delete(ctx, "old")).To(Succeed())
+	})
+
+	It("should fail with wrong credentials", func() {
+		// given
+		store.SecretKey = "wrong"
+
+		// when
+		err := store.Put(ctx, "events", []byte("{}"))
+
+		// then
+		Expect(err).To(HaveOccurred())
+		Expect(minio.ToErrorResponse(err).Code).To(Equal("SignatureDoesNotMatch"))
+		Expect(server.Objects()).To(BeEmpty())
+	})
+})
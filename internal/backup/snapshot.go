@@ -0,0 +1,106 @@
+// Package backup snapshots the metadata of a Kafka cluster - topics, dynamic configs, ACLs, SCRAM users and
+// consumer group offsets - and replays it into a cluster.
+//
+// The operator does not talk to the brokers itself: a Job runs the Kafka command line tools and prints their
+// output (see DumpScript), which is parsed into a Snapshot and stored in a ConfigMap or an S3 bucket.
+// A restore dumps the target cluster the same way and runs the script computed by Plan.
+package backup
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Snapshot is the metadata of a Kafka cluster at a point in time
+type Snapshot struct {
+	Cluster   string    `json:"cluster"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	Topics []Topic `json:"topics,omitempty"`
+	// BrokerConfigs are the dynamic broker configs by broker ID, the key DefaultBroker holds the cluster-wide defaults
+	BrokerConfigs map[string]map[string]string `json:"brokerConfigs,omitempty"`
+	ACLs          []ACL                        `json:"acls,omitempty"`
+	// Users are the user entities stored in ZooKeeper, with their SCRAM credentials and quotas
+	Users          []User          `json:"users,omitempty"`
+	ConsumerGroups []ConsumerGroup `json:"consumerGroups,omitempty"`
+}
+
+// DefaultBroker is the key of the cluster-wide dynamic broker configs
+const DefaultBroker = ""
+
+type Topic struct {
+	Name              string `json:"name"`
+	Partitions        int32  `json:"partitions"`
+	ReplicationFactor int32  `json:"replicationFactor"`
+	// Configs are the dynamic topic configs
+	Configs map[string]string `json:"configs,omitempty"`
+}
+
+// ACL is an access control entry bound to a resource pattern, in the notation of kafka-acls.sh
+type ACL struct {
+	ResourceType   string `json:"resourceType"`
+	ResourceName   string `json:"resourceName"`
+	PatternType    string `json:"patternType"`
+	Principal      string `json:"principal"`
+	Host           string `json:"host"`
+	Operation      string `json:"operation"`
+	PermissionType string `json:"permissionType"`
+}
+
+// User is a user entity, Config is its ZooKeeper node holding the salted SCRAM credentials
+type User struct {
+	Name   string `json:"name"`
+	Config string `json:"config"`
+}
+
+type ConsumerGroup struct {
+	Name    string            `json:"name"`
+	Offsets []PartitionOffset `json:"offsets"`
+}
+
+type PartitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// Marshal encodes the snapshot as stored in the backup target
+func (s *Snapshot) Marshal() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Unmarshal decodes a snapshot read from a backup target
+func Unmarshal(data []byte) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (s *Snapshot) topic(name string) *Topic {
+	for i := range s.Topics {
+		if s.Topics[i].Name == name {
+			return &s.Topics[i]
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) user(name string) *User {
+	for i := range s.Users {
+		if s.Users[i].Name == name {
+			return &s.Users[i]
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) consumerGroup(name string) *ConsumerGroup {
+	for i := range s.ConsumerGroups {
+		if s.ConsumerGroups[i].Name == name {
+			return &s.ConsumerGroups[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// Store keeps the snapshots of a backup target by name
+type Store interface {
+	Put(ctx context.Context, name string, data []byte) error
+	// Get returns ErrSnapshotNotFound if there is no snapshot with the name
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Delete does nothing if there is no snapshot with the name
+	Delete(ctx context.Context, name string) error
+}
+
+// ConfigMapSnapshotKey is the key of the snapshot in the ConfigMaps of a ConfigMapStore
+const ConfigMapSnapshotKey = "snapshot.json"
+
+// ConfigMapStore keeps every snapshot in a ConfigMap named after it, limiting snapshots to 1MiB.
+// The ConfigMaps are not owned by the backup, so they survive its deletion.
+type ConfigMapStore struct {
+	Client    ctrlclient.Client
+	Namespace string
+	Labels    map[string]string
+}
+
+var _ Store = &ConfigMapStore{}
+
+func (s *ConfigMapStore) Put(ctx context.Context, name string, data []byte) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.Namespace, Labels: s.Labels},
+		Data:       map[string]string{ConfigMapSnapshotKey: string(data)},
+	}
+	err := s.Client.Create(ctx, cm)
+	if apierrors.IsAlreadyExists(err) {
+		existing := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, ctrlclient.ObjectKeyFromObject(cm), existing); err != nil {
+			return err
+		}
+		existing.Data = cm.Data
+		return s.Client.Update(ctx, existing)
+	}
+	return err
+}
+
+func (s *ConfigMapStore) Get(ctx context.Context, name string) ([]byte, error) {
+	cm := &corev1.ConfigMap{}
+	if err := s.Client.Get(ctx, ctrlclient.ObjectKey{Namespace: s.Namespace, Name: name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: ConfigMap %s/%s", ErrSnapshotNotFound, s.Namespace, name)
+		}
+		return nil, err
+	}
+	data, ok := cm.Data[ConfigMapSnapshotKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: ConfigMap %s/%s has no key %s", ErrSnapshotNotFound, s.Namespace, name, ConfigMapSnapshotKey)
+	}
+	return []byte(data), nil
+}
+
+func (s *ConfigMapStore) Delete(ctx context.Context, name string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.Namespace}}
+	return ctrlclient.IgnoreNotFound(s.Client.Delete(ctx, cm))
+}
@@ -0,0 +1,93 @@
+// Package catalog declares the Kafka releases supported by the operator and the configuration each of them accepts.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported kafka version")
+
+// KafkaVersion is a supported Kafka release line, every patch release of it is supported
+type KafkaVersion struct {
+	// Line is the major and minor version, e.g. `3.9`
+	Line string
+	// Zookeeper is false for the releases only running in KRaft mode
+	Zookeeper bool
+	// LogType is the logging backend, the operator only generates the logging configuration for Log4j
+	LogType productlogging.LogType
+	// RemovedProperties are rejected by the release, they are dropped from server.properties
+	RemovedProperties []string
+	// RenamedProperties maps former property names to the ones of the release
+	RenamedProperties map[string]string
+	// DeprecatedProperties still work, but are removed in a later release
+	DeprecatedProperties []string
+}
+
+// removed in Kafka 4.0, deprecated in the 3.x releases before
+var deprecatedIn3 = []string{
+	"log.message.format.version",
+	"log.message.timestamp.difference.max.ms",
+	"delegation.token.master.key",
+}
+
+var kafkaVersions = []KafkaVersion{
+	{Line: "3.7", Zookeeper: true, LogType: productlogging.LogTypeLog4j, DeprecatedProperties: deprecatedIn3},
+	{Line: "3.8", Zookeeper: true, LogType: productlogging.LogTypeLog4j, DeprecatedProperties: deprecatedIn3},
+	{Line: "3.9", Zookeeper: true, LogType: productlogging.LogTypeLog4j, DeprecatedProperties: deprecatedIn3},
+}
+
+// SupportedLines returns the supported release lines in ascending order
+func SupportedLines() []string {
+	lines := make([]string, 0, len(kafkaVersions))
+	for _, v := range kafkaVersions {
+		lines = append(lines, v.Line)
+	}
+	return lines
+}
+
+// Lookup returns the release line of productVersion, e.g. `3.9.0`
+func Lookup(productVersion string) (*KafkaVersion, error) {
+	v, err := version.ParseGeneric(productVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a version", ErrUnsupportedVersion, productVersion)
+	}
+	line := fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+	for i := range kafkaVersions {
+		if kafkaVersions[i].Line == line {
+			return &kafkaVersions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s, supported versions are %s", ErrUnsupportedVersion, productVersion, strings.Join(SupportedLines(), ", "))
+}
+
+// AdaptServerProperties renames and drops the properties of server.properties the release does not accept.
+// It returns a warning for every renamed, removed or deprecated property.
+func (v *KafkaVersion) AdaptServerProperties(properties map[string]string) []string {
+	var warnings []string
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		if newKey, ok := v.RenamedProperties[key]; ok {
+			if _, exists := properties[newKey]; !exists {
+				properties[newKey] = properties[key]
+			}
+			delete(properties, key)
+			warnings = append(warnings, fmt.Sprintf("%s is renamed to %s in Kafka %s", key, newKey, v.Line))
+			continue
+		}
+		if slices.Contains(v.RemovedProperties, key) {
+			delete(properties, key)
+			warnings = append(warnings, fmt.Sprintf("%s is removed in Kafka %s and ignored", key, v.Line))
+			continue
+		}
+		if slices.Contains(v.DeprecatedProperties, key) {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated in Kafka %s", key, v.Line))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,69 @@
+package catalog_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
+
+	"github.com/zncdatadev/kafka-operator/internal/catalog"
+)
+
+var _ = Describe("Lookup", func() {
+	It("matches every patch release of a supported line", func() {
+		v, err := catalog.Lookup("3.9.1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.Line).To(Equal("3.9"))
+		Expect(v.Zookeeper).To(BeTrue())
+		Expect(v.LogType).To(Equal(productlogging.LogTypeLog4j))
+	})
+
+	It("rejects unknown and invalid versions", func() {
+		_, err := catalog.Lookup("2.8.1")
+		Expect(err).To(MatchError(catalog.ErrUnsupportedVersion))
+		Expect(err.Error()).To(ContainSubstring("3.7, 3.8, 3.9"))
+
+		_, err = catalog.Lookup("4.0.0")
+		Expect(err).To(MatchError(catalog.ErrUnsupportedVersion))
+
+		_, err = catalog.Lookup("latest")
+		Expect(err).To(MatchError(catalog.ErrUnsupportedVersion))
+	})
+})
+
+var _ = Describe("AdaptServerProperties", func() {
+	It("warns about deprecated properties and keeps them", func() {
+		v, _ := catalog.Lookup("3.8.0")
+		properties := map[string]string{"log.message.format.version": "2.8", "num.partitions": "3"}
+
+		Expect(v.AdaptServerProperties(properties)).To(ConsistOf(ContainSubstring("log.message.format.version is deprecated")))
+		Expect(properties).To(HaveKeyWithValue("log.message.format.version", "2.8"))
+	})
+
+	// a release renaming and dropping properties, the supported ones only deprecate them
+	release := &catalog.KafkaVersion{
+		Line:              "9.9",
+		RemovedProperties: []string{"offsets.commit.required.acks"},
+		RenamedProperties: map[string]string{"delegation.token.master.key": "delegation.token.secret.key"},
+	}
+
+	It("renames and drops the properties the release does not accept", func() {
+		properties := map[string]string{
+			"delegation.token.master.key":  "secret",
+			"offsets.commit.required.acks": "-1",
+			"num.partitions":               "3",
+		}
+
+		Expect(release.AdaptServerProperties(properties)).To(HaveLen(2))
+		Expect(properties).To(Equal(map[string]string{
+			"delegation.token.secret.key": "secret",
+			"num.partitions":              "3",
+		}))
+	})
+
+	It("keeps an explicitly set new name over a renamed one", func() {
+		properties := map[string]string{"delegation.token.master.key": "old", "delegation.token.secret.key": "new"}
+
+		release.AdaptServerProperties(properties)
+		Expect(properties).To(Equal(map[string]string{"delegation.token.secret.key": "new"}))
+	})
+})
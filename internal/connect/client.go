@@ -0,0 +1,188 @@
+// Package connect implements a client of the Kafka Connect REST API and
+// reconciles connectors against it.
+package connect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Connector and task states reported by the Connect REST API
+const (
+	StateRunning    = "RUNNING"
+	StatePaused     = "PAUSED"
+	StateStopped    = "STOPPED"
+	StateFailed     = "FAILED"
+	StateUnassigned = "UNASSIGNED"
+	StateRestarting = "RESTARTING"
+)
+
+// APIError is returned for non successful responses of the REST API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("connect REST API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if err is an APIError with status 404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+type ConnectorStatus struct {
+	Name      string       `json:"name"`
+	Connector StateInfo    `json:"connector"`
+	Tasks     []TaskStatus `json:"tasks"`
+	Type      string       `json:"type,omitempty"`
+}
+
+type StateInfo struct {
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+type TaskStatus struct {
+	ID int32 `json:"id"`
+	StateInfo
+}
+
+// Client is a client of the Kafka Connect REST API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the REST API served at baseURL, e.g. `http://connect:8083`
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// GetConnectorConfig returns the configuration of the connector
+func (c *Client) GetConnectorConfig(ctx context.Context, name string) (map[string]string, error) {
+	config := map[string]string{}
+	if err := c.do(ctx, http.MethodGet, connectorPath(name, "config"), nil, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// PutConnectorConfig creates the connector or updates its configuration
+func (c *Client) PutConnectorConfig(ctx context.Context, name string, config map[string]string) error {
+	return c.do(ctx, http.MethodPut, connectorPath(name, "config"), config, nil)
+}
+
+// DeleteConnector deletes the connector
+func (c *Client) DeleteConnector(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, connectorPath(name), nil, nil)
+}
+
+// GetConnectorStatus returns the state of the connector and its tasks
+func (c *Client) GetConnectorStatus(ctx context.Context, name string) (*ConnectorStatus, error) {
+	status := &ConnectorStatus{}
+	if err := c.do(ctx, http.MethodGet, connectorPath(name, "status"), nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// PauseConnector pauses the connector and its tasks
+func (c *Client) PauseConnector(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPut, connectorPath(name, "pause"), nil, nil)
+}
+
+// ResumeConnector resumes a paused or stopped connector
+func (c *Client) ResumeConnector(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPut, connectorPath(name, "resume"), nil, nil)
+}
+
+// StopConnector stops the connector and shuts down its tasks, the configuration is kept
+func (c *Client) StopConnector(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPut, connectorPath(name, "stop"), nil, nil)
+}
+
+// RestartConnector restarts the connector instance only
+func (c *Client) RestartConnector(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, connectorPath(name, "restart"), nil, nil)
+}
+
+// RestartTask restarts a single task of the connector
+func (c *Client) RestartTask(ctx context.Context, name string, taskID int32) error {
+	return c.do(ctx, http.MethodPost, connectorPath(name, "tasks", fmt.Sprint(taskID), "restart"), nil, nil)
+}
+
+func connectorPath(name string, elems ...string) string {
+	path := "/connectors/" + url.PathEscape(name)
+	for _, elem := range elems {
+		path += "/" + url.PathEscape(elem)
+	}
+	return path
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		// the REST API returns errors as `{"error_code": 404, "message": "..."}`
+		var errorBody struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errorBody) == nil && errorBody.Message != "" {
+			apiErr.Message = errorBody.Message
+		}
+		return apiErr
+	}
+
+	if result != nil && len(data) > 0 {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
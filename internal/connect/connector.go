@@ -0,0 +1,238 @@
+package connect
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const (
+	DefaultPollInterval   = time.Minute
+	DefaultRestartBackoff = 10 * time.Second
+	DefaultMaxBackoff     = 30 * time.Minute
+
+	// interval to check the connector again after a state change was requested
+	transitionInterval = 5 * time.Second
+)
+
+// Reasons of the Ready condition
+const (
+	ReasonRunning             = "Running"
+	ReasonPaused              = "Paused"
+	ReasonStopped             = "Stopped"
+	ReasonPending             = "Pending"
+	ReasonFailed              = "Failed"
+	ReasonRestartLimitReached = "RestartLimitReached"
+)
+
+// Connector is the desired state of a connector on the Connect cluster
+type Connector struct {
+	Name string
+	// the complete connector configuration, including `connector.class`
+	Config map[string]string
+	State  kafkav1alpha1.KafkaConnectorState
+
+	AutoRestart bool
+	MaxRestarts *int32
+}
+
+// Syncer reconciles connectors against the Connect REST API
+type Syncer struct {
+	Client *Client
+
+	// Now returns the current time, overridden in tests
+	Now func() time.Time
+
+	PollInterval   time.Duration
+	RestartBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func NewSyncer(client *Client) *Syncer {
+	return &Syncer{
+		Client:         client,
+		Now:            time.Now,
+		PollInterval:   DefaultPollInterval,
+		RestartBackoff: DefaultRestartBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+	}
+}
+
+// Sync creates or updates the connector, moves it into the desired state and restarts failed tasks.
+// The observed state is written to status, the returned duration is the time after which Sync should be called again.
+func (s *Syncer) Sync(ctx context.Context, connector *Connector, status *kafkav1alpha1.KafkaConnectorStatus) (time.Duration, error) {
+	desiredConfig := maps.Clone(connector.Config)
+	desiredConfig["name"] = connector.Name
+
+	currentConfig, err := s.Client.GetConnectorConfig(ctx, connector.Name)
+	if err != nil && !IsNotFound(err) {
+		return 0, err
+	}
+	if err != nil || !maps.Equal(currentConfig, desiredConfig) {
+		if err := s.Client.PutConnectorConfig(ctx, connector.Name, desiredConfig); err != nil {
+			return 0, err
+		}
+	}
+
+	connectorStatus, err := s.Client.GetConnectorStatus(ctx, connector.Name)
+	if IsNotFound(err) {
+		// the connector was just created and is not yet assigned to a worker
+		s.setReady(status, metav1.ConditionFalse, ReasonPending, "Waiting for the connector to be assigned to a worker")
+		return transitionInterval, nil
+	} else if err != nil {
+		return 0, err
+	}
+	setObservedState(status, connectorStatus)
+
+	if changed, err := s.transition(ctx, connector, connectorStatus.Connector.State); err != nil {
+		return 0, err
+	} else if changed {
+		s.setReady(status, metav1.ConditionFalse, ReasonPending, "Moving the connector to state "+string(connector.State))
+		return transitionInterval, nil
+	}
+
+	switch connector.State {
+	case kafkav1alpha1.KafkaConnectorStatePaused:
+		s.setReady(status, metav1.ConditionTrue, ReasonPaused, "The connector is paused")
+		return s.PollInterval, nil
+	case kafkav1alpha1.KafkaConnectorStateStopped:
+		s.setReady(status, metav1.ConditionTrue, ReasonStopped, "The connector is stopped")
+		return s.PollInterval, nil
+	}
+
+	failedTasks := make([]int32, 0)
+	running := connectorStatus.Connector.State == StateRunning
+	for _, task := range connectorStatus.Tasks {
+		if task.State == StateFailed {
+			failedTasks = append(failedTasks, task.ID)
+		}
+		running = running && task.State == StateRunning
+	}
+	connectorFailed := connectorStatus.Connector.State == StateFailed
+
+	if running {
+		if status.AutoRestart != nil {
+			status.AutoRestart.Count = 0
+		}
+		s.setReady(status, metav1.ConditionTrue, ReasonRunning, "The connector and all tasks are running")
+		return s.PollInterval, nil
+	}
+	if !connectorFailed && len(failedTasks) == 0 {
+		s.setReady(status, metav1.ConditionFalse, ReasonPending, "Waiting for the connector and all tasks to be running")
+		return transitionInterval, nil
+	}
+
+	s.setReady(status, metav1.ConditionFalse, ReasonFailed, "The connector or some of its tasks failed")
+	if !connector.AutoRestart {
+		return s.PollInterval, nil
+	}
+	return s.restart(ctx, connector, status, connectorFailed, failedTasks)
+}
+
+// transition requests the desired state of the connector, it returns true if a state change was requested
+func (s *Syncer) transition(ctx context.Context, connector *Connector, state string) (bool, error) {
+	switch connector.State {
+	case kafkav1alpha1.KafkaConnectorStatePaused:
+		if state != StatePaused {
+			return true, s.Client.PauseConnector(ctx, connector.Name)
+		}
+	case kafkav1alpha1.KafkaConnectorStateStopped:
+		if state != StateStopped {
+			return true, s.Client.StopConnector(ctx, connector.Name)
+		}
+	default:
+		if state == StatePaused || state == StateStopped {
+			return true, s.Client.ResumeConnector(ctx, connector.Name)
+		}
+	}
+	return false, nil
+}
+
+// restart restarts the failed connector and failed tasks, if the backoff since the last restart has expired
+func (s *Syncer) restart(
+	ctx context.Context,
+	connector *Connector,
+	status *kafkav1alpha1.KafkaConnectorStatus,
+	connectorFailed bool,
+	failedTasks []int32,
+) (time.Duration, error) {
+	if status.AutoRestart == nil {
+		status.AutoRestart = &kafkav1alpha1.KafkaConnectorAutoRestartStatus{}
+	}
+	restartStatus := status.AutoRestart
+
+	if connector.MaxRestarts != nil && restartStatus.Count >= *connector.MaxRestarts {
+		s.setReady(status, metav1.ConditionFalse, ReasonRestartLimitReached, "The connector failed and the restart limit is reached")
+		return s.PollInterval, nil
+	}
+
+	now := s.Now()
+	if restartStatus.LastRestartTime != nil && restartStatus.Count > 0 {
+		next := restartStatus.LastRestartTime.Add(s.backoff(restartStatus.Count))
+		if now.Before(next) {
+			return next.Sub(now), nil
+		}
+	}
+
+	if connectorFailed {
+		if err := s.Client.RestartConnector(ctx, connector.Name); err != nil {
+			return 0, err
+		}
+	}
+	for _, taskID := range failedTasks {
+		if err := s.Client.RestartTask(ctx, connector.Name, taskID); err != nil {
+			return 0, err
+		}
+	}
+	restartStatus.Count++
+	restartStatus.LastRestartTime = &metav1.Time{Time: now}
+
+	return s.backoff(restartStatus.Count), nil
+}
+
+// backoff returns the time to wait after the given number of consecutive restarts
+func (s *Syncer) backoff(restarts int32) time.Duration {
+	backoff := s.RestartBackoff
+	for i := int32(1); i < restarts; i++ {
+		backoff *= 2
+		if backoff >= s.MaxBackoff {
+			return s.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+func (s *Syncer) setReady(status *kafkav1alpha1.KafkaConnectorStatus, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               kafkav1alpha1.ConditionTypeConnectorReady,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: status.ObservedGeneration,
+	})
+}
+
+func setObservedState(status *kafkav1alpha1.KafkaConnectorStatus, connectorStatus *ConnectorStatus) {
+	status.ConnectorState = connectorStatus.Connector.State
+	status.WorkerID = connectorStatus.Connector.WorkerID
+	status.Tasks = make([]kafkav1alpha1.KafkaConnectorTaskStatus, 0, len(connectorStatus.Tasks))
+	for _, task := range connectorStatus.Tasks {
+		status.Tasks = append(status.Tasks, kafkav1alpha1.KafkaConnectorTaskStatus{
+			ID:       task.ID,
+			State:    task.State,
+			WorkerID: task.WorkerID,
+			Trace:    firstLine(task.Trace),
+		})
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,237 @@
+package connect_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/connect"
+)
+
+var _ = Describe("Syncer", func() {
+	var (
+		ctx       context.Context
+		api       *restAPI
+		syncer    *connect.Syncer
+		now       time.Time
+		connector *connect.Connector
+		status    *kafkav1alpha1.KafkaConnectorStatus
+	)
+
+	readyCondition := func() *metav1.Condition {
+		return meta.FindStatusCondition(status.Conditions, kafkav1alpha1.ConditionTypeConnectorReady)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = newRestAPI()
+		DeferCleanup(api.Close)
+
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		syncer = connect.NewSyncer(connect.NewClient(api.URL(), nil))
+		syncer.Now = func() time.Time { return now }
+
+		connector = &connect.Connector{
+			Name: "file-source",
+			Config: map[string]string{
+				"connector.class": "org.apache.kafka.connect.file.FileStreamSourceConnector",
+				"tasks.max":       "2",
+				"topic":           "lines",
+			},
+			State:       kafkav1alpha1.KafkaConnectorStateRunning,
+			AutoRestart: true,
+		}
+		status = &kafkav1alpha1.KafkaConnectorStatus{}
+	})
+
+	Context("when the connector does not exist", func() {
+		It("should create it and report it as running", func() {
+			// when
+			requeueAfter, err := syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(Equal(connect.DefaultPollInterval))
+			Expect(api.Connector("file-source").config).To(HaveKeyWithValue("topic", "lines"))
+			Expect(api.Connector("file-source").config).To(HaveKeyWithValue("name", "file-source"))
+			Expect(status.ConnectorState).To(Equal(connect.StateRunning))
+			Expect(status.Tasks).To(HaveLen(2))
+			Expect(readyCondition().Status).To(Equal(metav1.ConditionTrue))
+		})
+	})
+
+	Context("when the configuration is unchanged", func() {
+		It("should not update the connector", func() {
+			// given
+			_, err := syncer.Sync(ctx, connector, status)
+			Expect(err).NotTo(HaveOccurred())
+
+			// when
+			_, err = syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			puts := 0
+			for _, request := range api.Requests() {
+				if request == "PUT /connectors/file-source/config" {
+					puts++
+				}
+			}
+			Expect(puts).To(Equal(1))
+		})
+	})
+
+	Context("when the configuration changes", func() {
+		It("should update the connector", func() {
+			// given
+			_, err := syncer.Sync(ctx, connector, status)
+			Expect(err).NotTo(HaveOccurred())
+
+			// when
+			connector.Config["topic"] = "other-lines"
+			_, err = syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").config).To(HaveKeyWithValue("topic", "other-lines"))
+		})
+	})
+
+	DescribeTable("when the desired state changes",
+		func(state kafkav1alpha1.KafkaConnectorState, expectedState string, reason string) {
+			// given
+			_, err := syncer.Sync(ctx, connector, status)
+			Expect(err).NotTo(HaveOccurred())
+
+			// when
+			connector.State = state
+			_, err = syncer.Sync(ctx, connector, status)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").state).To(Equal(expectedState))
+			Expect(status.ConnectorState).To(Equal(expectedState))
+			Expect(readyCondition().Status).To(Equal(metav1.ConditionTrue))
+			Expect(readyCondition().Reason).To(Equal(reason))
+		},
+		Entry("should pause the connector", kafkav1alpha1.KafkaConnectorStatePaused, connect.StatePaused, connect.ReasonPaused),
+		Entry("should stop the connector", kafkav1alpha1.KafkaConnectorStateStopped, connect.StateStopped, connect.ReasonStopped),
+	)
+
+	Context("when a paused connector should run", func() {
+		It("should resume it", func() {
+			// given
+			connector.State = kafkav1alpha1.KafkaConnectorStatePaused
+			_, _ = syncer.Sync(ctx, connector, status)
+			_, _ = syncer.Sync(ctx, connector, status)
+			Expect(api.Connector("file-source").state).To(Equal(connect.StatePaused))
+
+			// when
+			connector.State = kafkav1alpha1.KafkaConnectorStateRunning
+			_, err := syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").state).To(Equal(connect.StateRunning))
+		})
+	})
+
+	Context("when a task failed", func() {
+		BeforeEach(func() {
+			_, err := syncer.Sync(ctx, connector, status)
+			Expect(err).NotTo(HaveOccurred())
+			api.SetTaskState("file-source", 1, connect.StateFailed)
+		})
+
+		It("should restart the task with an exponential backoff", func() {
+			// when the task fails for the first time
+			requeueAfter, err := syncer.Sync(ctx, connector, status)
+
+			// then it is restarted immediately
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").restarts).To(Equal(1))
+			Expect(status.AutoRestart.Count).To(Equal(int32(1)))
+			Expect(status.Tasks[1].State).To(Equal(connect.StateFailed))
+			Expect(status.Tasks[1].Trace).To(Equal("org.apache.kafka.connect.errors.ConnectException: boom"))
+			Expect(readyCondition().Reason).To(Equal(connect.ReasonFailed))
+			Expect(requeueAfter).To(Equal(connect.DefaultRestartBackoff))
+
+			// when it fails again before the backoff expired
+			api.SetTaskState("file-source", 1, connect.StateFailed)
+			now = now.Add(5 * time.Second)
+			requeueAfter, err = syncer.Sync(ctx, connector, status)
+
+			// then it is not restarted
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").restarts).To(Equal(1))
+			Expect(requeueAfter).To(Equal(5 * time.Second))
+
+			// when the backoff expired
+			now = now.Add(5 * time.Second)
+			requeueAfter, err = syncer.Sync(ctx, connector, status)
+
+			// then it is restarted and the backoff doubles
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").restarts).To(Equal(2))
+			Expect(requeueAfter).To(Equal(2 * connect.DefaultRestartBackoff))
+
+			// when the task is running again
+			_, err = syncer.Sync(ctx, connector, status)
+
+			// then the restart counter is reset
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.AutoRestart.Count).To(BeZero())
+			Expect(readyCondition().Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should stop restarting once the restart limit is reached", func() {
+			// given
+			connector.MaxRestarts = ptr.To(int32(0))
+
+			// when
+			_, err := syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").restarts).To(BeZero())
+			Expect(readyCondition().Reason).To(Equal(connect.ReasonRestartLimitReached))
+		})
+
+		It("should not restart the task if auto restart is disabled", func() {
+			// given
+			connector.AutoRestart = false
+
+			// when
+			_, err := syncer.Sync(ctx, connector, status)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.Connector("file-source").restarts).To(BeZero())
+			Expect(readyCondition().Status).To(Equal(metav1.ConditionFalse))
+		})
+	})
+})
+
+var _ = Describe("Client", func() {
+	It("should return a not found error for unknown connectors", func() {
+		// given
+		api := newRestAPI()
+		DeferCleanup(api.Close)
+		client := connect.NewClient(api.URL(), nil)
+
+		// when
+		err := client.DeleteConnector(context.Background(), "unknown")
+
+		// then
+		Expect(connect.IsNotFound(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("Connector unknown not found"))
+	})
+})
@@ -0,0 +1,234 @@
+package connect
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Metrics of the MirrorSourceConnector, exported by the JMX exporter of the MirrorMaker2 workers
+const (
+	MetricReplicationLatencyMs = "kafka_connect_mirror_source_replication_latency_ms"
+	MetricRecordAgeMs          = "kafka_connect_mirror_source_record_age_ms"
+)
+
+// MirrorMetricsExporterConfig is the JMX exporter configuration of the MirrorMaker2 workers.
+// Only the per partition metrics of the MirrorSourceConnector are renamed, everything else is exported as it is.
+const MirrorMetricsExporterConfig = `lowercaseOutputName: true
+rules:
+  - pattern: 'kafka.connect.mirror<type=MirrorSourceConnector, target=(.+), topic=(.+), partition=([0-9]+)><>replication-latency-ms'
+    name: ` + MetricReplicationLatencyMs + `
+    type: GAUGE
+    labels:
+      target: "$1"
+      topic: "$2"
+      partition: "$3"
+  - pattern: 'kafka.connect.mirror<type=MirrorSourceConnector, target=(.+), topic=(.+), partition=([0-9]+)><>record-age-ms'
+    name: ` + MetricRecordAgeMs + `
+    type: GAUGE
+    labels:
+      target: "$1"
+      topic: "$2"
+      partition: "$3"
+  - pattern: '.*'
+`
+
+// PartitionLag is the replication lag of a single partition, as reported by one worker
+type PartitionLag struct {
+	Topic                string
+	Partition            int32
+	ReplicationLatencyMs int64
+	RecordAgeMs          int64
+}
+
+// TopicLag is the replication lag of a topic, aggregated over all partitions
+type TopicLag struct {
+	Topic                string
+	Partitions           int32
+	ReplicationLatencyMs int64
+	RecordAgeMs          int64
+}
+
+// ParseMirrorMetrics reads the partition lag from metrics in the Prometheus text format
+func ParseMirrorMetrics(r io.Reader) ([]PartitionLag, error) {
+	type key struct {
+		topic     string
+		partition int32
+	}
+	lags := map[key]*PartitionLag{}
+	var order []key
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, labels, value, err := parseSample(line)
+		if err != nil {
+			return nil, err
+		}
+		if name != MetricReplicationLatencyMs && name != MetricRecordAgeMs {
+			continue
+		}
+		partition, err := strconv.ParseInt(labels["partition"], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition in metric %q: %w", line, err)
+		}
+		// NaN is reported until the first record was replicated
+		if math.IsNaN(value) {
+			value = 0
+		}
+
+		k := key{topic: labels["topic"], partition: int32(partition)}
+		lag, ok := lags[k]
+		if !ok {
+			lag = &PartitionLag{Topic: k.topic, Partition: k.partition}
+			lags[k] = lag
+			order = append(order, k)
+		}
+		if name == MetricReplicationLatencyMs {
+			lag.ReplicationLatencyMs = int64(value)
+		} else {
+			lag.RecordAgeMs = int64(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]PartitionLag, 0, len(order))
+	for _, k := range order {
+		result = append(result, *lags[k])
+	}
+	return result, nil
+}
+
+// AggregateTopicLag returns the maximum lag per topic, sorted by topic name
+func AggregateTopicLag(partitions []PartitionLag) []TopicLag {
+	type key struct {
+		topic     string
+		partition int32
+	}
+	seen := map[key]bool{}
+	topics := map[string]*TopicLag{}
+	for _, p := range partitions {
+		topic, ok := topics[p.Topic]
+		if !ok {
+			topic = &TopicLag{Topic: p.Topic}
+			topics[p.Topic] = topic
+		}
+		// a partition may be reported by several workers during a rebalance
+		if k := (key{p.Topic, p.Partition}); !seen[k] {
+			seen[k] = true
+			topic.Partitions++
+		}
+		topic.ReplicationLatencyMs = max(topic.ReplicationLatencyMs, p.ReplicationLatencyMs)
+		topic.RecordAgeMs = max(topic.RecordAgeMs, p.RecordAgeMs)
+	}
+
+	result := make([]TopicLag, 0, len(topics))
+	for _, topic := range topics {
+		result = append(result, *topic)
+	}
+	slices.SortFunc(result, func(a, b TopicLag) int { return strings.Compare(a.Topic, b.Topic) })
+	return result
+}
+
+// ScrapeMirrorMetrics fetches the partition lag from the metrics endpoints of all workers
+func ScrapeMirrorMetrics(ctx context.Context, httpClient *http.Client, urls []string) ([]PartitionLag, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	var lags []PartitionLag
+	for _, url := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+		}
+		partitions, err := ParseMirrorMetrics(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metrics of %s: %w", url, err)
+		}
+		lags = append(lags, partitions...)
+	}
+	return lags, nil
+}
+
+// parseSample parses a sample line like `name{label="value",} 1.0`
+func parseSample(line string) (string, map[string]string, float64, error) {
+	labels := map[string]string{}
+	name := line
+	rest := ""
+	if i := strings.IndexByte(line, '{'); i >= 0 {
+		j := strings.LastIndexByte(line, '}')
+		if j < i {
+			return "", nil, 0, fmt.Errorf("invalid metric %q", line)
+		}
+		name = line[:i]
+		for _, pair := range splitLabels(line[i+1 : j]) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return "", nil, 0, fmt.Errorf("invalid label %q in metric %q", pair, line)
+			}
+			unquoted, err := strconv.Unquote(strings.TrimSpace(v))
+			if err != nil {
+				return "", nil, 0, fmt.Errorf("invalid label %q in metric %q", pair, line)
+			}
+			labels[strings.TrimSpace(k)] = unquoted
+		}
+		rest = line[j+1:]
+	} else if i := strings.IndexByte(line, ' '); i >= 0 {
+		name, rest = line[:i], line[i:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, fmt.Errorf("metric %q has no value", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("invalid value in metric %q: %w", line, err)
+	}
+	return name, labels, value, nil
+}
+
+// splitLabels splits `a="1",b="2",` at the commas outside of quoted values
+func splitLabels(s string) []string {
+	var pairs []string
+	inQuotes, escaped, start := false, false, 0
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			if pair := strings.TrimSpace(s[start:i]); pair != "" {
+				pairs = append(pairs, pair)
+			}
+			start = i + 1
+		}
+	}
+	if pair := strings.TrimSpace(s[start:]); pair != "" {
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
@@ -0,0 +1,63 @@
+package connect_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zncdatadev/kafka-operator/internal/connect"
+)
+
+const workerMetrics = `# HELP kafka_connect_mirror_source_replication_latency_ms kafka.connect.mirror:name=null,type=MirrorSourceConnector
+# TYPE kafka_connect_mirror_source_replication_latency_ms gauge
+kafka_connect_mirror_source_replication_latency_ms{target="east",topic="orders",partition="0",} 120.0
+kafka_connect_mirror_source_replication_latency_ms{target="east",topic="orders",partition="1",} 80.0
+kafka_connect_mirror_source_replication_latency_ms{target="east",topic="payments",partition="0",} NaN
+# TYPE kafka_connect_mirror_source_record_age_ms gauge
+kafka_connect_mirror_source_record_age_ms{target="east",topic="orders",partition="0",} 1500.0
+kafka_connect_mirror_source_record_age_ms{target="east",topic="orders",partition="1",} 2500.0
+kafka_connect_mirror_source_record_age_ms{target="east",topic="payments",partition="0",} NaN
+# TYPE jvm_threads_current gauge
+jvm_threads_current 42.0
+`
+
+var _ = Describe("Mirror metrics", func() {
+	It("should parse the partition lag", func() {
+		// when
+		lags, err := connect.ParseMirrorMetrics(strings.NewReader(workerMetrics))
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lags).To(ConsistOf(
+			connect.PartitionLag{Topic: "orders", Partition: 0, ReplicationLatencyMs: 120, RecordAgeMs: 1500},
+			connect.PartitionLag{Topic: "orders", Partition: 1, ReplicationLatencyMs: 80, RecordAgeMs: 2500},
+			connect.PartitionLag{Topic: "payments", Partition: 0},
+		))
+	})
+
+	It("should aggregate the maximum lag per topic over all workers", func() {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(workerMetrics))
+		}))
+		DeferCleanup(server.Close)
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`kafka_connect_mirror_source_replication_latency_ms{target="east",topic="orders",partition="2",} 300.0` + "\n"))
+		}))
+		DeferCleanup(other.Close)
+
+		// when
+		lags, err := connect.ScrapeMirrorMetrics(context.Background(), nil, []string{server.URL, other.URL})
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(connect.AggregateTopicLag(lags)).To(Equal([]connect.TopicLag{
+			{Topic: "orders", Partitions: 3, ReplicationLatencyMs: 300, RecordAgeMs: 2500},
+			{Topic: "payments", Partitions: 1},
+		}))
+	})
+})
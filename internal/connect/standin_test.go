@@ -0,0 +1,152 @@
+package connect_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zncdatadev/kafka-operator/internal/connect"
+)
+
+// restAPI is a local stand-in of the Kafka Connect REST API, keeping connectors in memory.
+// Task states are set by the tests, restarts are recorded and move the task back to RUNNING.
+type restAPI struct {
+	mu         sync.Mutex
+	server     *httptest.Server
+	connectors map[string]*standinConnector
+
+	// requests in the form `METHOD /path`
+	requests []string
+}
+
+type standinConnector struct {
+	config   map[string]string
+	state    string
+	tasks    []connect.TaskStatus
+	restarts int
+}
+
+func newRestAPI() *restAPI {
+	api := &restAPI{connectors: map[string]*standinConnector{}}
+	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
+	return api
+}
+
+func (a *restAPI) Close() {
+	a.server.Close()
+}
+
+func (a *restAPI) URL() string {
+	return a.server.URL
+}
+
+func (a *restAPI) Requests() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.requests...)
+}
+
+func (a *restAPI) Connector(name string) *standinConnector {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.connectors[name]
+}
+
+func (a *restAPI) SetTaskState(name string, id int, state string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connectors[name].tasks[id].State = state
+	a.connectors[name].tasks[id].Trace = "org.apache.kafka.connect.errors.ConnectException: boom\n\tat Task.poll"
+}
+
+func (a *restAPI) handle(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, r.Method+" "+r.URL.Path)
+
+	// /connectors/{name}[/...]
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/connectors/"), "/")
+	name := parts[0]
+	connector := a.connectors[name]
+	action := strings.Join(parts[1:], "/")
+
+	if connector == nil && !(r.Method == http.MethodPut && action == "config") {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error_code": 404, "message": fmt.Sprintf("Connector %s not found", name)})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "config":
+		writeJSON(w, http.StatusOK, connector.config)
+	case r.Method == http.MethodPut && action == "config":
+		config := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+			return
+		}
+		statusCode := http.StatusOK
+		if connector == nil {
+			connector = &standinConnector{state: connect.StateRunning}
+			a.connectors[name] = connector
+			statusCode = http.StatusCreated
+		}
+		connector.config = config
+		tasksMax, _ := strconv.Atoi(config["tasks.max"])
+		connector.tasks = nil
+		for i := range max(tasksMax, 1) {
+			connector.tasks = append(connector.tasks, connect.TaskStatus{
+				ID:        int32(i),
+				StateInfo: connect.StateInfo{State: connect.StateRunning, WorkerID: "worker-0:8083"},
+			})
+		}
+		writeJSON(w, statusCode, config)
+	case r.Method == http.MethodDelete && action == "":
+		delete(a.connectors, name)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && action == "status":
+		tasks := connector.tasks
+		if connector.state == connect.StateStopped {
+			tasks = []connect.TaskStatus{}
+		}
+		writeJSON(w, http.StatusOK, connect.ConnectorStatus{
+			Name:      name,
+			Connector: connect.StateInfo{State: connector.state, WorkerID: "worker-0:8083"},
+			Tasks:     tasks,
+			Type:      "source",
+		})
+	case r.Method == http.MethodPut && action == "pause":
+		connector.state = connect.StatePaused
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && action == "resume":
+		connector.state = connect.StateRunning
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && action == "stop":
+		connector.state = connect.StateStopped
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && action == "restart":
+		connector.state = connect.StateRunning
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && strings.HasPrefix(action, "tasks/") && strings.HasSuffix(action, "/restart"):
+		id, err := strconv.Atoi(parts[2])
+		if err != nil || id >= len(connector.tasks) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"message": "task not found"})
+			return
+		}
+		connector.tasks[id].State = connect.StateRunning
+		connector.tasks[id].Trace = ""
+		connector.restarts++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "unsupported"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
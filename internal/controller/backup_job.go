@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/backup"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+const (
+	// BackupCommandConfig is the client properties file of the Kafka tools run by backup and restore Jobs
+	BackupCommandConfig = "/tmp/client.properties"
+
+	// BackupScriptFilename is the key of the script in the ConfigMap of a restore Job
+	BackupScriptFilename = "restore.sh"
+
+	// BackupResultAnnotation is set on the processed Jobs of a KafkaBackup, it holds the name of the stored
+	// snapshot, or backupResultFailed
+	BackupResultAnnotation = "kafka.kubedoop.dev/backup-result"
+	backupResultFailed     = "Failed"
+
+	backupContainerName = "kafka"
+	backupScriptDir     = kafkav1alpha1.KubedoopRoot + "/backup"
+	backupScriptVolume  = "backup-script"
+	backupJobBackoff    = 2
+
+	s3AccessKey = "accessKey"
+	s3SecretKey = "secretKey"
+)
+
+// PodLogsFunc returns the log of a container of a pod
+type PodLogsFunc func(ctx context.Context, namespace, pod, container string) (string, error)
+
+// NewPodLogsFunc returns a PodLogsFunc reading the logs through the API server,
+// the controller-runtime client does not support the log subresource.
+func NewPodLogsFunc(config *rest.Config) (PodLogsFunc, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, namespace, pod, container string) (string, error) {
+		data, err := clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container}).DoRaw(ctx)
+		return string(data), err
+	}, nil
+}
+
+// NewBackupJobSpec returns the Job running a script of package backup in the Kafka image of kafkaCluster.
+// The script finds the bootstrap servers in $KAFKA, the ZooKeeper connection string in $ZOOKEEPER and
+// the client properties at BackupCommandConfig.
+// If scriptConfigMap is set, the script is read from its key BackupScriptFilename instead.
+func NewBackupJobSpec(
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	clientSecurity *security.ClientSecurity,
+	script string,
+	scriptConfigMap string,
+) batchv1.JobSpec {
+	image := NewImage(kafkaCluster.Spec.Image)
+
+	var properties strings.Builder
+	settings := clientSecurity.ConfigSettings("")
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		fmt.Fprintf(&properties, "%s=%s\n", key, settings[key])
+	}
+	command := fmt.Sprintf("cat > %s <<'EOF'\n%sEOF\n", BackupCommandConfig, properties.String())
+
+	volumes := clientSecurity.Volumes("", "")
+	mounts := clientSecurity.VolumeMounts()
+	if scriptConfigMap != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: backupScriptVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: scriptConfigMap},
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: backupScriptVolume, MountPath: backupScriptDir})
+		command += fmt.Sprintf("exec bash %s/%s\n", backupScriptDir, BackupScriptFilename)
+	} else {
+		command += script
+	}
+
+	return batchv1.JobSpec{
+		BackoffLimit: ptr.To[int32](backupJobBackoff),
+		Template: corev1.PodTemplateSpec{
+			// the pod cache of the operator only holds the pods with this label, see ManagedPodSelector
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{opconstants.LabelKubernetesManagedBy: kafkav1alpha1.GroupVersion.Group},
+			},
+			Spec: corev1.PodSpec{
+				RestartPolicy:      corev1.RestartPolicyNever,
+				ServiceAccountName: ServiceAccountName(kafkaCluster.Name),
+				Volumes:            volumes,
+				Containers: []corev1.Container{
+					{
+						Name:            backupContainerName,
+						Image:           image.String(),
+						ImagePullPolicy: image.GetPullPolicy(),
+						Command:         []string{"bash", "-c"},
+						Args:            []string{command},
+						Env: []corev1.EnvVar{
+							{
+								Name: EnvKafkaBootstrapServers,
+								ValueFrom: &corev1.EnvVarSource{
+									ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: kafkaCluster.Name},
+										Key:                  KafkaDiscoveryKey,
+									},
+								},
+							},
+							{
+								Name: EnvZookeeperConnections,
+								ValueFrom: &corev1.EnvVarSource{
+									ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: kafkaCluster.Spec.ClusterConfig.ZookeeperConfigMapName,
+										},
+										Key: ZookeeperDiscoveryKey,
+									},
+								},
+							},
+						},
+						VolumeMounts: mounts,
+					},
+				},
+			},
+		},
+	}
+}
+
+// jobFinished returns whether the Job completed or failed, and the reason of the failure
+func jobFinished(job *batchv1.Job) (finished bool, failed bool, message string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, false, ""
+		case batchv1.JobFailed:
+			return true, true, condition.Message
+		}
+	}
+	return false, false, ""
+}
+
+// jobLogs returns the log of the succeeded pod of a Job
+func jobLogs(ctx context.Context, client ctrlclient.Client, podLogs PodLogsFunc, job *batchv1.Job) (string, error) {
+	pods := &corev1.PodList{}
+	if err := client.List(ctx, pods, ctrlclient.InNamespace(job.Namespace), ctrlclient.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			return podLogs(ctx, pod.Namespace, pod.Name, backupContainerName)
+		}
+	}
+	return "", fmt.Errorf("no succeeded pod of job %s/%s found", job.Namespace, job.Name)
+}
+
+// newBackupStore returns the store of a backup target, the credentials of S3 are read from their Secret
+func newBackupStore(
+	ctx context.Context,
+	client ctrlclient.Client,
+	namespace string,
+	target *kafkav1alpha1.BackupTargetSpec,
+) (backup.Store, error) {
+	switch {
+	case target.ConfigMap != nil:
+		return &backup.ConfigMapStore{Client: client, Namespace: namespace, Labels: target.ConfigMap.Labels}, nil
+	case target.S3 != nil:
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: namespace, Name: target.S3.CredentialsSecret}
+		if err := client.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get S3 credentials secret %s: %w", key, err)
+		}
+		for _, k := range []string{s3AccessKey, s3SecretKey} {
+			if _, ok := secret.Data[k]; !ok {
+				return nil, fmt.Errorf("S3 credentials secret %s has no key %s", key, k)
+			}
+		}
+		return &backup.S3Store{
+			Endpoint:  target.S3.Endpoint,
+			Bucket:    target.S3.Bucket,
+			Prefix:    target.S3.Prefix,
+			Region:    target.S3.Region,
+			AccessKey: string(secret.Data[s3AccessKey]),
+			SecretKey: string(secret.Data[s3SecretKey]),
+		}, nil
+	default:
+		return nil, fmt.Errorf("backup target has neither configMap nor s3")
+	}
+}
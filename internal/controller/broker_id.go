@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const (
+	ReservedBrokerMaxIdKey = "reserved.broker.max.id"
+
+	// Kafka default of reserved.broker.max.id, configured IDs must not exceed it
+	defaultReservedBrokerMaxId int32 = 1000
+
+	// brokerIdBlockSize is the granularity of the offsets picked by the operator
+	brokerIdBlockSize int32 = 100
+)
+
+type brokerIdRange struct {
+	roleGroup string
+	offset    int32
+	replicas  int32
+}
+
+func (r brokerIdRange) last() int32 {
+	return r.offset + r.replicas - 1
+}
+
+func (r brokerIdRange) overlaps(offset, replicas int32) bool {
+	return r.replicas > 0 && replicas > 0 && offset <= r.last() && r.offset <= offset+replicas-1
+}
+
+// BrokerIdOffsets returns the broker ID offset of every role group.
+// Role groups without an explicit offset keep the one in `assigned`, usually taken from the status,
+// new ones get the lowest multiple of brokerIdBlockSize whose blocks are not used by another role group.
+// The role groups in autoIds keep the IDs auto-generated by ZooKeeper and get no offset,
+// their brokers wrote data before the operator assigned IDs and refuse to start with another one.
+func BrokerIdOffsets(
+	roleGroups map[string]*kafkav1alpha1.BrokersRoleGroupSpec,
+	assigned map[string]int32,
+	autoIds []string,
+) (map[string]int32, error) {
+	names := make([]string, 0, len(roleGroups))
+	for name := range roleGroups {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	offsets := make(map[string]int32, len(roleGroups))
+	var ranges []brokerIdRange
+	var pending []string
+	for _, name := range names {
+		roleGroup := roleGroups[name]
+		offset, ok := assigned[name]
+		if roleGroup.BrokerIdOffset != nil {
+			offset, ok = *roleGroup.BrokerIdOffset, true
+		}
+		if !ok {
+			if !slices.Contains(autoIds, name) {
+				pending = append(pending, name)
+			}
+			continue
+		}
+		offsets[name] = offset
+		ranges = append(ranges, brokerIdRange{roleGroup: name, offset: offset, replicas: roleGroup.Replicas})
+	}
+
+	for _, name := range pending {
+		replicas := roleGroups[name].Replicas
+		// as many blocks as the replicas need, so the IDs stay within the blocks picked
+		size := max(1, (replicas+brokerIdBlockSize-1)/brokerIdBlockSize) * brokerIdBlockSize
+		for offset := int32(0); ; offset += brokerIdBlockSize {
+			if !slices.ContainsFunc(ranges, func(r brokerIdRange) bool { return r.overlaps(offset, size) }) {
+				offsets[name] = offset
+				ranges = append(ranges, brokerIdRange{roleGroup: name, offset: offset, replicas: replicas})
+				break
+			}
+		}
+	}
+
+	if err := validateBrokerIdRanges(ranges); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+func validateBrokerIdRanges(ranges []brokerIdRange) error {
+	slices.SortFunc(ranges, func(a, b brokerIdRange) int {
+		if a.offset != b.offset {
+			return int(a.offset - b.offset)
+		}
+		return strings.Compare(a.roleGroup, b.roleGroup)
+	})
+	for i := 1; i < len(ranges); i++ {
+		prev, cur := ranges[i-1], ranges[i]
+		if prev.overlaps(cur.offset, cur.replicas) {
+			return fmt.Errorf("%w: role group %s uses %d-%d, role group %s uses %d-%d",
+				ErrBrokerIdConflict, prev.roleGroup, prev.offset, prev.last(), cur.roleGroup, cur.offset, cur.last())
+		}
+	}
+	return nil
+}
+
+// validateReservedBrokerMaxId checks the IDs of the role group do not exceed reserved.broker.max.id of its server.properties
+func validateReservedBrokerMaxId(roleGroup string, offset, replicas int32, serverProperties map[string]string) error {
+	reservedMaxId, err := int32Property(serverProperties, ReservedBrokerMaxIdKey, defaultReservedBrokerMaxId)
+	if err != nil {
+		return err
+	}
+	if last := offset + replicas - 1; replicas > 0 && last > reservedMaxId {
+		return fmt.Errorf("%w: role group %s uses IDs up to %d, above %s %d",
+			ErrBrokerIdConflict, roleGroup, last, ReservedBrokerMaxIdKey, reservedMaxId)
+	}
+	return nil
+}
+
+// BrokerIds returns the broker ID of every pod of the role group StatefulSet
+func BrokerIds(statefulSetName string, offset, replicas int32) map[string]int32 {
+	ids := make(map[string]int32, replicas)
+	for ordinal := range replicas {
+		ids[fmt.Sprintf("%s-%d", statefulSetName, ordinal)] = offset + ordinal
+	}
+	return ids
+}
+
+// metaPropertiesFile returns the meta.properties of the first log dir, it holds the broker.id the data was written with
+func metaPropertiesFile(serverProperties map[string]string) string {
+	logDirs := defaultLogDirs
+	if value, ok := serverProperties[LogDirsKey]; ok && value != "" {
+		logDirs = value
+	}
+	logDir, _, _ := strings.Cut(logDirs, ",")
+	return path.Join(strings.TrimSpace(logDir), "meta.properties")
+}
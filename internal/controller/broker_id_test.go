@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+// roleGroups returns broker role groups with the replicas and, for the role groups in offsets, an explicit BrokerIdOffset
+func roleGroups(replicas map[string]int32, offsets map[string]int32) map[string]*kafkav1alpha1.BrokersRoleGroupSpec {
+	specs := map[string]*kafkav1alpha1.BrokersRoleGroupSpec{}
+	for name, n := range replicas {
+		spec := &kafkav1alpha1.BrokersRoleGroupSpec{}
+		spec.Replicas = n
+		if offset, ok := offsets[name]; ok {
+			spec.BrokerIdOffset = ptr.To(offset)
+		}
+		specs[name] = spec
+	}
+	return specs
+}
+
+var _ = Describe("BrokerIdOffsets", func() {
+	DescribeTable("assigns every role group its own ID range",
+		func(replicas, explicit, assigned map[string]int32, autoIds []string, expected map[string]int32) {
+			offsets, err := BrokerIdOffsets(roleGroups(replicas, explicit), assigned, autoIds)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offsets).To(Equal(expected))
+		},
+		Entry("new role groups get consecutive blocks in name order",
+			map[string]int32{"b": 3, "a": 3}, nil, nil, nil,
+			map[string]int32{"a": 0, "b": 100}),
+		Entry("an added role group gets the lowest free block, the others keep theirs",
+			map[string]int32{"a": 3, "b": 3, "c": 3}, nil, map[string]int32{"b": 0, "c": 200}, nil,
+			map[string]int32{"a": 100, "b": 0, "c": 200}),
+		Entry("the remaining role groups keep their offsets once one is removed",
+			map[string]int32{"a": 3, "c": 3}, nil, map[string]int32{"a": 0, "b": 100, "c": 200}, nil,
+			map[string]int32{"a": 0, "c": 200}),
+		Entry("explicit offsets take precedence over assigned ones",
+			map[string]int32{"a": 3}, map[string]int32{"a": 500}, map[string]int32{"a": 0}, nil,
+			map[string]int32{"a": 500}),
+		Entry("new role groups avoid the ranges of explicit offsets",
+			map[string]int32{"a": 3, "b": 3}, map[string]int32{"b": 50}, nil, nil,
+			map[string]int32{"a": 100, "b": 50}),
+		Entry("a role group with more than 100 replicas gets enough blocks",
+			map[string]int32{"a": 150, "b": 3}, nil, nil, nil,
+			map[string]int32{"a": 0, "b": 200}),
+		Entry("a new role group with more than 100 replicas skips blocks too small for it",
+			map[string]int32{"a": 3, "b": 3, "c": 250}, nil, map[string]int32{"a": 0, "b": 200}, nil,
+			map[string]int32{"a": 0, "b": 200, "c": 300}),
+		Entry("role groups with auto-generated IDs get no offset",
+			map[string]int32{"a": 3, "b": 3}, nil, nil, []string{"a"},
+			map[string]int32{"b": 0}),
+		Entry("assigned offsets are kept even if the role group is listed with auto-generated IDs",
+			map[string]int32{"a": 3}, nil, map[string]int32{"a": 100}, []string{"a"},
+			map[string]int32{"a": 100}),
+	)
+
+	DescribeTable("rejects overlapping ID ranges",
+		func(replicas, explicit, assigned map[string]int32, message string) {
+			_, err := BrokerIdOffsets(roleGroups(replicas, explicit), assigned, nil)
+			Expect(err).To(MatchError(ErrBrokerIdConflict))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("of explicit offsets",
+			map[string]int32{"a": 3, "b": 3}, map[string]int32{"a": 0, "b": 2}, nil,
+			"role group a uses 0-2, role group b uses 2-4"),
+		Entry("of an explicit offset and an assigned one",
+			map[string]int32{"a": 3, "b": 3}, map[string]int32{"b": 101}, map[string]int32{"a": 100},
+			"role group a uses 100-102, role group b uses 101-103"),
+		Entry("of an assigned role group scaled into the next one",
+			map[string]int32{"a": 101, "b": 3}, nil, map[string]int32{"a": 0, "b": 100},
+			"role group a uses 0-100, role group b uses 100-102"),
+	)
+})
+
+var _ = Describe("validateReservedBrokerMaxId", func() {
+	DescribeTable("checks the highest ID of the role group",
+		func(offset, replicas int32, serverProperties map[string]string, valid bool) {
+			err := validateReservedBrokerMaxId("default", offset, replicas, serverProperties)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ErrBrokerIdConflict))
+			}
+		},
+		Entry("up to the Kafka default", int32(900), int32(101), nil, true),
+		Entry("above the Kafka default", int32(900), int32(102), nil, false),
+		Entry("up to a raised maximum", int32(1900), int32(101), map[string]string{ReservedBrokerMaxIdKey: "2000"}, true),
+		Entry("above a lowered maximum", int32(100), int32(3), map[string]string{ReservedBrokerMaxIdKey: "100"}, false),
+		Entry("without replicas", int32(5000), int32(0), nil, true),
+	)
+
+	It("fails on an invalid maximum", func() {
+		err := validateReservedBrokerMaxId("default", 0, 1, map[string]string{ReservedBrokerMaxIdKey: "max"})
+		Expect(err).To(MatchError(ContainSubstring(`invalid reserved.broker.max.id "max"`)))
+	})
+})
+
+var _ = Describe("BrokerIds", func() {
+	It("adds the ordinal of every pod to the offset", func() {
+		Expect(BrokerIds("kafka-broker-default", 100, 2)).To(Equal(map[string]int32{
+			"kafka-broker-default-0": 100,
+			"kafka-broker-default-1": 101,
+		}))
+	})
+})
+
+var _ = Describe("autoBrokerIdRoleGroups", func() {
+	It("keeps the auto-generated IDs of the role groups whose brokers already wrote data", func() {
+		cluster := &kafkav1alpha1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "default"}}
+		existing := []string{"kafka-broker-running", "kafka-broker-assigned", "kafka-broker-explicit"}
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+			&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name: DataPvcName("kafka-broker-scaled", 0), Namespace: "default",
+			}},
+		).Build()
+		for _, name := range existing {
+			Expect(fakeClient.Create(context.Background(), &appv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			})).To(Succeed())
+		}
+		broker := &BrokerReconciler{
+			BaseRoleReconciler: reconciler.BaseRoleReconciler[*kafkav1alpha1.BrokersSpec]{
+				BaseReconciler: reconciler.BaseReconciler[*kafkav1alpha1.BrokersSpec]{
+					Client: &client.Client{Client: fakeClient, OwnerReference: cluster},
+					Spec: &kafkav1alpha1.BrokersSpec{RoleGroups: roleGroups(
+						map[string]int32{"running": 3, "scaled": 0, "assigned": 3, "explicit": 3, "new": 3},
+						map[string]int32{"explicit": 200},
+					)},
+				},
+				RoleInfo: reconciler.RoleInfo{ClusterInfo: reconciler.ClusterInfo{ClusterName: "kafka"}, RoleName: RoleName},
+			},
+			assignedBrokerIdOffsets: map[string]int32{"assigned": 100},
+		}
+
+		names, err := broker.autoBrokerIdRoleGroups(context.Background())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(ConsistOf("running", "scaled"))
+	})
+})
@@ -4,8 +4,9 @@ import (
 	"context"
 
 	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+	"github.com/zncdatadev/kafka-operator/internal/metrics"
 	"github.com/zncdatadev/kafka-operator/internal/security"
-	"github.com/zncdatadev/kafka-operator/internal/util/version"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	resourceClient "github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
@@ -18,12 +19,20 @@ type Reconciler struct {
 	reconciler.BaseCluster[*kafkav1alpha1.KafkaClusterSpec]
 	ClusterConfig    *kafkav1alpha1.ClusterConfigSpec
 	ClusterOperation *commonsv1alpha1.ClusterOperationSpec
+	Recorder         *event.Recorder
+
+	// broker role, set by RegisterResources
+	brokers *BrokerReconciler
+	// inter.broker.protocol.version of the brokers, see upgrade.go
+	protocolVersion string
 }
 
 func NewClusterReconciler(
 	client *resourceClient.Client,
 	clusterInfo reconciler.ClusterInfo,
 	spec *kafkav1alpha1.KafkaClusterSpec,
+	recorder *event.Recorder,
+	protocolVersion string,
 ) *Reconciler {
 
 	return &Reconciler{
@@ -33,30 +42,32 @@ func NewClusterReconciler(
 			spec.ClusterOperation,
 			spec,
 		),
-		ClusterConfig: spec.ClusterConfig,
+		ClusterConfig:    spec.ClusterConfig,
+		ClusterOperation: spec.ClusterOperation,
+		Recorder:         recorder,
+		protocolVersion:  protocolVersion,
 	}
 
 }
 
 func (r *Reconciler) GetImage() *util.Image {
-	image := util.NewImage(
-		kafkav1alpha1.DefaultProductName,
-		version.BuildVersion,
-		kafkav1alpha1.DefaultProductVersion,
-		func(options *util.ImageOptions) {
-			options.Custom = r.Spec.Image.Custom
-			options.Repo = r.Spec.Image.Repo
-			options.PullPolicy = *r.Spec.Image.PullPolicy
-		},
-	)
+	return NewImage(r.Spec.Image)
+}
 
-	if r.Spec.Image.KubedoopVersion != "" {
-		image.KubedoopVersion = r.Spec.Image.KubedoopVersion
+// BrokerIdOffsets returns the broker ID offset of each broker role group, nil before RegisterResources
+func (r *Reconciler) BrokerIdOffsets() map[string]int32 {
+	if r.brokers == nil {
+		return nil
 	}
-	if r.Spec.Image.ProductVersion != "" {
-		image.ProductVersion = r.Spec.Image.ProductVersion
+	return r.brokers.BrokerIdOffsets()
+}
+
+// BrokerIds returns the broker ID of each broker pod, nil before RegisterResources
+func (r *Reconciler) BrokerIds() map[string]int32 {
+	if r.brokers == nil {
+		return nil
 	}
-	return image
+	return r.brokers.BrokerIds()
 }
 
 func (r *Reconciler) RegisterResources(ctx context.Context) error {
@@ -79,21 +90,65 @@ func (r *Reconciler) RegisterResources(ctx context.Context) error {
 		r.ClusterConfig,
 		r.ClusterOperation,
 		tlsSecurity,
+		r.Recorder,
+		cluster.Status.BrokerIdOffsets,
+		r.protocolVersion,
 	)
 
 	if err := node.RegisterResources(ctx); err != nil {
 		return err
 	}
 	r.AddResource(node)
+	r.brokers = node
 
 	// Discovery related resources:
-	// Note: NodePort service is merged with the main service.
-	// The access type is now controlled by listenerclass setting.
-	// TODO: Consider deprecating separate NodePort handling
-	discovery := NewKafkaDiscoveryReconciler(ctx, r.Client, tlsSecurity)
-	nodePortDiscovery := NewKafkaDiscoveryNodePortReconciler(ctx, r.Client, tlsSecurity)
-	r.AddResource(discovery)
-	r.AddResource(nodePortDiscovery)
+	// One discovery ConfigMap `<clusterName>-<listenerClass>-discovery` is created for every distinct
+	// bootstrap ListenerClass across role groups, and `<clusterName>` always points to the
+	// primary bootstrap ListenerClass so existing clients keep working.
+	var discoveryNames []string
+	if primaryClass := node.PrimaryBootstrapListenerClass(); primaryClass != "" {
+		r.AddResource(observeResource(
+			r.Recorder,
+			metrics.ResourceDiscovery,
+			newConfigMap,
+			NewKafkaDiscoveryReconciler(ctx, r.Client, tlsSecurity, r.GetName(), primaryClass),
+		))
+		discoveryNames = append(discoveryNames, r.GetName())
+	}
+	for _, listenerClass := range node.BootstrapListenerClasses() {
+		name := DiscoveryConfigMapName(r.GetName(), listenerClass)
+		r.AddResource(observeResource(r.Recorder, metrics.ResourceDiscovery, newConfigMap, NewKafkaDiscoveryReconciler(
+			ctx,
+			r.Client,
+			tlsSecurity,
+			name,
+			listenerClass,
+		)))
+		discoveryNames = append(discoveryNames, name)
+	}
+	r.AddResource(NewStaleDiscoveryReconciler(r.Client, discoveryNames))
+
+	// alerts of the Prometheus Operator
+	if monitoring := r.ClusterConfig.Monitoring; monitoring != nil && monitoring.Alerts != nil {
+		r.AddResource(metrics.Instrument(metrics.ResourceMonitoring, NewKafkaPrometheusRuleReconciler(r.Client, r.GetName(), monitoring)))
+	}
+
+	// optional role `rest-proxy`, connecting to the brokers through the `<clusterName>` discovery ConfigMap
+	if r.Spec.RestProxy != nil {
+		restProxy := NewRestProxyReconciler(
+			r.Client,
+			reconciler.RoleInfo{ClusterInfo: r.ClusterInfo, RoleName: RestProxyRoleName},
+			r.Spec.RestProxy,
+			r.ClusterConfig,
+			r.ClusterOperation,
+			tlsSecurity.Client(),
+		)
+		if err := restProxy.RegisterResources(ctx); err != nil {
+			return err
+		}
+		r.AddResource(restProxy)
+		r.AddResource(metrics.Instrument(metrics.ResourceDiscovery, NewRestProxyDiscoveryReconciler(r.Client, r.GetName())))
+	}
 
 	return nil
 }
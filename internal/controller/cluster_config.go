@@ -2,7 +2,6 @@ package controller
 
 import (
 	"encoding/json"
-	"fmt"
 	"path"
 	"slices"
 
@@ -44,22 +43,31 @@ type KafkaConfig struct {
 }
 
 // ComputeCli implements OverrideConfiguration.
+// The operator passes its own `--override` arguments in the launch command, there are no defaults.
 func (k *KafkaConfig) ComputeCli() ([]string, error) {
-	return nil, fmt.Errorf("unimplemented")
+	return []string{}, nil
 }
 
 // ComputeEnv implements OverrideConfiguration.
 func (k *KafkaConfig) ComputeEnv() (map[string]string, error) {
-	return nil, fmt.Errorf("unimplemented")
+	return map[string]string{
+		// the image's default below the install dir is not writable
+		EnvKafkaLogDir: path.Join(kafkav1alpha1.KubedoopLogDir, string(Kafka)),
+	}, nil
 }
 
+const LogDirsKey = "log.dirs"
+
+// defaultLogDirs are the log dirs of the brokers on their data volume
+var defaultLogDirs = path.Join(constants.KubedoopDataDir, "topicdata")
+
 // ComputeFile implements OverrideConfiguration.
 func (k *KafkaConfig) ComputeFile() (map[string]map[string]string, error) {
 	return map[string]map[string]string{
 		ServerPropertiesFilename: {
 			"zookeeper.connection.timeout.ms": "18000",
 			"controlled.shutdown.enable":      "true",
-			"log.dirs":                        path.Join(constants.KubedoopDataDir, "topicdata"),
+			LogDirsKey:                        defaultLogDirs,
 		},
 		SecurityPropertiesFilename: {
 			"networkaddress.cache.ttl":          "30",
@@ -115,13 +123,8 @@ func defaultAffinity(role string, crName string) *corev1.Affinity {
 				{
 					Weight: 70,
 					PodAffinityTerm: corev1.PodAffinityTerm{
-						LabelSelector: &metav1.LabelSelector{
-							MatchLabels: map[string]string{
-								constants.LabelKubernetesInstance:  crName,
-								constants.LabelKubernetesComponent: role,
-							},
-						},
-						TopologyKey: corev1.LabelHostname,
+						LabelSelector: defaultAffinityLabelSelector(role, crName),
+						TopologyKey:   corev1.LabelHostname,
 					},
 				},
 			},
@@ -129,8 +132,18 @@ func defaultAffinity(role string, crName string) *corev1.Affinity {
 	}
 }
 
+// defaultAffinityLabelSelector selects the pods of the role spread by the default affinity and the rack topology spread constraints
+func defaultAffinityLabelSelector(role string, crName string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			constants.LabelKubernetesInstance:  crName,
+			constants.LabelKubernetesComponent: role,
+		},
+	}
+}
+
 // mergeOverrides merges default configurations into user overrides
-func mergeOverrides(userOverrides *commonsv1alpha1.OverridesSpec, defaultConfig KafkaConfig) error {
+func mergeOverrides(userOverrides *commonsv1alpha1.OverridesSpec, defaultConfig OverrideConfiguration) error {
 	if userOverrides == nil {
 		userOverrides = &commonsv1alpha1.OverridesSpec{}
 	}
@@ -206,7 +219,7 @@ func MergeFromUserConfig(
 	}
 
 	// Merge override configurations
-	return mergeOverrides(userOverrides, defaultConfig)
+	return mergeOverrides(userOverrides, &defaultConfig)
 }
 
 func defaultRoleGroupConfigSpec(defaultKafkaConfig KafkaConfig) *commonsv1alpha1.RoleGroupConfigSpec {
@@ -231,6 +244,11 @@ func mergeBaseConfig(userConfig *kafkav1alpha1.BrokersConfigSpec, defaultConfig
 		userConfig.RoleGroupConfigSpec = defaultRoleGroupConfigSpec(defaultConfig)
 	}
 
+	// the builder derives terminationGracePeriodSeconds from it
+	if userConfig.GracefulShutdownTimeout == "" {
+		userConfig.GracefulShutdownTimeout = defaultConfig.GracefulShutdownTimeout
+	}
+
 	// Resources
 	if userConfig.Resources == nil {
 		userConfig.Resources = defaultConfig.Resources
@@ -248,6 +266,22 @@ func mergeBaseConfig(userConfig *kafkav1alpha1.BrokersConfigSpec, defaultConfig
 	if userConfig.RequestedSecretLifeTime == "" {
 		userConfig.RequestedSecretLifeTime = defaultConfig.RequestedSecretLifetime
 	}
+	if userConfig.Jvm == nil {
+		userConfig.Jvm = defaultJvmSpec()
+	} else {
+		defaultJvm := defaultJvmSpec()
+		if userConfig.Jvm.Heap == "" {
+			userConfig.Jvm.Heap = defaultJvm.Heap
+		}
+		if userConfig.Jvm.PageCacheReservation == "" {
+			userConfig.Jvm.PageCacheReservation = defaultJvm.PageCacheReservation
+		}
+	}
+	if userConfig.Metrics == nil {
+		userConfig.Metrics = defaultMetricsSpec()
+	} else if userConfig.Metrics.Port == 0 {
+		userConfig.Metrics.Port = kafkav1alpha1.MetricsPort
+	}
 
 	return nil
 }
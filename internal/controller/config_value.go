@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const (
+	ConfigProvidersKey = "config.providers"
+
+	// configValueProvider is the alias of the config provider reading the keys mounted below KubedoopConfigValuesDir
+	configValueProvider          = "kubedoop"
+	directoryConfigProviderClass = "org.apache.kafka.common.config.provider.DirectoryConfigProvider"
+)
+
+// configValueSource is a Secret or ConfigMap referenced by serverPropertiesFrom
+type configValueSource struct {
+	kind string // secret or configmap
+	name string
+	keys []string
+	// the volume is optional if every reference to the source is
+	optional bool
+}
+
+func (s *configValueSource) dir() string {
+	return path.Join(kafkav1alpha1.KubedoopConfigValuesDir, s.kind, s.name)
+}
+
+// validateServerPropertiesFrom checks every entry references exactly one key
+func validateServerPropertiesFrom(roleGroup string, values []kafkav1alpha1.ConfigValueFromSpec) error {
+	for _, value := range values {
+		secretRef, configMapRef := value.ValueFrom.SecretKeyRef, value.ValueFrom.ConfigMapKeyRef
+		if (secretRef == nil) == (configMapRef == nil) {
+			return fmt.Errorf("%w: role group %s serverPropertiesFrom %s must set exactly one of secretKeyRef and configMapKeyRef",
+				ErrInvalidOverride, roleGroup, value.Name)
+		}
+		if secretRef != nil && (secretRef.Name == "" || secretRef.Key == "") ||
+			configMapRef != nil && (configMapRef.Name == "" || configMapRef.Key == "") {
+			return fmt.Errorf("%w: role group %s serverPropertiesFrom %s must set the name and key of its reference",
+				ErrInvalidOverride, roleGroup, value.Name)
+		}
+	}
+	return nil
+}
+
+// configValueSources returns the referenced Secrets and ConfigMaps sorted by kind and name
+func configValueSources(values []kafkav1alpha1.ConfigValueFromSpec) []*configValueSource {
+	var sources []*configValueSource
+	for _, value := range values {
+		kind, name, key, optional := configValueRef(value.ValueFrom)
+		i := slices.IndexFunc(sources, func(s *configValueSource) bool { return s.kind == kind && s.name == name })
+		if i < 0 {
+			sources = append(sources, &configValueSource{kind: kind, name: name, optional: optional})
+			i = len(sources) - 1
+		}
+		source := sources[i]
+		if !slices.Contains(source.keys, key) {
+			source.keys = append(source.keys, key)
+		}
+		source.optional = source.optional && optional
+	}
+	slices.SortFunc(sources, func(a, b *configValueSource) int {
+		if c := strings.Compare(a.kind, b.kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return sources
+}
+
+func configValueRef(source kafkav1alpha1.ConfigValueSource) (kind, name, key string, optional bool) {
+	if ref := source.SecretKeyRef; ref != nil {
+		return "secret", ref.Name, ref.Key, ref.Optional != nil && *ref.Optional
+	}
+	ref := source.ConfigMapKeyRef
+	return "configmap", ref.Name, ref.Key, ref.Optional != nil && *ref.Optional
+}
+
+// ConfigValueSettings returns the server.properties entries of serverPropertiesFrom, referencing the mounted keys
+// through the directory config provider, and registers the provider next to the ones in serverProperties
+func ConfigValueSettings(values []kafkav1alpha1.ConfigValueFromSpec, serverProperties map[string]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	var providers []string
+	if existing := strings.TrimSpace(serverProperties[ConfigProvidersKey]); existing != "" {
+		providers = strings.Split(existing, ",")
+	}
+	if !slices.Contains(providers, configValueProvider) {
+		providers = append(providers, configValueProvider)
+	}
+	settings := map[string]string{
+		ConfigProvidersKey: strings.Join(providers, ","),
+		fmt.Sprintf("%s.%s.class", ConfigProvidersKey, configValueProvider): directoryConfigProviderClass,
+	}
+
+	for _, value := range values {
+		kind, name, key, _ := configValueRef(value.ValueFrom)
+		source := configValueSource{kind: kind, name: name}
+		settings[value.Name] = fmt.Sprintf("${%s:%s:%s}", configValueProvider, source.dir(), key)
+	}
+	return settings
+}
+
+// configValueVolumes returns a volume projecting the referenced keys of every Secret and ConfigMap, and their mounts
+func configValueVolumes(values []kafkav1alpha1.ConfigValueFromSpec) ([]corev1.Volume, []corev1.VolumeMount) {
+	sources := configValueSources(values)
+	volumes := make([]corev1.Volume, 0, len(sources))
+	mounts := make([]corev1.VolumeMount, 0, len(sources))
+	for i, source := range sources {
+		// the names of the sources may exceed the length of a volume name
+		volumeName := fmt.Sprintf("config-values-%d", i)
+
+		items := make([]corev1.KeyToPath, 0, len(source.keys))
+		for _, key := range source.keys {
+			items = append(items, corev1.KeyToPath{Key: key, Path: key})
+		}
+
+		volume := corev1.Volume{Name: volumeName}
+		if source.kind == "secret" {
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: source.name, Items: items, Optional: &source.optional}
+		} else {
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.name},
+				Items:                items,
+				Optional:             &source.optional,
+			}
+		}
+		volumes = append(volumes, volume)
+		mounts = append(mounts, corev1.VolumeMount{Name: volumeName, MountPath: source.dir(), ReadOnly: true})
+	}
+	return volumes, mounts
+}
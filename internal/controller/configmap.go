@@ -2,8 +2,12 @@ package controller
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/builder"
@@ -37,6 +41,9 @@ func NewKafkaConfigmapReconciler(
 	roleGroupInf *reconciler.RoleGroupInfo,
 	overrides *commonsv1alpha1.OverridesSpec,
 	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+	metrics *kafkav1alpha1.MetricsSpec,
+	jvm *kafkav1alpha1.JvmSpec,
+	serverPropertiesFrom []kafkav1alpha1.ConfigValueFromSpec,
 ) reconciler.ResourceReconciler[builder.ConfigBuilder] {
 	builder := NewKafkaConfigmapBuilder(
 		client,
@@ -45,6 +52,9 @@ func NewKafkaConfigmapReconciler(
 		kafkaTlsSecurity,
 		overrides,
 		roleGroupConfig,
+		metrics,
+		jvm,
+		serverPropertiesFrom,
 	)
 	return reconciler.NewGenericResourceReconciler(client, builder)
 }
@@ -56,6 +66,9 @@ func NewKafkaConfigmapBuilder(
 	kafkaTlsSecurity *security.KafkaSecurity,
 	overrides *commonsv1alpha1.OverridesSpec,
 	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+	metrics *kafkav1alpha1.MetricsSpec,
+	jvm *kafkav1alpha1.JvmSpec,
+	serverPropertiesFrom []kafkav1alpha1.ConfigValueFromSpec,
 ) builder.ConfigBuilder {
 	return &KafkaConfigmapBuilder{
 		ConfigMapBuilder: *builder.NewConfigMapBuilder(
@@ -70,9 +83,14 @@ func NewKafkaConfigmapBuilder(
 		kafkaSecurity:   kafkaTlsSecurity,
 		overrides:       overrides,
 		roleGroupConfig: roleGroupConfig,
-		ClusterName:     roleGroupInfo.ClusterName,
-		RoleName:        roleGroupInfo.RoleName,
-		RoleGroupName:   roleGroupInfo.RoleGroupName,
+		metrics:         metrics,
+		jvm:             jvm,
+
+		serverPropertiesFrom: serverPropertiesFrom,
+
+		ClusterName:   roleGroupInfo.ClusterName,
+		RoleName:      roleGroupInfo.RoleName,
+		RoleGroupName: roleGroupInfo.RoleGroupName,
 	}
 }
 
@@ -84,6 +102,10 @@ type KafkaConfigmapBuilder struct {
 	kafkaSecurity   *security.KafkaSecurity
 	overrides       *commonsv1alpha1.OverridesSpec
 	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec
+	metrics         *kafkav1alpha1.MetricsSpec
+	jvm             *kafkav1alpha1.JvmSpec
+
+	serverPropertiesFrom []kafkav1alpha1.ConfigValueFromSpec
 
 	ClusterName   string
 	RoleName      string
@@ -95,6 +117,7 @@ func (b *KafkaConfigmapBuilder) Build(ctx context.Context) (ctrlclient.Object, e
 		ServerPropertiesFilename:   b.buildServerProperties,   // server.properties
 		SecurityPropertiesFilename: b.buildSecurityProperties, // security.properties
 		Log4jPropertiesFilename:    b.buildLog4jProperties,    // log4j.properties
+		kafkav1alpha1.JaasFileName: b.buildJaasConfig,         // jaas.conf
 	}
 
 	for filename, builder := range propertyFiles {
@@ -107,6 +130,22 @@ func (b *KafkaConfigmapBuilder) Build(ctx context.Context) (ctrlclient.Object, e
 		}
 	}
 
+	// jmx exporter config
+	if IsMetricsEnabled(b.metrics) {
+		jmxExporterConfig, err := JmxExporterConfig(b.metrics)
+		if err != nil {
+			return nil, err
+		}
+		b.AddItem(kafkav1alpha1.JmxExporterFileName, jmxExporterConfig) // jmx-exporter.yaml
+	}
+
+	// effective jvm settings, the container gets the same from the environment
+	jvm, err := NewJvmConfig(b.jvm, b.roleGroupConfig.Resources, KafkaJvmArgs(b.metrics))
+	if err != nil {
+		return nil, err
+	}
+	b.AddItem(kafkav1alpha1.JvmConfigFileName, jvm.String()) // jvm.env
+
 	// vector config
 	if IsVectorEnable(b.roleGroupConfig.Logging) {
 		if vectorConfig, err := b.buildVectorConfig(ctx); err != nil {
@@ -137,7 +176,8 @@ func (b *KafkaConfigmapBuilder) buildServerProperties() (string, error) {
 		data = b.overrides.ConfigOverrides[ServerPropertiesFilename]
 	}
 
-	maps.Copy(data, b.kafkaSecurity.ConfigSettings()) // tls
+	maps.Copy(data, b.kafkaSecurity.ConfigSettings())                  // tls
+	maps.Copy(data, ConfigValueSettings(b.serverPropertiesFrom, data)) // secret values
 
 	propertyLoader := properties.NewPropertiesFromMap(data)
 	return propertyLoader.Marshal()
@@ -165,14 +205,77 @@ func (b *KafkaConfigmapBuilder) buildLog4jProperties() (string, error) {
 		return "", err
 	}
 
-	return loggingConfig.Content()
+	content, err := loggingConfig.Content()
+	if err != nil {
+		return "", err
+	}
+	return appendOverrides(content, b.configOverrides(Log4jPropertiesFilename)), nil
+}
+
+// jaas config of the Kerberos listeners, the principals are resolved from system properties set by the launch command
+func (b *KafkaConfigmapBuilder) buildJaasConfig() (string, error) {
+	kerberos, err := b.kafkaSecurity.GetKerberosAuth()
+	if err != nil || kerberos == nil {
+		return "", err
+	}
+	serviceName := kerberos.Role.KerberosServiceName()
+
+	options := map[string]string{
+		"useKeyTab":   "true",
+		"storeKey":    "true",
+		"isInitiator": "false",
+		"keyTab":      strconv.Quote(path.Join(kafkav1alpha1.KubedoopKerberosDir, "keytab")),
+	}
+	sections := []struct {
+		listener        KafkaListenerName
+		addressProperty string
+	}{
+		{Client, JaasBrokerAddressProperty},
+		{Bootstrap, JaasBootstrapAddressProperty},
+	}
+
+	var sb strings.Builder
+	for _, section := range sections {
+		sectionOptions := maps.Clone(options)
+		sectionOptions["principal"] = strconv.Quote(fmt.Sprintf("%s/${%s}@${%s}", serviceName, section.addressProperty, JaasKerberosRealmProperty))
+		maps.Copy(sectionOptions, b.configOverrides(kafkav1alpha1.JaasFileName))
+
+		// the section of a listener is named after it in lower case
+		fmt.Fprintf(&sb, "%s.KafkaServer {\n  com.sun.security.auth.module.Krb5LoginModule required", strings.ToLower(string(section.listener)))
+		for _, key := range slices.Sorted(maps.Keys(sectionOptions)) {
+			fmt.Fprintf(&sb, "\n  %s=%s", key, sectionOptions[key])
+		}
+		sb.WriteString(";\n};\n")
+	}
+	return sb.String(), nil
+}
+
+func (b *KafkaConfigmapBuilder) configOverrides(filename string) map[string]string {
+	if b.overrides == nil {
+		return nil
+	}
+	return b.overrides.ConfigOverrides[filename]
+}
+
+// appendOverrides appends the overrides to a generated properties file, a later definition of a key wins
+func appendOverrides(content string, overrides map[string]string) string {
+	if len(overrides) == 0 {
+		return content
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(content, "\n"))
+	sb.WriteString("\n\n# overrides\n")
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		fmt.Fprintf(&sb, "%s=%s\n", key, overrides[key])
+	}
+	return sb.String()
 }
 
 // vector config
 func (b *KafkaConfigmapBuilder) buildVectorConfig(ctx context.Context) (string, error) {
 	if b.roleGroupConfig != nil && b.roleGroupConfig.Logging != nil && b.roleGroupConfig.Logging.EnableVectorAgent != nil {
 		if b.ClusterConfig.VectorAggregatorConfigMapName == "" {
-			return "", errors.New("vector is enabled but vectorAggregatorConfigMapName is not set")
+			return "", ErrVectorAggregatorNotSet
 		}
 		if *b.roleGroupConfig.Logging.EnableVectorAgent {
 			s, err := productlogging.MakeVectorYaml(
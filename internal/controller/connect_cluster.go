@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+var _ reconciler.Reconciler = &ConnectReconciler{}
+
+// ConnectReconciler reconciles the resources of a KafkaConnectCluster
+type ConnectReconciler struct {
+	reconciler.BaseCluster[*kafkav1alpha1.KafkaConnectClusterSpec]
+	ClusterConfig *kafkav1alpha1.KafkaConnectClusterConfigSpec
+
+	// the KafkaCluster referenced by `clusterConfig.kafkaClusterRef`
+	kafkaCluster *kafkav1alpha1.KafkaCluster
+}
+
+func NewConnectClusterReconciler(
+	client *client.Client,
+	clusterInfo reconciler.ClusterInfo,
+	spec *kafkav1alpha1.KafkaConnectClusterSpec,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+) *ConnectReconciler {
+	return &ConnectReconciler{
+		BaseCluster: *reconciler.NewBaseCluster(
+			client,
+			clusterInfo,
+			spec.ClusterOperation,
+			spec,
+		),
+		ClusterConfig: spec.ClusterConfig,
+		kafkaCluster:  kafkaCluster,
+	}
+}
+
+func (r *ConnectReconciler) GetImage() *opgoutil.Image {
+	return NewImage(r.Spec.Image)
+}
+
+func (r *ConnectReconciler) RegisterResources(ctx context.Context) error {
+	// RBAC
+	sa := NewServiceAccountReconciler(r.Client, r.GetName())
+	r.AddResource(sa)
+
+	// role `worker`
+	roleInfo := reconciler.RoleInfo{ClusterInfo: r.ClusterInfo, RoleName: ConnectRoleName}
+
+	worker := NewConnectWorkerReconciler(
+		r.Client,
+		roleInfo,
+		r.Spec.Workers,
+		r.GetImage(),
+		r.ClusterConfig,
+		r.Spec.ClusterOperation,
+		r.kafkaCluster,
+		security.NewKafkaSecurity(r.kafkaCluster),
+	)
+	if err := worker.RegisterResources(ctx); err != nil {
+		return err
+	}
+	r.AddResource(worker)
+
+	// discovery
+	r.AddResource(NewConnectDiscoveryReconciler(r.Client, r.GetName()))
+
+	return nil
+}
+
+// ConnectWorkerReconciler reconciles the role groups of the Connect workers
+type ConnectWorkerReconciler struct {
+	reconciler.BaseRoleReconciler[*kafkav1alpha1.KafkaConnectWorkersSpec]
+
+	clusterConfig    *kafkav1alpha1.KafkaConnectClusterConfigSpec
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec
+	image            *opgoutil.Image
+	kafkaCluster     *kafkav1alpha1.KafkaCluster
+	kafkaSecurity    *security.KafkaSecurity
+}
+
+func NewConnectWorkerReconciler(
+	client *client.Client,
+	roleInfo reconciler.RoleInfo,
+	spec *kafkav1alpha1.KafkaConnectWorkersSpec,
+	image *opgoutil.Image,
+	clusterConfig *kafkav1alpha1.KafkaConnectClusterConfigSpec,
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	kafkaSecurity *security.KafkaSecurity,
+) *ConnectWorkerReconciler {
+	stopped := clusterOperation != nil && clusterOperation.Stopped
+
+	return &ConnectWorkerReconciler{
+		BaseRoleReconciler: *reconciler.NewBaseRoleReconciler(
+			client,
+			stopped,
+			roleInfo,
+			spec,
+		),
+		clusterConfig:    clusterConfig,
+		clusterOperation: clusterOperation,
+		image:            image,
+		kafkaCluster:     kafkaCluster,
+		kafkaSecurity:    kafkaSecurity,
+	}
+}
+
+func (r *ConnectWorkerReconciler) RegisterResources(ctx context.Context) error {
+	for name, roleGroup := range r.Spec.RoleGroups {
+		if roleGroup == nil {
+			roleGroup = &kafkav1alpha1.KafkaConnectRoleGroupSpec{Replicas: 1}
+		}
+		mergedConfig, err := opgoutil.MergeObject(r.Spec.Config, roleGroup.Config)
+		if err != nil {
+			return err
+		}
+		overrides, err := opgoutil.MergeObject(r.Spec.OverridesSpec, roleGroup.OverridesSpec)
+		if err != nil {
+			return err
+		}
+
+		// merge default config to the user provided config
+		if overrides == nil {
+			overrides = &commonsv1alpha1.OverridesSpec{}
+		}
+		if mergedConfig == nil {
+			mergedConfig = &kafkav1alpha1.KafkaConnectConfigSpec{}
+		}
+		if err := MergeFromUserConnectConfig(mergedConfig, overrides, r.GetClusterName()); err != nil {
+			return err
+		}
+
+		info := &reconciler.RoleGroupInfo{
+			RoleInfo:      r.RoleInfo,
+			RoleGroupName: name,
+		}
+		for _, reconciler := range r.registerResourceWithRoleGroup(ctx, roleGroup.Replicas, info, overrides, mergedConfig) {
+			r.AddResource(reconciler)
+			logger.Info("registered resource", "role", r.GetName(), "roleGroup", name, "reconciler", reconciler.GetName())
+		}
+	}
+	return nil
+}
+
+func (r *ConnectWorkerReconciler) registerResourceWithRoleGroup(
+	ctx context.Context,
+	replicas int32,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	overrides *commonsv1alpha1.OverridesSpec,
+	connectConfig *kafkav1alpha1.KafkaConnectConfigSpec,
+) []reconciler.Reconciler {
+	return []reconciler.Reconciler{
+		// headless service of the statefulset
+		NewRoleGroupService(r.Client, roleGroupInfo),
+		// configmap
+		NewConnectConfigmapReconciler(
+			r.Client,
+			roleGroupInfo,
+			r.clusterConfig,
+			r.kafkaCluster,
+			r.kafkaSecurity.Client(),
+			overrides,
+			connectConfig.RoleGroupConfigSpec,
+		),
+		// statefulset
+		NewConnectStatefulSetReconciler(
+			ctx,
+			r.Client,
+			r.image,
+			&replicas,
+			r.clusterConfig,
+			r.clusterOperation,
+			roleGroupInfo,
+			connectConfig,
+			overrides,
+			r.kafkaSecurity.Client(),
+		),
+		// rest listener
+		NewConnectRestListenerReconciler(r.Client, connectConfig.RestListenerClass, roleGroupInfo),
+	}
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const (
+	ConnectRoleName = "worker"
+
+	// maxConnectInternalTopicReplicationFactor caps the default replication factor of the internal topics
+	maxConnectInternalTopicReplicationFactor int32 = 3
+)
+
+var _ OverrideConfiguration = &ConnectConfig{}
+
+type ConnectConfig struct {
+	commonsv1alpha1.RoleGroupConfigSpec
+
+	RestListenerClass string
+
+	RequestedSecretLifetime string
+}
+
+// ComputeCli implements OverrideConfiguration.
+func (c *ConnectConfig) ComputeCli() ([]string, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ComputeEnv implements OverrideConfiguration.
+func (c *ConnectConfig) ComputeEnv() (map[string]string, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ComputeFile implements OverrideConfiguration.
+func (c *ConnectConfig) ComputeFile() (map[string]map[string]string, error) {
+	return map[string]map[string]string{
+		kafkav1alpha1.ConnectDistributedFileName: {
+			"key.converter":                  "org.apache.kafka.connect.json.JsonConverter",
+			"value.converter":                "org.apache.kafka.connect.json.JsonConverter",
+			"key.converter.schemas.enable":   "true",
+			"value.converter.schemas.enable": "true",
+			"offset.flush.interval.ms":       "10000",
+		},
+		SecurityPropertiesFilename: {
+			"networkaddress.cache.ttl":          "30",
+			"networkaddress.cache.negative.ttl": "0",
+		},
+	}, nil
+}
+
+func DefaultConnectConfig(clusterName string) ConnectConfig {
+	rawAffinity, err := json.Marshal(defaultAffinity(ConnectRoleName, clusterName))
+	if err != nil {
+		clusterConfigLogger.Error(err, "Failed to marshal affinity")
+	}
+
+	return ConnectConfig{
+		RoleGroupConfigSpec: commonsv1alpha1.RoleGroupConfigSpec{
+			Affinity: &runtime.RawExtension{
+				Raw: rawAffinity,
+			},
+			GracefulShutdownTimeout: "30s",
+			Logging: &commonsv1alpha1.LoggingSpec{
+				EnableVectorAgent: ptr.To(false),
+				Containers:        nil,
+			},
+			Resources: &commonsv1alpha1.ResourcesSpec{
+				CPU: &commonsv1alpha1.CPUResource{
+					Max: resource.MustParse("1000m"),
+					Min: resource.MustParse("250m"),
+				},
+				Memory: &commonsv1alpha1.MemoryResource{
+					Limit: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		RestListenerClass:       "cluster-internal",
+		RequestedSecretLifetime: "1d",
+	}
+}
+
+// MergeFromUserConnectConfig merges the default worker configuration into the user provided configuration
+func MergeFromUserConnectConfig(
+	userConfig *kafkav1alpha1.KafkaConnectConfigSpec,
+	userOverrides *commonsv1alpha1.OverridesSpec,
+	clusterName string,
+) error {
+	defaultConfig := DefaultConnectConfig(clusterName)
+
+	if userConfig.RoleGroupConfigSpec == nil {
+		userConfig.RoleGroupConfigSpec = &commonsv1alpha1.RoleGroupConfigSpec{
+			Affinity:                defaultConfig.Affinity,
+			GracefulShutdownTimeout: defaultConfig.GracefulShutdownTimeout,
+			Logging:                 defaultConfig.Logging,
+			Resources:               defaultConfig.Resources,
+		}
+	}
+	if userConfig.Affinity == nil {
+		userConfig.Affinity = defaultConfig.Affinity
+	}
+	if userConfig.Logging == nil {
+		userConfig.Logging = defaultConfig.Logging
+	}
+	if userConfig.Resources == nil {
+		userConfig.Resources = defaultConfig.Resources
+	} else {
+		mergeResources(userConfig.Resources, defaultConfig.Resources)
+	}
+
+	if userConfig.RestListenerClass == "" {
+		userConfig.RestListenerClass = defaultConfig.RestListenerClass
+	}
+	if userConfig.RequestedSecretLifeTime == "" {
+		userConfig.RequestedSecretLifeTime = defaultConfig.RequestedSecretLifetime
+	}
+
+	return mergeOverrides(userOverrides, &defaultConfig)
+}
+
+// ConnectGroupID returns the Connect group id of the workers
+func ConnectGroupID(name string, clusterConfig *kafkav1alpha1.KafkaConnectClusterConfigSpec) string {
+	if clusterConfig.GroupID != "" {
+		return clusterConfig.GroupID
+	}
+	return name
+}
+
+// ConnectInternalTopics are the names and replication factors of the topics Connect uses to store its state
+type ConnectInternalTopics struct {
+	ConfigStorageTopic string
+	OffsetStorageTopic string
+	StatusStorageTopic string
+
+	ConfigStorageReplicationFactor int32
+	OffsetStorageReplicationFactor int32
+	StatusStorageReplicationFactor int32
+}
+
+// NewConnectInternalTopics returns the internal topics of the Connect group. Replication factors which are
+// not set explicitly default to the number of brokers of the KafkaCluster, capped at 3.
+func NewConnectInternalTopics(
+	groupID string,
+	spec *kafkav1alpha1.KafkaConnectInternalTopicsSpec,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+) *ConnectInternalTopics {
+	defaultFactor := min(BrokerCount(kafkaCluster), maxConnectInternalTopicReplicationFactor)
+	if defaultFactor < 1 {
+		defaultFactor = 1
+	}
+
+	topics := &ConnectInternalTopics{
+		ConfigStorageTopic:             groupID + "-configs",
+		OffsetStorageTopic:             groupID + "-offsets",
+		StatusStorageTopic:             groupID + "-status",
+		ConfigStorageReplicationFactor: defaultFactor,
+		OffsetStorageReplicationFactor: defaultFactor,
+		StatusStorageReplicationFactor: defaultFactor,
+	}
+	if spec != nil {
+		if spec.ConfigStorageReplicationFactor != nil {
+			topics.ConfigStorageReplicationFactor = *spec.ConfigStorageReplicationFactor
+		}
+		if spec.OffsetStorageReplicationFactor != nil {
+			topics.OffsetStorageReplicationFactor = *spec.OffsetStorageReplicationFactor
+		}
+		if spec.StatusStorageReplicationFactor != nil {
+			topics.StatusStorageReplicationFactor = *spec.StatusStorageReplicationFactor
+		}
+	}
+	return topics
+}
+
+// ConfigSettings returns the worker properties of the internal topics
+func (t *ConnectInternalTopics) ConfigSettings() map[string]string {
+	return map[string]string{
+		"config.storage.topic":              t.ConfigStorageTopic,
+		"offset.storage.topic":              t.OffsetStorageTopic,
+		"status.storage.topic":              t.StatusStorageTopic,
+		"config.storage.replication.factor": fmt.Sprint(t.ConfigStorageReplicationFactor),
+		"offset.storage.replication.factor": fmt.Sprint(t.OffsetStorageReplicationFactor),
+		"status.storage.replication.factor": fmt.Sprint(t.StatusStorageReplicationFactor),
+	}
+}
+
+// BrokerCount returns the number of brokers of all role groups of the KafkaCluster
+func BrokerCount(kafkaCluster *kafkav1alpha1.KafkaCluster) int32 {
+	var count int32
+	if kafkaCluster.Spec.Brokers == nil {
+		return count
+	}
+	for _, roleGroup := range kafkaCluster.Spec.Brokers.RoleGroups {
+		if roleGroup != nil {
+			count += roleGroup.Replicas
+		}
+	}
+	return count
+}
+
+// ConnectPluginDir returns the directory a connector plugin is installed to
+func ConnectPluginDir(pluginName string) string {
+	return path.Join(kafkav1alpha1.KubedoopConnectPluginsDir, pluginName)
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"maps"
+	"strconv"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/config/properties"
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+const (
+	ConnectLog4jFilename = "connect.log4j.xml"
+)
+
+// client prefixes of the Connect worker, every client needs its own security settings
+var connectClientPrefixes = []string{"", "producer.", "consumer.", "admin."}
+
+func NewConnectConfigmapReconciler(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	clusterConfig *kafkav1alpha1.KafkaConnectClusterConfigSpec,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	clientSecurity *security.ClientSecurity,
+	overrides *commonsv1alpha1.OverridesSpec,
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+) reconciler.ResourceReconciler[builder.ConfigBuilder] {
+	builder := NewConnectConfigmapBuilder(
+		client,
+		roleGroupInfo,
+		clusterConfig,
+		kafkaCluster,
+		clientSecurity,
+		overrides,
+		roleGroupConfig,
+	)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
+
+func NewConnectConfigmapBuilder(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	clusterConfig *kafkav1alpha1.KafkaConnectClusterConfigSpec,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	clientSecurity *security.ClientSecurity,
+	overrides *commonsv1alpha1.OverridesSpec,
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+) builder.ConfigBuilder {
+	return &ConnectConfigmapBuilder{
+		ConfigMapBuilder: *builder.NewConfigMapBuilder(
+			client,
+			roleGroupInfo.GetFullName(),
+			func(o *builder.Options) {
+				o.Labels = roleGroupInfo.GetLabels()
+				o.Annotations = roleGroupInfo.GetAnnotations()
+			},
+		),
+		clusterConfig:   clusterConfig,
+		kafkaCluster:    kafkaCluster,
+		clientSecurity:  clientSecurity,
+		overrides:       overrides,
+		roleGroupConfig: roleGroupConfig,
+		roleGroupInfo:   roleGroupInfo,
+	}
+}
+
+type ConnectConfigmapBuilder struct {
+	builder.ConfigMapBuilder
+
+	clusterConfig   *kafkav1alpha1.KafkaConnectClusterConfigSpec
+	kafkaCluster    *kafkav1alpha1.KafkaCluster
+	clientSecurity  *security.ClientSecurity
+	overrides       *commonsv1alpha1.OverridesSpec
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec
+	roleGroupInfo   *reconciler.RoleGroupInfo
+}
+
+func (b *ConnectConfigmapBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	propertyFiles := map[string]func() (string, error){
+		kafkav1alpha1.ConnectDistributedFileName: b.buildConnectDistributedProperties, // connect-distributed.properties
+		SecurityPropertiesFilename:               b.buildSecurityProperties,           // security.properties
+		Log4jPropertiesFilename:                  b.buildLog4jProperties,              // log4j.properties
+	}
+
+	for filename, builder := range propertyFiles {
+		content, err := builder()
+		if err != nil {
+			return nil, err
+		}
+		if content != "" {
+			b.AddItem(filename, content)
+		}
+	}
+
+	// vector config
+	if IsVectorEnable(b.roleGroupConfig.Logging) {
+		if b.clusterConfig.VectorAggregatorConfigMapName == "" {
+			return nil, ErrVectorAggregatorNotSet
+		}
+		vectorConfig, err := productlogging.MakeVectorYaml(
+			ctx,
+			b.Client.Client,
+			b.Client.GetOwnerNamespace(),
+			b.roleGroupInfo.ClusterName,
+			b.roleGroupInfo.RoleName,
+			b.roleGroupInfo.RoleGroupName,
+			b.clusterConfig.VectorAggregatorConfigMapName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		b.AddItem(VectorConfigFilename, vectorConfig) // vector.yaml
+	}
+
+	return b.GetObject(), nil
+}
+
+// connect-distributed.properties
+//
+// The bootstrap servers and the advertised REST host are only known at runtime and are appended by the container command.
+func (b *ConnectConfigmapBuilder) buildConnectDistributedProperties() (string, error) {
+	groupID := ConnectGroupID(b.roleGroupInfo.ClusterName, b.clusterConfig)
+
+	data := map[string]string{
+		"group.id":                 groupID,
+		"listeners":                "http://0.0.0.0:" + strconv.Itoa(kafkav1alpha1.ConnectRestPort),
+		"rest.advertised.listener": "http",
+		"rest.advertised.port":     strconv.Itoa(kafkav1alpha1.ConnectRestPort),
+		"plugin.path":              kafkav1alpha1.KubedoopConnectPluginsDir,
+	}
+	maps.Copy(data, NewConnectInternalTopics(groupID, b.clusterConfig.InternalTopics, b.kafkaCluster).ConfigSettings())
+
+	if b.overrides != nil && b.overrides.ConfigOverrides != nil {
+		maps.Copy(data, b.overrides.ConfigOverrides[kafkav1alpha1.ConnectDistributedFileName])
+	}
+
+	for _, prefix := range connectClientPrefixes {
+		maps.Copy(data, b.clientSecurity.ConfigSettings(prefix)) // tls, kerberos
+	}
+
+	return properties.NewPropertiesFromMap(data).Marshal()
+}
+
+// security properties
+func (b *ConnectConfigmapBuilder) buildSecurityProperties() (string, error) {
+	if b.overrides != nil && b.overrides.ConfigOverrides != nil {
+		if data, ok := b.overrides.ConfigOverrides[SecurityPropertiesFilename]; ok {
+			return properties.NewPropertiesFromMap(data).Marshal()
+		}
+	}
+	return "", nil
+}
+
+// log4j properties
+func (b *ConnectConfigmapBuilder) buildLog4jProperties() (string, error) {
+	var loggingSpec *commonsv1alpha1.LoggingConfigSpec
+	if b.roleGroupConfig != nil && b.roleGroupConfig.Logging != nil && b.roleGroupConfig.Logging.Containers != nil {
+		if mainContainerLogging, ok := b.roleGroupConfig.Logging.Containers[ConnectRoleName]; ok {
+			loggingSpec = &mainContainerLogging
+		}
+	}
+	loggingConfig, err := productlogging.NewConfigGenerator(
+		loggingSpec,
+		ConnectRoleName,
+		ConnectLog4jFilename,
+		productlogging.LogTypeLog4j,
+		func(cgo *productlogging.ConfigGeneratorOption) {
+			cgo.ConsoleHandlerFormatter = ptr.To(ConsoleConversionPattern)
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return loggingConfig.Content()
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/pkg"
+)
+
+const (
+	KafkaConnectDiscoveryKey = "KAFKA_CONNECT"
+
+	LabelListenerConnectRest = "app.kubernetes.io/listener-connect-rest"
+)
+
+func ConnectRestListenerName(roleGroupInfo *reconciler.RoleGroupInfo) string {
+	return roleGroupInfo.GetFullName() + "-rest"
+}
+
+func ConnectRestContainerPorts() []corev1.ContainerPort {
+	return []corev1.ContainerPort{
+		{
+			Name:          kafkav1alpha1.ConnectRestPortName,
+			ContainerPort: kafkav1alpha1.ConnectRestPort,
+			Protocol:      corev1.ProtocolTCP,
+		},
+	}
+}
+
+func NewConnectRestListenerReconciler(
+	client *client.Client,
+	restListenerClass string,
+	info *reconciler.RoleGroupInfo,
+) reconciler.ResourceReconciler[pkg.ListenerBuidler] {
+	builder := pkg.NewListenerBuilder(
+		client,
+		ConnectRestListenerName(info),
+		restListenerClass,
+		func(lbo *pkg.ListenerBuilderOptions) {
+			lbo.ContainerPorts = ConnectRestContainerPorts()
+			lbo.ExtraPodSelectorLabels = map[string]string{
+				LabelListenerConnectRest: LabelValueTrue, // add this label for search in discovery
+			}
+		},
+	)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
+
+// NewConnectDiscoveryReconciler creates the discovery ConfigMap of a KafkaConnectCluster,
+// containing the REST endpoints of all worker role groups.
+func NewConnectDiscoveryReconciler(
+	client *client.Client,
+	name string,
+) reconciler.ResourceReconciler[builder.ConfigBuilder] {
+	builder := NewHttpDiscoveryBuilder(client, name, LabelListenerConnectRest, kafkav1alpha1.ConnectRestPortName, KafkaConnectDiscoveryKey)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+	"github.com/zncdatadev/kafka-operator/internal/util"
+)
+
+const (
+	// ConnectKerberosServiceName is the service name of the principal used by the workers
+	ConnectKerberosServiceName = "kafka-connect"
+
+	connectPluginsInitContainerName = "connect-plugins"
+	connectRuntimePropertiesPath    = "/tmp/" + kafkav1alpha1.ConnectDistributedFileName
+)
+
+func NewConnectStatefulSetReconciler(
+	ctx context.Context,
+	client *client.Client,
+	image *opgoutil.Image,
+	replicas *int32,
+	clusterConfig *kafkav1alpha1.KafkaConnectClusterConfigSpec,
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	connectConfig *kafkav1alpha1.KafkaConnectConfigSpec,
+	overrides *commonsv1alpha1.OverridesSpec,
+	clientSecurity *security.ClientSecurity,
+) reconciler.ResourceReconciler[builder.StatefulSetBuilder] {
+	stopped := clusterOperation != nil && clusterOperation.Stopped
+
+	builder := &ConnectStatefulSetBuilder{
+		StatefulSet: *builder.NewStatefulSetBuilder(
+			client,
+			roleGroupInfo.GetFullName(),
+			replicas,
+			image,
+			overrides,
+			connectConfig.RoleGroupConfigSpec,
+			func(o *builder.Options) {
+				o.ClusterName = roleGroupInfo.ClusterName
+				o.Labels = roleGroupInfo.GetLabels()
+				o.Annotations = roleGroupInfo.GetAnnotations()
+				o.RoleName = roleGroupInfo.RoleName
+				o.RoleGroupName = roleGroupInfo.GetFullName()
+			},
+		),
+		clusterConfig:  clusterConfig,
+		roleGroupInfo:  roleGroupInfo,
+		connectConfig:  connectConfig,
+		clientSecurity: clientSecurity,
+	}
+	return reconciler.NewStatefulSet(client, builder, stopped)
+}
+
+var _ builder.StatefulSetBuilder = &ConnectStatefulSetBuilder{}
+
+type ConnectStatefulSetBuilder struct {
+	builder.StatefulSet
+
+	clusterConfig  *kafkav1alpha1.KafkaConnectClusterConfigSpec
+	roleGroupInfo  *reconciler.RoleGroupInfo
+	connectConfig  *kafkav1alpha1.KafkaConnectConfigSpec
+	clientSecurity *security.ClientSecurity
+}
+
+func (b *ConnectStatefulSetBuilder) GetObject() (*appv1.StatefulSet, error) {
+	tpl, err := b.GetPodTemplate()
+	if err != nil {
+		return nil, err
+	}
+	obj := &appv1.StatefulSet{
+		ObjectMeta: b.GetObjectMeta(),
+		Spec: appv1.StatefulSetSpec{
+			Replicas:             b.GetReplicas(),
+			Selector:             b.GetLabelSelector(),
+			ServiceName:          b.GetName(),
+			Template:             *tpl,
+			VolumeClaimTemplates: b.GetVolumeClaimTemplates(),
+		},
+	}
+	return obj, nil
+}
+
+func (b *ConnectStatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	if len(b.clusterConfig.Plugins) > 0 {
+		b.AddInitContainer(b.createPluginsInitContainer())
+	}
+	b.AddContainer(b.createMainContainer())
+
+	restListenerPVC, err := util.NewListenerOperatorVolumeSourceBuilder(
+		&util.ListenerReference{
+			ListenerName: ConnectRestListenerName(b.roleGroupInfo),
+		}, b.GetLabels(),
+	).BuildPVC(kafkav1alpha1.KubedoopListenerRest)
+	if err != nil {
+		return nil, err
+	}
+	b.AddVolumeClaimTemplate(restListenerPVC)
+
+	b.AddVolumes(b.volumes())
+	b.AddVolumes(b.clientSecurity.Volumes(ConnectKerberosServiceName, b.connectConfig.RequestedSecretLifeTime))
+
+	// vector
+	if IsVectorEnable(b.connectConfig.Logging) {
+		vectorFactory := GetVectorFactory(b.GetImage())
+		b.AddContainer(vectorFactory.GetContainer())
+		b.AddVolumes(vectorFactory.GetVolumes())
+	}
+
+	sts, err := b.GetObject()
+	if err != nil {
+		return nil, err
+	}
+
+	sts.Spec.Template.Spec.ServiceAccountName = ServiceAccountName(b.ClusterName)
+	// parallel pod management
+	sts.Spec.PodManagementPolicy = appv1.ParallelPodManagement
+
+	return sts, nil
+}
+
+func (b *ConnectStatefulSetBuilder) createMainContainer() *corev1.Container {
+	return builder.NewContainerBuilder(ConnectRoleName, b.GetImage()).
+		AddEnvVars(b.containerEnv()).
+		SetCommand([]string{"sh", "-c"}).
+		SetArgs(b.commandArgs()).
+		AddVolumeMounts(b.volumeMounts()).
+		AddVolumeMounts(b.clientSecurity.VolumeMounts()).
+		SetResources(b.connectConfig.Resources).
+		SetReadinessProbe(&corev1.Probe{
+			FailureThreshold:    3,
+			InitialDelaySeconds: 20,
+			PeriodSeconds:       10,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      3,
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/connectors",
+					Port: intstr.FromString(kafkav1alpha1.ConnectRestPortName),
+				},
+			},
+		}).
+		SetLivenessProbe(&corev1.Probe{
+			FailureThreshold:    6,
+			InitialDelaySeconds: 30,
+			PeriodSeconds:       30,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      5,
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(kafkav1alpha1.ConnectRestPortName)},
+			},
+		}).
+		AddPorts(ConnectRestContainerPorts()).
+		Build()
+}
+
+func (b *ConnectStatefulSetBuilder) containerEnv() []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{
+			Name: EnvPodName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		},
+		{
+			Name: EnvKafkaBootstrapServers,
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: b.clusterConfig.KafkaClusterRef,
+					},
+					Key: KafkaDiscoveryKey,
+				},
+			},
+		},
+		{
+			Name:  EnvKafkaLog4jOpts,
+			Value: fmt.Sprintf("-Dlog4j.configuration=file:%s/%s", kafkav1alpha1.KubedoopLogConfigDir, Log4jPropertiesFilename),
+		},
+		{
+			Name:  EnvJvmArgs,
+			Value: fmt.Sprintf("-Djava.security.properties=%s/%s", kafkav1alpha1.KubedoopConfigDir, SecurityPropertiesFilename),
+		},
+	}
+	envs = append(envs, b.clientSecurity.Envs()...)
+
+	if resources := b.connectConfig.Resources; resources != nil && resources.Memory != nil {
+		heap := fmt.Sprintf("-Xmx%dm", int(util.QuantityToMB(resources.Memory.Limit)*0.8))
+		envs = append(envs, corev1.EnvVar{Name: EnvKafkaHeapOpts, Value: heap})
+	}
+	return envs
+}
+
+func (b *ConnectStatefulSetBuilder) commandArgs() []string {
+	podFqdn := util.PodFqdn(b.GetObjectMeta().Namespace, b.GetName())
+
+	var args []string
+	args = append(args, opgoutil.CommonBashTrapFunctions)
+	args = append(args, opgoutil.RemoveVectorShutdownFileCommand())
+	args = append(args, "prepare_signal_handlers")
+
+	if b.clientSecurity.IsKerberosEnabled() {
+		args = append(args, fmt.Sprintf("export KERBEROS_REALM=$(grep -oP 'default_realm = \\K.*' %s)", b.clientSecurity.Krb5Path()))
+	}
+
+	// bootstrap servers and the advertised host are only known at runtime
+	args = append(args, fmt.Sprintf("cp %s/%s %s", kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.ConnectDistributedFileName, connectRuntimePropertiesPath))
+	args = append(args, fmt.Sprintf("echo \"bootstrap.servers=${%s}\" >> %s", EnvKafkaBootstrapServers, connectRuntimePropertiesPath))
+	args = append(args, fmt.Sprintf("echo \"rest.advertised.host.name=%s\" >> %s", podFqdn, connectRuntimePropertiesPath))
+	if b.clientSecurity.IsKerberosEnabled() {
+		// the principal is passed as printf argument, so the shell expands the pod name and realm
+		jaasConfig := b.clientSecurity.KerberosJaasConfig("%s")
+		principal := fmt.Sprintf("%s/%s@${KERBEROS_REALM}", ConnectKerberosServiceName, podFqdn)
+		for _, prefix := range connectClientPrefixes {
+			args = append(args, fmt.Sprintf("printf '%ssasl.jaas.config=%s\\n' \"%s\" >> %s", prefix, jaasConfig, principal, connectRuntimePropertiesPath))
+		}
+	}
+
+	args = append(args, fmt.Sprintf("bin/connect-distributed.sh %s &", connectRuntimePropertiesPath))
+	args = append(args, opgoutil.InvokeWaitForTermination)
+	args = append(args, opgoutil.CreateVectorShutdownFileCommand())
+
+	return []string{strings.Join(args, "\n")}
+}
+
+func (b *ConnectStatefulSetBuilder) volumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      kafkav1alpha1.KubedoopConfigDirName,
+			MountPath: kafkav1alpha1.KubedoopConfigDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopLogDirName,
+			MountPath: kafkav1alpha1.KubedoopLogDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopLogConfigDirName,
+			MountPath: kafkav1alpha1.KubedoopLogConfigDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopConnectPluginsDirName,
+			MountPath: kafkav1alpha1.KubedoopConnectPluginsDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopListenerRest,
+			MountPath: kafkav1alpha1.KubedoopListenerRestDir,
+		},
+	}
+}
+
+func (b *ConnectStatefulSetBuilder) volumes() []corev1.Volume {
+	configMapVolumeSource := corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: RoleGroupConfigMapName(b.roleGroupInfo),
+			},
+		},
+	}
+	return []corev1.Volume{
+		{
+			Name:         kafkav1alpha1.KubedoopLogDirName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopLogConfigDirName,
+			VolumeSource: configMapVolumeSource,
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopConfigDirName,
+			VolumeSource: configMapVolumeSource,
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopConnectPluginsDirName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	}
+}
+
+// createPluginsInitContainer downloads the plugin artifacts into the plugin path before the worker starts
+func (b *ConnectStatefulSetBuilder) createPluginsInitContainer() *corev1.Container {
+	return builder.NewContainerBuilder(connectPluginsInitContainerName, b.GetImage()).
+		SetCommand([]string{"sh", "-c"}).
+		SetArgs([]string{ConnectPluginsInstallScript(b.clusterConfig.Plugins)}).
+		AddVolumeMounts([]corev1.VolumeMount{
+			{
+				Name:      kafkav1alpha1.KubedoopConnectPluginsDirName,
+				MountPath: kafkav1alpha1.KubedoopConnectPluginsDir,
+			},
+		}).
+		Build()
+}
+
+// ConnectPluginsInstallScript returns the shell script installing the plugins.
+// Every plugin gets its own directory, archives are extracted and other artifacts are copied as they are.
+func ConnectPluginsInstallScript(plugins []kafkav1alpha1.KafkaConnectPluginSpec) string {
+	args := []string{"set -euo pipefail"}
+	for _, plugin := range plugins {
+		pluginDir := ConnectPluginDir(plugin.Name)
+		args = append(args, fmt.Sprintf("mkdir -p %s", pluginDir))
+		for i, artifact := range plugin.Artifacts {
+			fileName := path.Base(artifact.URL)
+			download := fmt.Sprintf("/tmp/%s-%d-%s", plugin.Name, i, fileName)
+			args = append(args, fmt.Sprintf("curl --fail --silent --show-error --location --output %s '%s'", download, artifact.URL))
+			if artifact.Sha512Sum != "" {
+				args = append(args, fmt.Sprintf("echo '%s  %s' | sha512sum -c -", artifact.Sha512Sum, download))
+			}
+			switch {
+			case strings.HasSuffix(fileName, ".zip"):
+				args = append(args, fmt.Sprintf("unzip -o -q %s -d %s", download, pluginDir))
+			case strings.HasSuffix(fileName, ".tar.gz"), strings.HasSuffix(fileName, ".tgz"):
+				args = append(args, fmt.Sprintf("tar -xzf %s -C %s", download, pluginDir))
+			default:
+				args = append(args, fmt.Sprintf("cp %s %s/%s", download, pluginDir, fileName))
+			}
+			args = append(args, fmt.Sprintf("rm -f %s", download))
+		}
+	}
+	return strings.Join(args, "\n")
+}
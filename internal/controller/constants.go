@@ -9,6 +9,9 @@ const (
 const (
 	ZookeeperDiscoveryKey = "ZOOKEEPER"
 	NodePortFileName      = "kafka_nodeport"
+
+	// KafkaPidFile holds the pid of the broker JVM, the preStop hook signals it
+	KafkaPidFile = "/tmp/kafka.pid"
 )
 
 const (
@@ -16,7 +19,28 @@ const (
 	EnvZookeeperConnections = "ZOOKEEPER"
 	EnvKafkaLog4jOpts       = "KAFKA_LOG4J_OPTS"
 	EnvKafkaHeapOpts        = "KAFKA_HEAP_OPTS"
-	EnvNode                 = "NODE"
-	EnvNodePort             = "NODE_PORT"
-	EnvPodName              = "POD_NAME"
+	// EnvKafkaJvmPerformanceOpts holds the GC options of the Kafka start script
+	EnvKafkaJvmPerformanceOpts = "KAFKA_JVM_PERFORMANCE_OPTS"
+	EnvNode                    = "NODE"
+	EnvNodePort                = "NODE_PORT"
+	EnvPodName                 = "POD_NAME"
+	// set by the launch command
+	EnvBrokerId      = "BROKER_ID"
+	EnvBrokerRack    = "BROKER_RACK"
+	EnvKerberosRealm = "KERBEROS_REALM"
+	// EnvKafkaLogDir is the directory the Kafka start scripts write their own logs to
+	EnvKafkaLogDir = "LOG_DIR"
+	EnvKafkaOpts   = "KAFKA_OPTS"
+
+	EnvKafkaBootstrapServers = "KAFKA"
+)
+
+// system properties referenced by the principals in jaas.conf
+const (
+	JaasBrokerAddressProperty    = "kubedoop.kafka.broker.address"
+	JaasBootstrapAddressProperty = "kubedoop.kafka.bootstrap.address"
+	JaasKerberosRealmProperty    = "kubedoop.kafka.kerberos.realm"
 )
+
+// InterBrokerProtocolVersionProperty is pinned by the operator during upgrades, see upgrade.go
+const InterBrokerProtocolVersionProperty = "inter.broker.protocol.version"
@@ -14,16 +14,29 @@ import (
 	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
 )
 
+// startupProbeFailureThreshold allows the broker one hour to recover its logs with a period of 10s
+const startupProbeFailureThreshold = 360
+
 // ContainerComponent use for define container name
 type ContainerComponent string
 
 type KafkaContainerBuilder struct {
-	resourceSpec *commonsv1alpha1.ResourcesSpec
-
 	zookeeperDiscoveryZNode string
 	*security.KafkaSecurity
 	namespace    string
 	groupSvcName string
+	metrics      *kafkav1alpha1.MetricsSpec
+	// rack awareness, broker.rack is read from the rack annotation of the pod if set
+	rackAwareness *kafkav1alpha1.RackAwarenessSpec
+	// broker.id of the pod with ordinal 0, nil if the IDs are auto-generated by ZooKeeper
+	brokerIdOffset *int32
+	jvm            *JvmConfig
+	// env and cli overrides of the role group, validated by validateOverrides
+	overrides *commonsv1alpha1.OverridesSpec
+	// inter.broker.protocol.version, passed on the command line so changing it rolls the brokers
+	protocolVersion string
+	// whether a KafkaSnapshot with controlled shutdown can hold the broker, see KafkaSnapshotReconciler
+	snapshotHold bool
 }
 
 func NewKafkaContainer(
@@ -33,12 +46,26 @@ func NewKafkaContainer(
 	tlsSecurity *security.KafkaSecurity,
 	namespace string,
 	groupSvcName string,
+	metrics *kafkav1alpha1.MetricsSpec,
+	rackAwareness *kafkav1alpha1.RackAwarenessSpec,
+	brokerIdOffset *int32,
+	jvm *JvmConfig,
+	overrides *commonsv1alpha1.OverridesSpec,
+	protocolVersion string,
+	snapshotHold bool,
 ) *KafkaContainerBuilder {
 	return &KafkaContainerBuilder{
 		zookeeperDiscoveryZNode: zookeeperDiscoveryZNode,
 		KafkaSecurity:           tlsSecurity,
 		namespace:               namespace,
 		groupSvcName:            groupSvcName,
+		metrics:                 metrics,
+		rackAwareness:           rackAwareness,
+		brokerIdOffset:          brokerIdOffset,
+		jvm:                     jvm,
+		overrides:               overrides,
+		protocolVersion:         protocolVersion,
+		snapshotHold:            snapshotHold,
 	}
 }
 
@@ -75,24 +102,17 @@ func (d *KafkaContainerBuilder) ContainerEnv() []corev1.EnvVar {
 			Name:  EnvKafkaLog4jOpts,
 			Value: fmt.Sprintf("-Dlog4j.configuration=file:%s/%s", kafkav1alpha1.KubedoopLogConfigDir, kafkav1alpha1.Log4jFileName),
 		},
-		{
-			Name: EnvJvmArgs,
-			Value: fmt.Sprintf("-Djava.security.properties=%s/security.properties -javaagent:%s/jmx/jmx_prometheus_javaagent.jar=%d:%s/jmx/config.yaml",
-				kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.KubedoopRoot, kafkav1alpha1.MetricsPort, kafkav1alpha1.KubedoopRoot),
-		},
 	}
 
+	envs = append(envs, d.jvm.Env()...)
+
 	if d.IsKerberosEnabled() {
 		envs = append(envs, d.getKerbersoAuth().GetEnvs()...)
 	}
 
-	if d.resourceSpec != nil && d.resourceSpec.Memory != nil {
-		memoryLimit := d.resourceSpec.Memory.Limit
-		heap := fmt.Sprintf("-Xmx%dm", int(util.QuantityToMB(memoryLimit)*0.8))
-		envs = append(envs, corev1.EnvVar{
-			Name:  EnvKafkaHeapOpts,
-			Value: heap,
-		})
+	// overrides take precedence over the values set by the operator
+	if d.overrides != nil {
+		envs = applyEnvOverrides(envs, d.overrides.EnvOverrides)
 	}
 	return envs
 }
@@ -105,12 +125,6 @@ func (d *KafkaContainerBuilder) getKerbersoAuth() *security.KerberosAuthenticati
 	}
 }
 
-// create listener
-
-func (d *KafkaContainerBuilder) ResourceRequirements() corev1.ResourceRequirements {
-	return *util.ConvertToResourceRequirements(d.resourceSpec)
-}
-
 func (d *KafkaContainerBuilder) VolumeMount() []corev1.VolumeMount {
 	mounts := []corev1.VolumeMount{
 		{
@@ -141,6 +155,24 @@ func (d *KafkaContainerBuilder) VolumeMount() []corev1.VolumeMount {
 	if d.IsKerberosEnabled() {
 		mounts = append(mounts, d.getKerbersoAuth().GetVolumeMount()...)
 	}
+	if IsMetricsTlsEnabled(d.metrics) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      kafkav1alpha1.KubedoopMetricsTlsName,
+			MountPath: kafkav1alpha1.KubedoopMetricsTlsDir,
+		})
+	}
+	if d.rackAwareness != nil {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      kafkav1alpha1.KubedoopRackDirName,
+			MountPath: kafkav1alpha1.KubedoopRackDir,
+		})
+	}
+	if d.snapshotHold {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      kafkav1alpha1.KubedoopSnapshotHoldDirName,
+			MountPath: kafkav1alpha1.KubedoopSnapshotHoldDir,
+		})
+	}
 	return mounts
 }
 
@@ -157,22 +189,66 @@ func (d *KafkaContainerBuilder) LivenessProbe() *corev1.Probe {
 	}
 }
 
+// ReadinessProbe checks the broker answers a metadata request listing itself,
+// so it is only ready once it registered and serves requests
 func (d *KafkaContainerBuilder) ReadinessProbe() *corev1.Probe {
 	return &corev1.Probe{
-		FailureThreshold:    3,
-		InitialDelaySeconds: 20,
-		PeriodSeconds:       30,
-		SuccessThreshold:    1,
-		TimeoutSeconds:      1,
-		ProbeHandler: corev1.ProbeHandler{
-			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(d.ClientPortName())},
+		FailureThreshold: 3,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		TimeoutSeconds:   5,
+		ProbeHandler:     d.brokerRegisteredProbeHandler(),
+	}
+}
+
+// StartupProbe runs the readiness check until the broker finished recovering its logs,
+// which may take long after an unclean shutdown. The liveness probe only starts afterwards.
+func (d *KafkaContainerBuilder) StartupProbe() *corev1.Probe {
+	return &corev1.Probe{
+		FailureThreshold: startupProbeFailureThreshold,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		TimeoutSeconds:   5,
+		ProbeHandler:     d.brokerRegisteredProbeHandler(),
+	}
+}
+
+func (d *KafkaContainerBuilder) brokerRegisteredProbeHandler() corev1.ProbeHandler {
+	kcat := strings.Join(d.KcatProberContainerCommands(), " ")
+	// an auto-generated ID is only known from the data the broker wrote
+	var brokerId string
+	if d.brokerIdOffset != nil {
+		brokerId = fmt.Sprintf("$((${%s##*-} + %d))", EnvPodName, *d.brokerIdOffset)
+	} else {
+		var serverProperties map[string]string
+		if d.overrides != nil {
+			serverProperties = d.overrides.ConfigOverrides[ServerPropertiesFilename]
+		}
+		brokerId = fmt.Sprintf("$(sed -n 's/^broker.id=//p' %s)", metaPropertiesFile(serverProperties))
+	}
+	check := fmt.Sprintf(`%s | grep -q "broker %s at"`, kcat, brokerId)
+	return corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{Command: []string{"sh", "-c", check}},
+	}
+}
+
+// Lifecycle returns the preStop hook triggering the controlled shutdown of the broker.
+// Kafka moves the leadership of its partitions to other brokers before the JVM exits,
+// the hook blocks until then so the pod is only removed afterwards.
+func (d *KafkaContainerBuilder) Lifecycle() *corev1.Lifecycle {
+	preStop := fmt.Sprintf(`pid=$(cat %s 2>/dev/null) || exit 0
+kill -TERM "$pid" 2>/dev/null || exit 0
+while kill -0 "$pid" 2>/dev/null; do sleep 1; done`, KafkaPidFile)
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"sh", "-c", preStop}},
 		},
 	}
 }
 
 // ContainerPorts  make container ports of data node
 func (d *KafkaContainerBuilder) ContainerPorts() []corev1.ContainerPort {
-	return KafkaContainerPorts(d.KafkaSecurity)
+	return append(KafkaContainerPorts(d.KafkaSecurity), MetricsContainerPorts(d.metrics)...)
 }
 
 func (d *KafkaContainerBuilder) Command() []string {
@@ -202,11 +278,32 @@ func (d *KafkaContainerBuilder) CommandArgs() []string {
 
 	// kerberos set real env
 	if d.IsKerberosEnabled() {
-		args = append(args, fmt.Sprintf("export KERBEROS_REALM=$(grep -oP 'default_realm = \\K.*' %s)", kafkav1alpha1.KubedoopKerberosKrb5Path))
+		args = append(args, fmt.Sprintf("export %s=$(grep -oP 'default_realm = \\K.*' %s)", EnvKerberosRealm, kafkav1alpha1.KubedoopKerberosKrb5Path))
+	}
+
+	// the operator annotates the pod with the rack of its node once it is scheduled. The broker starts without a rack
+	// if it is not set in time, e.g. the node has no label or the operator does not reconcile the cluster.
+	if d.rackAwareness != nil {
+		rackFile := fmt.Sprintf("%s/%s", kafkav1alpha1.KubedoopRackDir, kafkav1alpha1.RackFileName)
+		args = append(args, fmt.Sprintf(`rack_wait=0
+while [ ! -s %[1]s ] && [ -z "${term_kill_needed}" ] && [ "${rack_wait}" -lt %[2]d ]; do echo "Waiting for the rack of the node"; sleep 2; rack_wait=$((rack_wait + 2)); done
+if [ -s %[1]s ]; then export %[3]s=$(cat %[1]s); else echo "No rack assigned after %[2]ds, starting without a rack"; fi`,
+			rackFile, int(rackWaitTimeout.Seconds()), EnvBrokerRack))
+	}
+
+	// the operator holds the broker while a KafkaSnapshot snapshots its data volume, see KafkaSnapshotReconciler.
+	// The hold is watched in the background, it stops the broker, which waits for the release after the restart.
+	if d.snapshotHold {
+		holdFile := fmt.Sprintf("%s/%s", kafkav1alpha1.KubedoopSnapshotHoldDir, kafkav1alpha1.SnapshotHoldFileName)
+		args = append(args, fmt.Sprintf(`while [ -s %s ] && [ -z "${term_kill_needed}" ]; do echo "Waiting for the volume snapshot"; sleep 2; done
+(while [ ! -s %s ]; do sleep 5; done; echo "Stopping the broker for a volume snapshot"; kill -TERM "$(cat %s)") &`,
+			holdFile, holdFile, KafkaPidFile))
 	}
 
 	args = append(args, d.LaunchCommand(listeners, advertisedListers, lisenerSecurityProtocolMap))
-	args = append(args, "wait_for_termination")
+	args = append(args, fmt.Sprintf("echo $! > %s", KafkaPidFile))
+	// forward SIGTERM to the JVM and wait until it exited, so it gets the whole grace period for the controlled shutdown
+	args = append(args, opgputil.InvokeWaitForTermination)
 	// create vector shut down file command
 	args = append(args, opgputil.CreateVectorShutdownFileCommand())
 
@@ -215,15 +312,38 @@ func (d *KafkaContainerBuilder) CommandArgs() []string {
 
 // kafka launch command
 func (d *KafkaContainerBuilder) LaunchCommand(listeners, advertisedListers, lisenerSecurityProtocolMap string) string {
-	cmds := fmt.Sprintf(`bin/kafka-server-start.sh %s/%s --override "zookeeper.connect=${ZOOKEEPER}" --override "listeners=%s" --override "advertised.listeners=%s" --override "listener.security.protocol.map=%s" `,
-		kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.ServerFileName, listeners, advertisedListers, lisenerSecurityProtocolMap)
+	// the broker id is the offset of the role group plus the pod ordinal, or auto-generated by ZooKeeper without one
+	var cmds, brokerIdOverride string
+	if d.brokerIdOffset != nil {
+		cmds = fmt.Sprintf("export %s=$((${%s##*-} + %d))\n", EnvBrokerId, EnvPodName, *d.brokerIdOffset)
+		brokerIdOverride = fmt.Sprintf(`--override "broker.id=${%s}" `, EnvBrokerId)
+	}
 
+	// the principals in jaas.conf refer to the listener addresses and the realm, only known at runtime
 	if d.IsKerberosEnabled() {
-		serviceName := d.getKerbersoAuth().Role.KerberosServiceName()
-		brokerAddress := util.NodeAddressCmd(kafkav1alpha1.KubedoopListenerBrokerDir)
-		bootstrapAddress := util.NodeAddressCmd(kafkav1alpha1.KubedoopListenerBootstrapDir)
-		kerberosArgs := fmt.Sprintf(" --override \"listener.name.client.gssapi.sasl.jaas.config=com.sun.security.auth.module.Krb5LoginModule required useKeyTab=true storeKey=true isInitiator=false keyTab=\\\"/kubedoop/kerberos/keytab\\\" principal=\\\"%s/%s@$KERBEROS_REALM\\\";\" --override \"listener.name.bootstrap.gssapi.sasl.jaas.config=com.sun.security.auth.module.Krb5LoginModule required useKeyTab=true storeKey=true isInitiator=false keyTab=\\\"/kubedoop/kerberos/keytab\\\" principal=\\\"%s/%s@$KERBEROS_REALM\\\";\"", serviceName, brokerAddress, serviceName, bootstrapAddress)
-		cmds = cmds + kerberosArgs
+		cmds += fmt.Sprintf(`export %s="${%s} -Djava.security.auth.login.config=%s/%s -D%s=%s -D%s=%s -D%s=${%s}"`+"\n",
+			EnvKafkaOpts, EnvKafkaOpts,
+			kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.JaasFileName,
+			JaasBrokerAddressProperty, util.NodeAddressCmd(kafkav1alpha1.KubedoopListenerBrokerDir),
+			JaasBootstrapAddressProperty, util.NodeAddressCmd(kafkav1alpha1.KubedoopListenerBootstrapDir),
+			JaasKerberosRealmProperty, EnvKerberosRealm)
+	}
+
+	cmds += fmt.Sprintf(`bin/kafka-server-start.sh %s/%s %s--override "zookeeper.connect=${%s}" --override "listeners=%s" --override "advertised.listeners=%s" --override "listener.security.protocol.map=%s" `,
+		kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.ServerFileName, brokerIdOverride, EnvZookeeperConnections, listeners, advertisedListers, lisenerSecurityProtocolMap)
+
+	// broker.rack is left unset if the broker gave up waiting for its rack
+	if d.rackAwareness != nil {
+		cmds += fmt.Sprintf(`${%[1]s:+--override "broker.rack=${%[1]s}"} `, EnvBrokerRack)
 	}
+
+	if d.protocolVersion != "" {
+		cmds += fmt.Sprintf(`--override "%s=%s" `, InterBrokerProtocolVersionProperty, d.protocolVersion)
+	}
+
+	if d.overrides != nil {
+		cmds += cliOverrideArgs(d.overrides.CliOverrides)
+	}
+
 	return cmds + " &"
 }
@@ -3,67 +3,84 @@ package controller
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/metrics"
 	"github.com/zncdatadev/kafka-operator/internal/security"
 	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/builder"
 	"github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	KafkaDiscoveryKey = "KAFKA"
 
-	LabelListenerBootstrap = "app.kubernetes.io/listener-bootstrap"
-	LabelValueTrue         = "true"
+	LabelListenerBootstrap      = "app.kubernetes.io/listener-bootstrap"
+	LabelListenerBootstrapClass = "app.kubernetes.io/listener-bootstrap-class"
+	LabelValueTrue              = "true"
 )
 
+// DiscoveryConfigMapName returns the name of the discovery ConfigMap published for
+// the given bootstrap ListenerClass, formatted as `<clusterName>-<listenerClass>-discovery`.
+// The suffix keeps it apart from the other resources of the cluster, e.g. the role group ConfigMaps.
+func DiscoveryConfigMapName(clusterName, listenerClass string) string {
+	return clusterName + "-" + listenerClass + "-discovery"
+}
+
+// legacyNodePortDiscoveryConfigMapName returns the name of the NodePort discovery ConfigMap of earlier operator versions
+func legacyNodePortDiscoveryConfigMapName(clusterName string) string {
+	return clusterName + "-nodeport"
+}
+
 type DiscoveryBuilder struct {
 	builder.ConfigMapBuilder
 	kafkaSecurity *security.KafkaSecurity
-	isNodePort    bool
+	listenerClass string
 }
 
+// NewKafkaDiscoveryReconciler creates a reconciler for a discovery ConfigMap named `name`,
+// containing the bootstrap servers of all bootstrap Listeners of this cluster with the given ListenerClass.
 func NewKafkaDiscoveryReconciler(
 	ctx context.Context,
 	client *client.Client,
 	kafkaTlsSecurity *security.KafkaSecurity,
+	name string,
+	listenerClass string,
 ) reconciler.ResourceReconciler[builder.ConfigBuilder] {
-	builder := NewKafkaDiscoveryBuilder(client, kafkaTlsSecurity, false)
-	return reconciler.NewGenericResourceReconciler(client, builder)
-}
-
-func NewKafkaDiscoveryNodePortReconciler(
-	ctx context.Context,
-	client *client.Client,
-	kafkaTlsSecurity *security.KafkaSecurity,
-) reconciler.ResourceReconciler[builder.ConfigBuilder] {
-	builder := NewKafkaDiscoveryBuilder(client, kafkaTlsSecurity, true)
+	builder := NewKafkaDiscoveryBuilder(client, kafkaTlsSecurity, name, listenerClass)
 	return reconciler.NewGenericResourceReconciler(client, builder)
 }
 
 func NewKafkaDiscoveryBuilder(
 	client *client.Client,
 	kafkaSecurity *security.KafkaSecurity,
-	isNodePort bool,
+	name string,
+	listenerClass string,
 ) builder.ConfigBuilder {
-	name := client.GetOwnerName()
-	if isNodePort {
-		name = fmt.Sprintf("%s-nodeport", name)
-	}
-
 	return &DiscoveryBuilder{
 		ConfigMapBuilder: *builder.NewConfigMapBuilder(
 			client,
 			name,
 			func(o *builder.Options) {
-				o.Labels = client.OwnerReference.GetLabels()
+				// the instance and ListenerClass labels find the discovery ConfigMaps of ListenerClasses no longer used
+				o.Labels = map[string]string{opconstants.LabelKubernetesManagedBy: opconstants.KubedoopDomain}
+				maps.Copy(o.Labels, client.OwnerReference.GetLabels())
+				o.Labels[opconstants.LabelKubernetesInstance] = client.GetOwnerName()
+				o.Labels[LabelListenerBootstrapClass] = listenerClass
 			},
 		),
 		kafkaSecurity: kafkaSecurity,
-		isNodePort:    isNodePort,
+		listenerClass: listenerClass,
 	}
 }
 
@@ -77,7 +94,12 @@ func (b *DiscoveryBuilder) Build(ctx context.Context) (ctrlclient.Object, error)
 	err := b.Client.Client.List(
 		ctx,
 		listenerList,
-		ctrlclient.MatchingLabels{LabelListenerBootstrap: LabelValueTrue},
+		ctrlclient.InNamespace(b.Client.GetOwnerNamespace()),
+		ctrlclient.MatchingLabels{
+			LabelListenerBootstrap:              LabelValueTrue,
+			LabelListenerBootstrapClass:         b.listenerClass,
+			opconstants.LabelKubernetesInstance: b.Client.GetOwnerName(),
+		},
 	)
 	if err != nil {
 		return nil, err
@@ -87,19 +109,51 @@ func (b *DiscoveryBuilder) Build(ctx context.Context) (ctrlclient.Object, error)
 	if err != nil {
 		return nil, err
 	}
+	b.trackListenerAddresses(listenerList)
 
 	bootstrapServers := b.makeBootstrapServers(hosts)
 	b.AddItem(KafkaDiscoveryKey, bootstrapServers)
 
+	if cluster, ok := b.Client.OwnerReference.(*kafkav1alpha1.KafkaCluster); ok && cluster.Spec.ClusterConfig.RackAwareness != nil {
+		racks, err := BrokerRacks(ctx, b.Client.Client, b.Client.GetOwnerNamespace(), b.Client.GetOwnerName())
+		if err != nil {
+			return nil, err
+		}
+		if len(racks) > 0 {
+			b.AddItem(KafkaRacksDiscoveryKey, strings.Join(racks, ","))
+		}
+	}
+
 	return b.GetObject(), nil
 }
 
+// trackListenerAddresses measures the time until all bootstrap Listeners got an address
+func (b *DiscoveryBuilder) trackListenerAddresses(listenerList *listenerv1alpha1.ListenerList) {
+	key := b.Client.GetOwnerNamespace() + "/" + b.GetName()
+	pending := len(listenerList.Items) == 0
+	for _, listener := range listenerList.Items {
+		if len(listener.Status.IngressAddresses) == 0 {
+			pending = true
+		}
+	}
+	if pending {
+		metrics.ListenerAddressesPending(key)
+	} else {
+		metrics.ListenerAddressesReady(key, b.listenerClass)
+	}
+}
+
 type HostPort struct {
 	Host string
 	Port int32
 }
 
 func (b *DiscoveryBuilder) listenerHosts(listenerList *listenerv1alpha1.ListenerList, portName string) ([]HostPort, error) {
+	// sort listeners by name, so the bootstrap servers are stable between reconciles
+	slices.SortFunc(listenerList.Items, func(a, b listenerv1alpha1.Listener) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
 	var result []HostPort
 	for _, listener := range listenerList.Items {
 		// TODO: Status refactor to user pointer
@@ -109,7 +163,7 @@ func (b *DiscoveryBuilder) listenerHosts(listenerList *listenerv1alpha1.Listener
 		for _, addr := range listener.Status.IngressAddresses {
 			port, ok := addr.Ports[portName]
 			if !ok {
-				return nil, &Error{msg: fmt.Sprintf("no service port with name %s", portName)}
+				return nil, fmt.Errorf("%w %s", ErrListenerPortMissing, portName)
 			}
 			result = append(result, HostPort{
 				Host: addr.Address,
@@ -128,14 +182,75 @@ func (b *DiscoveryBuilder) makeBootstrapServers(hosts []HostPort) string {
 	return strings.Join(servers, ",")
 }
 
-type Error struct {
-	msg string
-	err error
+var _ reconciler.Reconciler = &StaleDiscoveryReconciler{}
+
+// StaleDiscoveryReconciler deletes the discovery ConfigMaps of the cluster that are no longer published,
+// e.g. of a bootstrap ListenerClass no longer used or the NodePort discovery ConfigMap of earlier operator versions.
+// Clients reading them would connect to stale addresses.
+type StaleDiscoveryReconciler struct {
+	client *client.Client
+	names  []string
+}
+
+// NewStaleDiscoveryReconciler creates a reconciler keeping the discovery ConfigMaps with the given names
+func NewStaleDiscoveryReconciler(client *client.Client, names []string) *StaleDiscoveryReconciler {
+	return &StaleDiscoveryReconciler{client: client, names: names}
+}
+
+func (r *StaleDiscoveryReconciler) GetName() string {
+	return r.client.GetOwnerName() + "-stale-discovery"
 }
 
-func (e *Error) Error() string {
-	if e.err != nil {
-		return fmt.Sprintf("%s: %v", e.msg, e.err)
+func (r *StaleDiscoveryReconciler) GetNamespace() string {
+	return r.client.GetOwnerNamespace()
+}
+
+func (r *StaleDiscoveryReconciler) GetClient() *client.Client {
+	return r.client
+}
+
+func (r *StaleDiscoveryReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.client.Client.List(
+		ctx,
+		configMaps,
+		ctrlclient.InNamespace(r.GetNamespace()),
+		ctrlclient.HasLabels{LabelListenerBootstrapClass},
+		ctrlclient.MatchingLabels{opconstants.LabelKubernetesInstance: r.client.GetOwnerName()},
+	); err != nil {
+		return ctrl.Result{}, err
 	}
-	return e.msg
+	stale := make([]*corev1.ConfigMap, 0, len(configMaps.Items)+1)
+	for i := range configMaps.Items {
+		if !slices.Contains(r.names, configMaps.Items[i].Name) {
+			stale = append(stale, &configMaps.Items[i])
+		}
+	}
+
+	// the NodePort discovery ConfigMap of earlier operator versions has no ListenerClass label
+	legacy := &corev1.ConfigMap{}
+	legacyName := legacyNodePortDiscoveryConfigMapName(r.client.GetOwnerName())
+	if err := r.client.GetWithOwnerNamespace(ctx, legacyName, legacy); err == nil {
+		if !slices.Contains(r.names, legacyName) {
+			stale = append(stale, legacy)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	for _, cm := range stale {
+		// only ConfigMaps created by the operator for this cluster
+		if !metav1.IsControlledBy(cm, r.client.OwnerReference) {
+			continue
+		}
+		logger.Info("Deleting stale discovery ConfigMap", "configMap", cm.Name, "namespace", cm.Namespace)
+		if err := ctrlclient.IgnoreNotFound(r.client.Client.Delete(ctx, cm)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *StaleDiscoveryReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
 }
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/zncdatadev/kafka-operator/internal/catalog"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+	"github.com/zncdatadev/kafka-operator/internal/metrics"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+)
+
+// Event reasons
+const (
+	EventReasonCreated        = "Created"
+	EventReasonScaled         = "Scaled"
+	EventReasonRollingRestart = "RollingRestart"
+	EventReasonReconcileError = "ReconcileFailed"
+
+	EventReasonZookeeperConfigMapMissing = "ZookeeperConfigMapMissing"
+	EventReasonVectorAggregatorNotSet    = "VectorAggregatorNotSet"
+	EventReasonListenerPortMissing       = "ListenerPortMissing"
+	EventReasonRackLabelMissing          = "RackLabelMissing"
+	EventReasonBrokerIdConflict          = "BrokerIdConflict"
+	EventReasonInvalidOverride           = "InvalidOverride"
+	EventReasonUnsupportedVersion        = "UnsupportedVersion"
+	EventReasonDeprecatedProperty        = "DeprecatedProperty"
+	EventReasonNoReplicationMargin       = "NoReplicationMargin"
+	EventReasonUpgraded                  = "Upgraded"
+	EventReasonDowngradeRejected         = "DowngradeRejected"
+	EventReasonReconciliationPaused      = "ReconciliationPaused"
+	EventReasonStopped                   = "Stopped"
+)
+
+var (
+	ErrZookeeperConfigMapMissing = errors.New("zookeeper discovery ConfigMap not found")
+	ErrVectorAggregatorNotSet    = errors.New("vector is enabled but vectorAggregatorConfigMapName is not set")
+	ErrListenerPortMissing       = errors.New("no service port with name")
+	ErrBrokerIdConflict          = errors.New("broker ID ranges conflict")
+	ErrInvalidOverride           = errors.New("invalid override")
+	ErrDowngradeNotAllowed       = errors.New("downgrade not allowed")
+)
+
+// ErrorEventReason returns the event reason of the error class of err
+func ErrorEventReason(err error) string {
+	switch {
+	case errors.Is(err, ErrZookeeperConfigMapMissing):
+		return EventReasonZookeeperConfigMapMissing
+	case errors.Is(err, ErrVectorAggregatorNotSet):
+		return EventReasonVectorAggregatorNotSet
+	case errors.Is(err, ErrListenerPortMissing):
+		return EventReasonListenerPortMissing
+	case errors.Is(err, ErrBrokerIdConflict):
+		return EventReasonBrokerIdConflict
+	case errors.Is(err, ErrInvalidOverride):
+		return EventReasonInvalidOverride
+	case errors.Is(err, catalog.ErrUnsupportedVersion):
+		return EventReasonUnsupportedVersion
+	case errors.Is(err, ErrDowngradeNotAllowed):
+		return EventReasonDowngradeRejected
+	default:
+		return EventReasonReconcileError
+	}
+}
+
+var _ reconciler.Reconciler = &EventReconciler{}
+
+// EventReconciler emits events on the owner for significant changes of the resource of the wrapped reconciler:
+// its creation and, for StatefulSets, scaling and pod template changes rolling the pods.
+type EventReconciler struct {
+	reconciler.Reconciler
+	recorder *event.Recorder
+	// newObject returns an empty object of the kind of the resource
+	newObject func() ctrlclient.Object
+}
+
+func NewEventReconciler(recorder *event.Recorder, newObject func() ctrlclient.Object, r reconciler.Reconciler) reconciler.Reconciler {
+	return &EventReconciler{Reconciler: r, recorder: recorder, newObject: newObject}
+}
+
+func (r *EventReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	if r.recorder == nil {
+		return r.Reconciler.Reconcile(ctx)
+	}
+
+	before, err := r.get(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	result, err := r.Reconciler.Reconcile(ctx)
+	if err != nil {
+		return result, err
+	}
+	after, err := r.get(ctx)
+	if err != nil || after == nil {
+		return result, err
+	}
+
+	owner := r.GetClient().GetOwnerReference()
+	kind := after.GetObjectKind().GroupVersionKind().Kind
+	if before == nil {
+		r.recorder.Normal(owner, EventReasonCreated, "Create", "Created %s %s", kind, after.GetName())
+		return result, nil
+	}
+
+	if oldSts, ok := before.(*appv1.StatefulSet); ok {
+		newSts := after.(*appv1.StatefulSet)
+		if oldReplicas, newReplicas := replicas(oldSts), replicas(newSts); oldReplicas != newReplicas {
+			r.recorder.Normal(owner, EventReasonScaled, "Scale", "Scaled StatefulSet %s from %d to %d replicas", newSts.Name, oldReplicas, newReplicas)
+		}
+		if !equality.Semantic.DeepEqual(oldSts.Spec.Template, newSts.Spec.Template) {
+			r.recorder.Normal(owner, EventReasonRollingRestart, "Update", "Pod template of StatefulSet %s changed, rolling the pods", newSts.Name)
+		}
+	}
+	return result, nil
+}
+
+// get returns the current resource, nil if it does not exist
+func (r *EventReconciler) get(ctx context.Context) (ctrlclient.Object, error) {
+	obj := r.newObject()
+	key := ctrlclient.ObjectKey{Namespace: r.GetNamespace(), Name: r.GetName()}
+	if err := r.GetClient().Client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// the typed client clears the type meta
+	if gvks, _, err := r.GetClient().GetCtrlScheme().ObjectKinds(obj); err == nil && len(gvks) > 0 {
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	return obj, nil
+}
+
+// observeResource instruments the reconciler of a resource with operator metrics and events
+func observeResource(recorder *event.Recorder, resource string, newObject func() ctrlclient.Object, r reconciler.Reconciler) reconciler.Reconciler {
+	return metrics.Instrument(resource, NewEventReconciler(recorder, newObject, r))
+}
+
+func newService() ctrlclient.Object     { return &corev1.Service{} }
+func newConfigMap() ctrlclient.Object   { return &corev1.ConfigMap{} }
+func newStatefulSet() ctrlclient.Object { return &appv1.StatefulSet{} }
+func newListener() ctrlclient.Object    { return &listenerv1alpha1.Listener{} }
+
+func newPodDisruptionBudget() ctrlclient.Object { return &policyv1.PodDisruptionBudget{} }
+
+func replicas(sts *appv1.StatefulSet) int32 {
+	if sts.Spec.Replicas == nil {
+		return 1
+	}
+	return *sts.Spec.Replicas
+}
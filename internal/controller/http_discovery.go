@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HttpDiscoveryBuilder builds a discovery ConfigMap containing the HTTP endpoints of the Listeners
+// of the owner labeled with `listenerLabel`, as comma separated `http://host:port` URLs.
+type HttpDiscoveryBuilder struct {
+	builder.ConfigMapBuilder
+
+	listenerLabel string
+	portName      string
+	key           string
+}
+
+func NewHttpDiscoveryBuilder(
+	client *client.Client,
+	name string,
+	listenerLabel string,
+	portName string,
+	key string,
+) builder.ConfigBuilder {
+	return &HttpDiscoveryBuilder{
+		ConfigMapBuilder: *builder.NewConfigMapBuilder(
+			client,
+			name,
+			func(o *builder.Options) {
+				o.Labels = client.OwnerReference.GetLabels()
+			},
+		),
+		listenerLabel: listenerLabel,
+		portName:      portName,
+		key:           key,
+	}
+}
+
+func (b *HttpDiscoveryBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	listenerList := &listenerv1alpha1.ListenerList{}
+	err := b.Client.Client.List(
+		ctx,
+		listenerList,
+		ctrlclient.InNamespace(b.Client.GetOwnerNamespace()),
+		ctrlclient.MatchingLabels{
+			b.listenerLabel:                     LabelValueTrue,
+			opconstants.LabelKubernetesInstance: b.Client.GetOwnerName(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(listenerList.Items, func(a, b listenerv1alpha1.Listener) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var urls []string
+	for _, listener := range listenerList.Items {
+		for _, addr := range listener.Status.IngressAddresses {
+			port, ok := addr.Ports[b.portName]
+			if !ok {
+				return nil, fmt.Errorf("%w %s", ErrListenerPortMissing, b.portName)
+			}
+			urls = append(urls, fmt.Sprintf("http://%s:%d", addr.Address, port))
+		}
+	}
+
+	b.AddItem(b.key, strings.Join(urls, ","))
+	return b.GetObject(), nil
+}
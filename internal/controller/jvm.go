@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/util"
+)
+
+const mebibyte = 1024 * 1024
+
+// JvmConfig holds the effective JVM settings of the brokers of a role group
+type JvmConfig struct {
+	// HeapOpts is KAFKA_HEAP_OPTS, empty without a memory limit so the script default applies
+	HeapOpts string
+	// ExtraArgs is EXTRA_ARGS
+	ExtraArgs []string
+	// PerformanceOpts is KAFKA_JVM_PERFORMANCE_OPTS, empty to keep the script defaults
+	PerformanceOpts string
+}
+
+// KafkaJvmArgs returns the JVM arguments set by the operator, the JMX exporter is only started if metrics are enabled
+func KafkaJvmArgs(metrics *kafkav1alpha1.MetricsSpec) []string {
+	args := []string{fmt.Sprintf("-Djava.security.properties=%s/%s", kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.SecurityFileName)}
+	if IsMetricsEnabled(metrics) {
+		args = append(args, JmxExporterJavaAgent(metrics))
+	}
+	return args
+}
+
+// NewJvmConfig computes the JVM settings from the jvm section, the memory limit and the arguments set by the operator
+func NewJvmConfig(jvm *kafkav1alpha1.JvmSpec, resources *commonsv1alpha1.ResourcesSpec, operatorArgs []string) (*JvmConfig, error) {
+	if jvm == nil {
+		jvm = defaultJvmSpec()
+	}
+	config := &JvmConfig{PerformanceOpts: strings.Join(jvm.GcOptions, " ")}
+
+	if resources != nil && resources.Memory != nil && !resources.Memory.Limit.IsZero() {
+		limit := resources.Memory.Limit
+		heap, err := util.MemoryShare(jvm.Heap, limit)
+		if err != nil {
+			return nil, err
+		}
+		reservation, err := util.MemoryShare(jvm.PageCacheReservation, limit)
+		if err != nil {
+			return nil, err
+		}
+		heap = min(heap, limit.Value()-reservation)
+		if heap < mebibyte {
+			return nil, fmt.Errorf("memory limit %s leaves no heap with heap %s and page cache reservation %s",
+				limit.String(), jvm.Heap, jvm.PageCacheReservation)
+		}
+		config.HeapOpts = fmt.Sprintf("-Xms%[1]dm -Xmx%[1]dm", heap/mebibyte)
+	}
+
+	removeRegex := make([]*regexp.Regexp, 0, len(jvm.RemoveArgsRegex))
+	for _, expr := range jvm.RemoveArgsRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jvm removeArgsRegex %q: %w", expr, err)
+		}
+		removeRegex = append(removeRegex, re)
+	}
+	for _, arg := range operatorArgs {
+		if slices.Contains(jvm.RemoveArgs, arg) || slices.ContainsFunc(removeRegex, func(re *regexp.Regexp) bool { return re.MatchString(arg) }) {
+			continue
+		}
+		config.ExtraArgs = append(config.ExtraArgs, arg)
+	}
+	config.ExtraArgs = append(config.ExtraArgs, jvm.ExtraArgs...)
+	return config, nil
+}
+
+// Env returns the environment variables read by the Kafka start script
+func (c *JvmConfig) Env() []corev1.EnvVar {
+	envs := []corev1.EnvVar{{Name: EnvJvmArgs, Value: strings.Join(c.ExtraArgs, " ")}}
+	if c.HeapOpts != "" {
+		envs = append(envs, corev1.EnvVar{Name: EnvKafkaHeapOpts, Value: c.HeapOpts})
+	}
+	if c.PerformanceOpts != "" {
+		envs = append(envs, corev1.EnvVar{Name: EnvKafkaJvmPerformanceOpts, Value: c.PerformanceOpts})
+	}
+	return envs
+}
+
+// String renders the environment variables as shell assignments, documenting them in the role group ConfigMap
+func (c *JvmConfig) String() string {
+	var sb strings.Builder
+	sb.WriteString("# Effective JVM settings of the brokers, for reference only. Unset variables keep the Kafka defaults.\n")
+	for _, env := range c.Env() {
+		fmt.Fprintf(&sb, "%s=%q\n", env.Name, env.Value)
+	}
+	return sb.String()
+}
+
+func defaultJvmSpec() *kafkav1alpha1.JvmSpec {
+	return &kafkav1alpha1.JvmSpec{
+		Heap:                 "50%",
+		PageCacheReservation: "25%",
+	}
+}
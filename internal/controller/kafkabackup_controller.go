@@ -0,0 +1,336 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/backup"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+const (
+	ReasonKafkaClusterNotFound = "KafkaClusterNotFound"
+	ReasonKerberosNotSupported = "KerberosNotSupported"
+	ReasonBackupPending        = "BackupPending"
+	ReasonBackupSucceeded      = "BackupSucceeded"
+	ReasonBackupFailed         = "BackupFailed"
+
+	EventReasonBackupCompleted = "BackupCompleted"
+	EventReasonBackupFailed    = "BackupFailed"
+
+	kafkaClusterNotFoundInterval = 30 * time.Second
+	defaultBackupHistoryLimit    = 5
+	snapshotTimeFormat           = "20060102-150405"
+)
+
+// KafkaBackupReconciler reconciles a KafkaBackup object
+type KafkaBackupReconciler struct {
+	ctrlclient.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder *event.Recorder
+
+	// PodLogs reads the dumps printed by the backup Jobs
+	PodLogs PodLogsFunc
+}
+
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkabackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkabackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkabackups/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile runs the backup Jobs of the cluster, once or on a schedule, and stores the snapshot printed by
+// every succeeded Job in the target. Snapshots beyond the history limit are deleted.
+func (r *KafkaBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger.V(1).Info("Reconciling KafkaBackup")
+
+	instance := &kafkav1alpha1.KafkaBackup{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			logger.V(1).Info("KafkaBackup not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	status := instance.Status.DeepCopy()
+	status.ObservedGeneration = instance.Generation
+
+	kafkaCluster := &kafkav1alpha1.KafkaCluster{}
+	kafkaClusterKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterRef}
+	if err := r.Get(ctx, kafkaClusterKey, kafkaCluster); err != nil {
+		if ctrlclient.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		setBackupReady(status, metav1.ConditionFalse, ReasonKafkaClusterNotFound, fmt.Sprintf("KafkaCluster %s not found", kafkaClusterKey))
+		return ctrl.Result{RequeueAfter: kafkaClusterNotFoundInterval}, r.updateStatus(ctx, instance, status)
+	}
+
+	clientSecurity := security.NewKafkaSecurity(kafkaCluster).Client()
+	if clientSecurity.IsKerberosEnabled() {
+		setBackupReady(status, metav1.ConditionFalse, ReasonKerberosNotSupported,
+			fmt.Sprintf("KafkaCluster %s uses Kerberos authentication, which is not supported by backups", kafkaClusterKey))
+		return ctrl.Result{}, r.updateStatus(ctx, instance, status)
+	}
+
+	jobSpec := NewBackupJobSpec(kafkaCluster, clientSecurity, backup.DumpScript(BackupCommandConfig), "")
+	if err := r.reconcileJobs(ctx, instance, jobSpec); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	store, err := newBackupStore(ctx, r.Client, instance.Namespace, &instance.Spec.Target)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.collect(ctx, instance, kafkaCluster, store, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.prune(ctx, instance, store, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if meta.FindStatusCondition(status.Conditions, kafkav1alpha1.ConditionTypeBackupReady) == nil {
+		setBackupReady(status, metav1.ConditionUnknown, ReasonBackupPending, "no backup finished yet")
+	}
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.V(1).Info("Reconcile finished.", "backup", instance.Name, "namespace", instance.Namespace, "snapshots", len(status.Snapshots))
+	return ctrl.Result{}, nil
+}
+
+// reconcileJobs creates the CronJob of a scheduled backup, or the Job of the current generation of a one-off backup
+func (r *KafkaBackupReconciler) reconcileJobs(ctx context.Context, instance *kafkav1alpha1.KafkaBackup, jobSpec batchv1.JobSpec) error {
+	labels := map[string]string{kafkav1alpha1.BackupLabel: instance.Name}
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}}
+
+	if instance.Spec.Schedule == "" {
+		if err := ctrlclient.IgnoreNotFound(r.Delete(ctx, cronJob)); err != nil {
+			return err
+		}
+
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", instance.Name, instance.Generation),
+				Namespace: instance.Namespace,
+				Labels:    labels,
+			},
+			Spec: jobSpec,
+		}
+		err := r.Get(ctx, ctrlclient.ObjectKeyFromObject(job), &batchv1.Job{})
+		if !apierrors.IsNotFound(err) {
+			// the Job of this generation exists already
+			return err
+		}
+		if err := ctrl.SetControllerReference(instance, job, r.Scheme); err != nil {
+			return err
+		}
+		logger.Info("Creating backup job", "job", job.Name, "namespace", job.Namespace)
+		return r.Create(ctx, job)
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, cronJob, func() error {
+		cronJob.Labels = labels
+		cronJob.Spec.Schedule = instance.Spec.Schedule
+		cronJob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+		cronJob.Spec.JobTemplate = batchv1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec:       jobSpec,
+		}
+		return ctrl.SetControllerReference(instance, cronJob, r.Scheme)
+	})
+	return err
+}
+
+// collect stores the snapshots of the finished Jobs not processed yet, oldest first
+func (r *KafkaBackupReconciler) collect(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaBackup,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	store backup.Store,
+	status *kafkav1alpha1.KafkaBackupStatus,
+) error {
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, ctrlclient.InNamespace(instance.Namespace), ctrlclient.MatchingLabels{kafkav1alpha1.BackupLabel: instance.Name}); err != nil {
+		return err
+	}
+	slices.SortFunc(jobs.Items, func(a, b batchv1.Job) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if _, processed := job.Annotations[BackupResultAnnotation]; processed {
+			continue
+		}
+		finished, failed, message := jobFinished(job)
+		if !finished {
+			continue
+		}
+
+		result := backupResultFailed
+		if failed {
+			setBackupReady(status, metav1.ConditionFalse, ReasonBackupFailed, fmt.Sprintf("job %s failed: %s", job.Name, message))
+			r.Recorder.Warning(instance, EventReasonBackupFailed, "Backup", "Backup job %s failed: %s", job.Name, message)
+		} else {
+			snapshot, err := r.store(ctx, job, kafkaCluster, store)
+			if err != nil {
+				return err
+			}
+			result = snapshot.Name
+			if !slices.ContainsFunc(status.Snapshots, func(s kafkav1alpha1.BackupSnapshotStatus) bool { return s.Name == snapshot.Name }) {
+				status.Snapshots = append(status.Snapshots, *snapshot)
+			}
+			status.LastBackupTime = &snapshot.Time
+			setBackupReady(status, metav1.ConditionTrue, ReasonBackupSucceeded, fmt.Sprintf("snapshot %s stored", snapshot.Name))
+			r.Recorder.Normal(instance, EventReasonBackupCompleted, "Backup", "Snapshot %s stored", snapshot.Name)
+		}
+
+		patch := ctrlclient.MergeFrom(job.DeepCopy())
+		metav1.SetMetaDataAnnotation(&job.ObjectMeta, BackupResultAnnotation, result)
+		if err := r.Patch(ctx, job, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// store parses the dump printed by a succeeded Job and puts the snapshot into the store
+func (r *KafkaBackupReconciler) store(
+	ctx context.Context,
+	job *batchv1.Job,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	store backup.Store,
+) (*kafkav1alpha1.BackupSnapshotStatus, error) {
+	logs, err := jobLogs(ctx, r.Client, r.PodLogs, job)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := backup.ParseDump(kafkaCluster.Name, logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the dump of job %s: %w", job.Name, err)
+	}
+
+	completionTime := job.CreationTimestamp
+	if job.Status.CompletionTime != nil {
+		completionTime = *job.Status.CompletionTime
+	}
+	snapshot.CreatedAt = completionTime.UTC()
+	data, err := snapshot.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%s", job.Labels[kafkav1alpha1.BackupLabel], completionTime.UTC().Format(snapshotTimeFormat))
+	if err := store.Put(ctx, name, data); err != nil {
+		return nil, fmt.Errorf("failed to store snapshot %s: %w", name, err)
+	}
+	logger.Info("Snapshot stored", "snapshot", name, "job", job.Name, "namespace", job.Namespace)
+
+	return &kafkav1alpha1.BackupSnapshotStatus{
+		Name:           name,
+		Time:           completionTime,
+		Topics:         int32(len(snapshot.Topics)),
+		ACLs:           int32(len(snapshot.ACLs)),
+		Users:          int32(len(snapshot.Users)),
+		ConsumerGroups: int32(len(snapshot.ConsumerGroups)),
+	}, nil
+}
+
+// prune deletes the oldest snapshots beyond the history limit
+func (r *KafkaBackupReconciler) prune(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaBackup,
+	store backup.Store,
+	status *kafkav1alpha1.KafkaBackupStatus,
+) error {
+	limit := defaultBackupHistoryLimit
+	if instance.Spec.HistoryLimit != nil {
+		limit = int(*instance.Spec.HistoryLimit)
+	}
+	for len(status.Snapshots) > limit {
+		name := status.Snapshots[0].Name
+		if err := store.Delete(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete snapshot %s: %w", name, err)
+		}
+		logger.Info("Snapshot deleted", "snapshot", name, "backup", instance.Name, "namespace", instance.Namespace)
+		status.Snapshots = status.Snapshots[1:]
+	}
+	return nil
+}
+
+func (r *KafkaBackupReconciler) updateStatus(ctx context.Context, instance *kafkav1alpha1.KafkaBackup, status *kafkav1alpha1.KafkaBackupStatus) error {
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.Status().Update(ctx, instance)
+}
+
+func setBackupReady(status *kafkav1alpha1.KafkaBackupStatus, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               kafkav1alpha1.ConditionTypeBackupReady,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: status.ObservedGeneration,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Jobs created by the CronJob are not owned by the backup, they are mapped to it by their label.
+func (r *KafkaBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1alpha1.KafkaBackup{}).
+		Owns(&batchv1.CronJob{}).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj ctrlclient.Object) []reconcile.Request {
+			name, ok := obj.GetLabels()[kafkav1alpha1.BackupLabel]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+		})).
+		Complete(r)
+}
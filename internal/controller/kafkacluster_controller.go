@@ -18,16 +18,28 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/catalog"
+	"github.com/zncdatadev/kafka-operator/internal/event"
 	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 )
 
@@ -36,6 +48,9 @@ type KafkaClusterReconciler struct {
 	ctrlclient.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+
+	// Recorder emits events on the KafkaCluster, events are dropped if nil
+	Recorder *event.Recorder
 }
 
 // +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaclusters,verbs=get;list;watch;create;update;patch;delete
@@ -47,8 +62,14 @@ type KafkaClusterReconciler struct {
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkasnapshots,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=listeners.kubedoop.dev,resources=listeners,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -74,6 +95,48 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 	logger.V(1).Info("KafkaCluster found", "namespace", instance.Namespace, "name", instance.Name)
 
+	result, err := r.reconcile(ctx, instance)
+	if err != nil {
+		r.Recorder.Warning(instance, ErrorEventReason(err), "Reconcile", "%s", err.Error())
+	}
+	return result, err
+}
+
+func (r *KafkaClusterReconciler) reconcile(ctx context.Context, instance *kafkav1alpha1.KafkaCluster) (ctrl.Result, error) {
+	// a paused cluster is reconciled again once the spec changed
+	if paused, err := r.checkClusterOperation(ctx, instance); err != nil || paused {
+		return ctrl.Result{}, err
+	}
+
+	// brokers wait for their rack before starting, so assign it even if the other resources are not reconciled yet
+	unlabeledNodes, err := AssignRacks(ctx, r.Client, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(unlabeledNodes) > 0 {
+		r.Recorder.Warning(instance, EventReasonRackLabelMissing, "AssignRack", "Nodes %s have no label %s, their brokers start without a rack after %s",
+			strings.Join(unlabeledNodes, ","), instance.Spec.ClusterConfig.RackAwareness.NodeLabel, rackWaitTimeout)
+	}
+
+	// an unsupported version is not retried, the cluster is reconciled again once the spec changed
+	if supported, err := r.checkProductVersion(ctx, instance); err != nil || !supported {
+		return ctrl.Result{}, err
+	}
+
+	// the brokers can not start without the ZooKeeper connection string
+	if err := r.checkZookeeperConfigMap(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// a rejected downgrade is not retried, the cluster is reconciled again once the spec changed
+	protocolVersion, err := r.reconcileUpgrade(ctx, instance)
+	if errors.Is(err, ErrDowngradeNotAllowed) {
+		r.Recorder.Warning(instance, EventReasonDowngradeRejected, "Upgrade", "%s", err.Error())
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	resourceClient := &client.Client{
 		Client:         r.Client,
 		OwnerReference: instance,
@@ -92,12 +155,19 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			ClusterName: instance.Name,
 		},
 		&instance.Spec,
+		r.Recorder,
+		protocolVersion,
 	)
 
 	if err := clusterReconciler.RegisterResources(ctx); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// persist the broker IDs before the pods use them, so the offsets picked by the operator stay stable
+	if err := r.updateBrokerIds(ctx, instance, clusterReconciler); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if result, err := clusterReconciler.Reconcile(ctx); err != nil {
 		return ctrl.Result{}, err
 	} else if !result.IsZero() {
@@ -112,14 +182,139 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return result, nil
 	}
 
+	// the upgrade moves to its next phase once the brokers of the current one are rolled out
+	if result, err := r.advanceUpgrade(ctx, instance); err != nil || !result.IsZero() {
+		return result, err
+	}
+
 	logger.V(1).Info("Reconcile finished.", "cluster", instance.Name, "namespace", instance.Namespace)
 
 	return ctrl.Result{}, nil
 }
 
+// checkProductVersion reflects in the VersionSupported condition whether the version catalog supports the product version
+func (r *KafkaClusterReconciler) checkProductVersion(ctx context.Context, instance *kafkav1alpha1.KafkaCluster) (bool, error) {
+	productVersion := NewImage(instance.Spec.Image).ProductVersion
+	condition := metav1.Condition{
+		Type:               kafkav1alpha1.ConditionTypeVersionSupported,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Supported",
+		Message:            fmt.Sprintf("Kafka %s is supported", productVersion),
+		ObservedGeneration: instance.Generation,
+	}
+	kafkaVersion, err := catalog.Lookup(productVersion)
+	switch {
+	case err != nil:
+	case !kafkaVersion.Zookeeper:
+		err = fmt.Errorf("%w: Kafka %s only runs in KRaft mode, the brokers require ZooKeeper", catalog.ErrUnsupportedVersion, productVersion)
+	case kafkaVersion.LogType != productlogging.LogTypeLog4j:
+		// the logging configuration of the brokers is only generated for Log4j
+		err = fmt.Errorf("%w: Kafka %s does not log with Log4j, the brokers require it", catalog.ErrUnsupportedVersion, productVersion)
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = EventReasonUnsupportedVersion
+		condition.Message = err.Error()
+		r.Recorder.Warning(instance, EventReasonUnsupportedVersion, "Reconcile", "%s", err.Error())
+	}
+
+	if apimeta.SetStatusCondition(&instance.Status.Conditions, condition) {
+		if err := r.Status().Update(ctx, instance); err != nil {
+			return false, err
+		}
+	}
+	return condition.Status == metav1.ConditionTrue, nil
+}
+
+// checkClusterOperation reflects the cluster operation in the ReconciliationPaused and Stopped conditions.
+// It returns whether the reconciliation is paused, only the status of a paused cluster is written.
+func (r *KafkaClusterReconciler) checkClusterOperation(ctx context.Context, instance *kafkav1alpha1.KafkaCluster) (bool, error) {
+	operation := instance.Spec.ClusterOperation
+	paused := operation != nil && operation.ReconciliationPaused
+	stopped := operation != nil && operation.Stopped
+	wasPaused := apimeta.IsStatusConditionTrue(instance.Status.Conditions, kafkav1alpha1.ConditionTypeReconciliationPaused)
+	wasStopped := apimeta.IsStatusConditionTrue(instance.Status.Conditions, kafkav1alpha1.ConditionTypeStopped)
+
+	pausedCondition := metav1.Condition{
+		Type:               kafkav1alpha1.ConditionTypeReconciliationPaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Reconciling",
+		Message:            "The resources of the cluster are reconciled",
+		ObservedGeneration: instance.Generation,
+	}
+	if paused {
+		pausedCondition.Status = metav1.ConditionTrue
+		pausedCondition.Reason = EventReasonReconciliationPaused
+		pausedCondition.Message = "Reconciliation is paused, no resource of the cluster is changed"
+	}
+	changed := apimeta.SetStatusCondition(&instance.Status.Conditions, pausedCondition)
+
+	// the role groups of a paused cluster are not scaled, Stopped keeps reflecting the last reconciliation
+	if !paused {
+		stoppedCondition := metav1.Condition{
+			Type:               kafkav1alpha1.ConditionTypeStopped,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Running",
+			Message:            "The role groups run their configured replicas",
+			ObservedGeneration: instance.Generation,
+		}
+		if stopped {
+			stoppedCondition.Status = metav1.ConditionTrue
+			stoppedCondition.Reason = EventReasonStopped
+			stoppedCondition.Message = "The role groups are scaled to zero, their volumes, listeners and discovery are kept"
+		}
+		changed = apimeta.SetStatusCondition(&instance.Status.Conditions, stoppedCondition) || changed
+	}
+
+	if changed {
+		if err := r.Status().Update(ctx, instance); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case paused && !wasPaused:
+		r.Recorder.Normal(instance, EventReasonReconciliationPaused, "Reconcile", "Reconciliation paused")
+	case !paused && wasPaused:
+		r.Recorder.Normal(instance, EventReasonReconciliationPaused, "Reconcile", "Reconciliation resumed")
+	}
+	switch {
+	case !paused && stopped && !wasStopped:
+		r.Recorder.Normal(instance, EventReasonStopped, "Stop", "Stopping the cluster, scaling the role groups to zero")
+	case !paused && !stopped && wasStopped:
+		r.Recorder.Normal(instance, EventReasonStopped, "Start", "Starting the cluster, scaling the role groups to their replicas")
+	}
+	return paused, nil
+}
+
+func (r *KafkaClusterReconciler) checkZookeeperConfigMap(ctx context.Context, instance *kafkav1alpha1.KafkaCluster) error {
+	name := instance.Spec.ClusterConfig.ZookeeperConfigMapName
+	if err := r.Get(ctx, ctrlclient.ObjectKey{Namespace: instance.Namespace, Name: name}, &corev1.ConfigMap{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %s", ErrZookeeperConfigMapMissing, name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *KafkaClusterReconciler) updateBrokerIds(ctx context.Context, instance *kafkav1alpha1.KafkaCluster, clusterReconciler *Reconciler) error {
+	newStatus := instance.Status.DeepCopy()
+	newStatus.BrokerIdOffsets = clusterReconciler.BrokerIdOffsets()
+	newStatus.BrokerIds = clusterReconciler.BrokerIds()
+	if equality.Semantic.DeepEqual(&instance.Status, newStatus) {
+		return nil
+	}
+	instance.Status = *newStatus
+	return r.Status().Update(ctx, instance)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KafkaClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kafkav1alpha1.KafkaCluster{}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(brokerPodToCluster), builder.WithPredicates(podScheduled)).
+		Watches(&kafkav1alpha1.KafkaSnapshot{}, handler.EnqueueRequestsFromMapFunc(controlledShutdownSnapshotToCluster),
+			builder.WithPredicates(snapshotCreatedOrDeleted)).
 		Complete(r)
 }
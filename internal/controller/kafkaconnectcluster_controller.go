@@ -0,0 +1,120 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+)
+
+// KafkaConnectClusterReconciler reconciles a KafkaConnectCluster object
+type KafkaConnectClusterReconciler struct {
+	ctrlclient.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectclusters,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectclusters/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaclusters,verbs=get;list;watch
+
+// Reconcile deploys the Connect workers of a KafkaConnectCluster against the KafkaCluster
+// referenced by `clusterConfig.kafkaClusterRef`.
+func (r *KafkaConnectClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger.V(1).Info("Reconciling KafkaConnectCluster")
+
+	instance := &kafkav1alpha1.KafkaConnectCluster{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			logger.V(1).Info("KafkaConnectCluster not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	logger.V(1).Info("KafkaConnectCluster found", "namespace", instance.Namespace, "name", instance.Name)
+
+	kafkaCluster := &kafkav1alpha1.KafkaCluster{}
+	kafkaClusterKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterConfig.KafkaClusterRef}
+	if err := r.Get(ctx, kafkaClusterKey, kafkaCluster); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get referenced KafkaCluster %s: %w", kafkaClusterKey, err)
+	}
+
+	resourceClient := &client.Client{
+		Client:         r.Client,
+		OwnerReference: instance,
+	}
+
+	gvk := instance.GetObjectKind().GroupVersionKind()
+
+	clusterReconciler := NewConnectClusterReconciler(
+		resourceClient,
+		reconciler.ClusterInfo{
+			GVK: &metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+			ClusterName: instance.Name,
+		},
+		&instance.Spec,
+		kafkaCluster,
+	)
+
+	if err := clusterReconciler.RegisterResources(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if result, err := clusterReconciler.Reconcile(ctx); err != nil {
+		return ctrl.Result{}, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	logger.Info("Connect cluster resource reconciled, checking if ready.", "cluster", instance.Name, "namespace", instance.Namespace)
+
+	if result, err := clusterReconciler.Ready(ctx); err != nil {
+		return ctrl.Result{}, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	logger.V(1).Info("Reconcile finished.", "cluster", instance.Name, "namespace", instance.Namespace)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KafkaConnectClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1alpha1.KafkaConnectCluster{}).
+		Complete(r)
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/connect"
+)
+
+const (
+	ReasonConnectClusterNotFound = "ConnectClusterNotFound"
+	ReasonConnectAPIError        = "ConnectAPIError"
+
+	connectClusterNotFoundInterval = 30 * time.Second
+)
+
+// KafkaConnectorReconciler reconciles a KafkaConnector object
+type KafkaConnectorReconciler struct {
+	ctrlclient.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	// NewConnectClient returns a client of the REST API of the KafkaConnectCluster.
+	// Defaults to the first REST endpoint published in the discovery ConfigMap of the cluster.
+	NewConnectClient func(ctx context.Context, connectCluster *kafkav1alpha1.KafkaConnectCluster) (*connect.Client, error)
+}
+
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectors/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaconnectclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile manages the connector on the Connect cluster through the Connect REST API.
+// The connector is polled periodically, to surface its state and restart failed tasks.
+func (r *KafkaConnectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger.V(1).Info("Reconciling KafkaConnector")
+
+	instance := &kafkav1alpha1.KafkaConnector{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			logger.V(1).Info("KafkaConnector not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	connectCluster := &kafkav1alpha1.KafkaConnectCluster{}
+	connectClusterKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ConnectClusterRef}
+	err := r.Get(ctx, connectClusterKey, connectCluster)
+	if err != nil && ctrlclient.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	connectClusterFound := err == nil
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, instance, connectCluster, connectClusterFound)
+	}
+
+	if controllerutil.AddFinalizer(instance, kafkav1alpha1.KafkaConnectorFinalizer) {
+		if err := r.Update(ctx, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	status := instance.Status.DeepCopy()
+	status.ObservedGeneration = instance.Generation
+
+	if !connectClusterFound {
+		setConnectorNotReady(status, ReasonConnectClusterNotFound, fmt.Sprintf("KafkaConnectCluster %s not found", connectClusterKey))
+		return ctrl.Result{RequeueAfter: connectClusterNotFoundInterval}, r.updateStatus(ctx, instance, status)
+	}
+
+	requeueAfter, syncErr := r.sync(ctx, instance, connectCluster, status)
+	if syncErr != nil {
+		setConnectorNotReady(status, ReasonConnectAPIError, syncErr.Error())
+	}
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	if syncErr != nil {
+		return ctrl.Result{}, syncErr
+	}
+
+	logger.V(1).Info("Reconcile finished.", "connector", instance.Name, "namespace", instance.Namespace, "state", status.ConnectorState)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *KafkaConnectorReconciler) sync(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaConnector,
+	connectCluster *kafkav1alpha1.KafkaConnectCluster,
+	status *kafkav1alpha1.KafkaConnectorStatus,
+) (time.Duration, error) {
+	config, err := r.connectorConfig(ctx, instance)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := r.newConnectClient(ctx, connectCluster)
+	if err != nil {
+		return 0, err
+	}
+
+	connector := &connect.Connector{
+		Name:        instance.Name,
+		Config:      config,
+		State:       instance.Spec.State,
+		AutoRestart: true,
+	}
+	if autoRestart := instance.Spec.AutoRestart; autoRestart != nil {
+		connector.AutoRestart = autoRestart.Enabled == nil || *autoRestart.Enabled
+		connector.MaxRestarts = autoRestart.MaxRestarts
+	}
+
+	return connect.NewSyncer(client).Sync(ctx, connector, status)
+}
+
+// finalize deletes the connector from the Connect cluster and removes the finalizer
+func (r *KafkaConnectorReconciler) finalize(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaConnector,
+	connectCluster *kafkav1alpha1.KafkaConnectCluster,
+	connectClusterFound bool,
+) error {
+	if !controllerutil.ContainsFinalizer(instance, kafkav1alpha1.KafkaConnectorFinalizer) {
+		return nil
+	}
+
+	// nothing to clean up if the Connect cluster is gone
+	if connectClusterFound && connectCluster.DeletionTimestamp.IsZero() {
+		client, err := r.newConnectClient(ctx, connectCluster)
+		if err != nil {
+			return err
+		}
+		if err := client.DeleteConnector(ctx, instance.Name); err != nil && !connect.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Connector deleted", "connector", instance.Name, "namespace", instance.Namespace)
+	}
+
+	controllerutil.RemoveFinalizer(instance, kafkav1alpha1.KafkaConnectorFinalizer)
+	return r.Update(ctx, instance)
+}
+
+// connectorConfig returns the complete connector configuration, including the values read from Secrets
+func (r *KafkaConnectorReconciler) connectorConfig(ctx context.Context, instance *kafkav1alpha1.KafkaConnector) (map[string]string, error) {
+	config := maps.Clone(instance.Spec.Config)
+	if config == nil {
+		config = map[string]string{}
+	}
+	config["connector.class"] = instance.Spec.Class
+	if instance.Spec.TasksMax != nil {
+		config["tasks.max"] = fmt.Sprint(*instance.Spec.TasksMax)
+	}
+
+	for _, secretConfig := range instance.Spec.SecretConfig {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: instance.Namespace, Name: secretConfig.SecretKeyRef.Name}
+		if err := r.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get secret %s for connector config %s: %w", key, secretConfig.Key, err)
+		}
+		value, ok := secret.Data[secretConfig.SecretKeyRef.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret %s has no key %s for connector config %s", key, secretConfig.SecretKeyRef.Key, secretConfig.Key)
+		}
+		config[secretConfig.Key] = string(value)
+	}
+	return config, nil
+}
+
+func (r *KafkaConnectorReconciler) newConnectClient(ctx context.Context, connectCluster *kafkav1alpha1.KafkaConnectCluster) (*connect.Client, error) {
+	if r.NewConnectClient != nil {
+		return r.NewConnectClient(ctx, connectCluster)
+	}
+
+	discovery := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: connectCluster.Namespace, Name: connectCluster.Name}
+	if err := r.Get(ctx, key, discovery); err != nil {
+		return nil, fmt.Errorf("failed to get discovery configmap of KafkaConnectCluster %s: %w", key, err)
+	}
+	url, _, _ := strings.Cut(discovery.Data[KafkaConnectDiscoveryKey], ",")
+	if url == "" {
+		return nil, fmt.Errorf("REST API of KafkaConnectCluster %s is not published yet", key)
+	}
+	return connect.NewClient(url, nil), nil
+}
+
+func (r *KafkaConnectorReconciler) updateStatus(ctx context.Context, instance *kafkav1alpha1.KafkaConnector, status *kafkav1alpha1.KafkaConnectorStatus) error {
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.Status().Update(ctx, instance)
+}
+
+func setConnectorNotReady(status *kafkav1alpha1.KafkaConnectorStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               kafkav1alpha1.ConditionTypeConnectorReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: status.ObservedGeneration,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KafkaConnectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1alpha1.KafkaConnector{}).
+		Complete(r)
+}
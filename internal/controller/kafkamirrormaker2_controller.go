@@ -0,0 +1,255 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/connect"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+)
+
+const (
+	// ConditionTypeReplicationLag reports whether the replication lag could be scraped from the workers
+	ConditionTypeReplicationLag = "ReplicationLagAvailable"
+
+	mirrorMaker2ScrapeInterval = time.Minute
+	mirrorMaker2ScrapeTimeout  = 10 * time.Second
+)
+
+// KafkaMirrorMaker2Reconciler reconciles a KafkaMirrorMaker2 object
+type KafkaMirrorMaker2Reconciler struct {
+	ctrlclient.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	// HTTPClient is used to scrape the metrics of the workers, defaults to a client with a short timeout
+	HTTPClient *http.Client
+}
+
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkamirrormaker2s,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkamirrormaker2s/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkamirrormaker2s/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+
+// Reconcile deploys the MirrorMaker2 workers replicating from the source to the target cluster.
+// Once the workers are ready, the replication lag is scraped from their metrics periodically.
+func (r *KafkaMirrorMaker2Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger.V(1).Info("Reconciling KafkaMirrorMaker2")
+
+	instance := &kafkav1alpha1.KafkaMirrorMaker2{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			logger.V(1).Info("KafkaMirrorMaker2 not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	logger.V(1).Info("KafkaMirrorMaker2 found", "namespace", instance.Namespace, "name", instance.Name)
+
+	clusterConfig := instance.Spec.ClusterConfig
+	source, err := r.resolveCluster(ctx, instance.Namespace, "source", clusterConfig.Source)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	target, err := r.resolveCluster(ctx, instance.Namespace, "target", clusterConfig.Target)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	resourceClient := &client.Client{
+		Client:         r.Client,
+		OwnerReference: instance,
+	}
+
+	gvk := instance.GetObjectKind().GroupVersionKind()
+
+	clusterReconciler := NewMirrorMaker2ClusterReconciler(
+		resourceClient,
+		reconciler.ClusterInfo{
+			GVK: &metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+			ClusterName: instance.Name,
+		},
+		&instance.Spec,
+		source,
+		target,
+	)
+
+	if err := clusterReconciler.RegisterResources(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if result, err := clusterReconciler.Reconcile(ctx); err != nil {
+		return ctrl.Result{}, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	logger.Info("MirrorMaker2 resource reconciled, checking if ready.", "cluster", instance.Name, "namespace", instance.Namespace)
+
+	newStatus := instance.Status.DeepCopy()
+	newStatus.Generation = instance.Generation
+
+	if result, err := clusterReconciler.Ready(ctx); err != nil {
+		return ctrl.Result{}, err
+	} else if !result.IsZero() {
+		newStatus.SetStatusCondition(metav1.Condition{
+			Type:    status.ConditionTypeAvailable,
+			Status:  metav1.ConditionFalse,
+			Reason:  status.ConditionReasonPreparing,
+			Message: "Waiting for the workers to be ready",
+		})
+		if err := r.updateStatus(ctx, instance, newStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+		return result, nil
+	}
+
+	newStatus.SetStatusCondition(metav1.Condition{
+		Type:    status.ConditionTypeAvailable,
+		Status:  metav1.ConditionTrue,
+		Reason:  status.ConditionReasonRunning,
+		Message: "The workers are ready",
+	})
+	r.scrapeReplicationLag(ctx, instance, newStatus)
+	if err := r.updateStatus(ctx, instance, newStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.V(1).Info("Reconcile finished.", "cluster", instance.Name, "namespace", instance.Namespace)
+
+	return ctrl.Result{RequeueAfter: mirrorMaker2ScrapeInterval}, nil
+}
+
+// resolveCluster fetches the KafkaCluster referenced by a source or target cluster
+func (r *KafkaMirrorMaker2Reconciler) resolveCluster(
+	ctx context.Context,
+	namespace string,
+	name string,
+	spec *kafkav1alpha1.MirrorMaker2ClusterSpec,
+) (*MirrorMaker2Cluster, error) {
+	if spec.KafkaClusterRef == "" {
+		return NewMirrorMaker2Cluster(name, spec, nil), nil
+	}
+
+	kafkaCluster := &kafkav1alpha1.KafkaCluster{}
+	kafkaClusterKey := types.NamespacedName{Namespace: namespace, Name: spec.KafkaClusterRef}
+	if err := r.Get(ctx, kafkaClusterKey, kafkaCluster); err != nil {
+		return nil, fmt.Errorf("failed to get %s KafkaCluster %s: %w", name, kafkaClusterKey, err)
+	}
+	return NewMirrorMaker2Cluster(name, spec, kafkaCluster), nil
+}
+
+// scrapeReplicationLag writes the replication lag of the running workers to the status.
+// Failures are reported in a condition, the last scraped values are kept.
+func (r *KafkaMirrorMaker2Reconciler) scrapeReplicationLag(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaMirrorMaker2,
+	newStatus *kafkav1alpha1.KafkaMirrorMaker2Status,
+) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, ctrlclient.InNamespace(instance.Namespace), ctrlclient.MatchingLabels{
+		constants.LabelKubernetesInstance:  instance.Name,
+		constants.LabelKubernetesComponent: MirrorMaker2RoleName,
+	}); err != nil {
+		setReplicationLagCondition(newStatus, metav1.ConditionFalse, status.ConditionReasonFail, err.Error())
+		return
+	}
+
+	var urls []string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			urls = append(urls, fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, kafkav1alpha1.MetricsPort))
+		}
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: mirrorMaker2ScrapeTimeout}
+	}
+	lags, err := connect.ScrapeMirrorMetrics(ctx, httpClient, urls)
+	if err != nil {
+		logger.Error(err, "Failed to scrape the replication lag", "cluster", instance.Name, "namespace", instance.Namespace)
+		setReplicationLagCondition(newStatus, metav1.ConditionFalse, status.ConditionReasonFail, err.Error())
+		return
+	}
+
+	newStatus.Replication = nil
+	for _, topic := range connect.AggregateTopicLag(lags) {
+		newStatus.Replication = append(newStatus.Replication, kafkav1alpha1.MirrorMaker2TopicReplicationStatus{
+			Topic:                topic.Topic,
+			Partitions:           topic.Partitions,
+			ReplicationLatencyMs: topic.ReplicationLatencyMs,
+			RecordAgeMs:          topic.RecordAgeMs,
+		})
+	}
+	newStatus.LastScrapeTime = &metav1.Time{Time: time.Now()}
+	setReplicationLagCondition(newStatus, metav1.ConditionTrue, status.ConditionReasonReady,
+		fmt.Sprintf("Replication lag of %d topics scraped from %d workers", len(newStatus.Replication), len(urls)))
+}
+
+func setReplicationLagCondition(newStatus *kafkav1alpha1.KafkaMirrorMaker2Status, conditionStatus metav1.ConditionStatus, reason, message string) {
+	newStatus.SetStatusCondition(metav1.Condition{
+		Type:    ConditionTypeReplicationLag,
+		Status:  conditionStatus,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+func (r *KafkaMirrorMaker2Reconciler) updateStatus(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaMirrorMaker2,
+	newStatus *kafkav1alpha1.KafkaMirrorMaker2Status,
+) error {
+	if equality.Semantic.DeepEqual(&instance.Status, newStatus) {
+		return nil
+	}
+	instance.Status = *newStatus
+	return r.Status().Update(ctx, instance)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KafkaMirrorMaker2Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1alpha1.KafkaMirrorMaker2{}).
+		Complete(r)
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/backup"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+const (
+	EventReasonRestoreCompleted = "RestoreCompleted"
+	EventReasonRestoreFailed    = "RestoreFailed"
+)
+
+// KafkaRestoreReconciler reconciles a KafkaRestore object
+type KafkaRestoreReconciler struct {
+	ctrlclient.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder *event.Recorder
+
+	// PodLogs reads the dump of the cluster printed by the inspect Job
+	PodLogs PodLogsFunc
+}
+
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkarestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkarestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkarestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile replays a snapshot into the cluster once it is available. A first Job dumps the metadata of the
+// cluster, so the conflicts with the snapshot are resolved by the operator, a second Job runs the restore.
+func (r *KafkaRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger.V(1).Info("Reconciling KafkaRestore")
+
+	instance := &kafkav1alpha1.KafkaRestore{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			logger.V(1).Info("KafkaRestore not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	status := instance.Status.DeepCopy()
+	switch status.Phase {
+	case kafkav1alpha1.RestorePhaseSucceeded, kafkav1alpha1.RestorePhaseFailed:
+		return ctrl.Result{}, nil
+	case "":
+		now := metav1.Now()
+		status.Phase = kafkav1alpha1.RestorePhasePending
+		status.StartTime = &now
+	}
+	status.ObservedGeneration = instance.Generation
+
+	kafkaCluster := &kafkav1alpha1.KafkaCluster{}
+	kafkaClusterKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterRef}
+	if err := r.Get(ctx, kafkaClusterKey, kafkaCluster); err != nil {
+		if ctrlclient.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		status.Message = fmt.Sprintf("KafkaCluster %s not found", kafkaClusterKey)
+		return ctrl.Result{RequeueAfter: kafkaClusterNotFoundInterval}, r.updateStatus(ctx, instance, status)
+	}
+
+	clientSecurity := security.NewKafkaSecurity(kafkaCluster).Client()
+	if clientSecurity.IsKerberosEnabled() {
+		r.fail(instance, status, fmt.Sprintf("KafkaCluster %s uses Kerberos authentication, which is not supported by restores", kafkaClusterKey))
+		return ctrl.Result{}, r.updateStatus(ctx, instance, status)
+	}
+
+	store, err := newBackupStore(ctx, r.Client, instance.Namespace, &instance.Spec.Target)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var result ctrl.Result
+	switch status.Phase {
+	case kafkav1alpha1.RestorePhasePending:
+		result, err = r.pending(ctx, instance, kafkaCluster, clientSecurity, store, status)
+	case kafkav1alpha1.RestorePhaseInspecting:
+		err = r.inspect(ctx, instance, kafkaCluster, clientSecurity, store, status)
+	case kafkav1alpha1.RestorePhaseRestoring:
+		err = r.restore(ctx, instance, status)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.V(1).Info("Reconcile finished.", "restore", instance.Name, "namespace", instance.Namespace, "phase", status.Phase)
+	return result, nil
+}
+
+// pending starts the inspect Job once the cluster is available and the snapshot is found
+func (r *KafkaRestoreReconciler) pending(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaRestore,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	clientSecurity *security.ClientSecurity,
+	store backup.Store,
+	status *kafkav1alpha1.KafkaRestoreStatus,
+) (ctrl.Result, error) {
+	if !kafkaCluster.Status.IsAvailable() {
+		status.Message = fmt.Sprintf("waiting for KafkaCluster %s to be available", kafkaCluster.Name)
+		return ctrl.Result{RequeueAfter: kafkaClusterNotFoundInterval}, nil
+	}
+
+	if _, err := r.snapshot(ctx, instance, store, status); err != nil || status.Phase == kafkav1alpha1.RestorePhaseFailed {
+		return ctrl.Result{}, err
+	}
+
+	jobSpec := NewBackupJobSpec(kafkaCluster, clientSecurity, backup.DumpScript(BackupCommandConfig), "")
+	if err := r.createJob(ctx, instance, restoreInspectJobName(instance), jobSpec); err != nil {
+		return ctrl.Result{}, err
+	}
+	status.Phase = kafkav1alpha1.RestorePhaseInspecting
+	status.Message = ""
+	return ctrl.Result{}, nil
+}
+
+// inspect plans the restore from the dump of the cluster and starts the restore Job
+func (r *KafkaRestoreReconciler) inspect(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaRestore,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+	clientSecurity *security.ClientSecurity,
+	store backup.Store,
+	status *kafkav1alpha1.KafkaRestoreStatus,
+) error {
+	job, err := r.finishedJob(ctx, instance, restoreInspectJobName(instance), status)
+	if job == nil || err != nil {
+		return err
+	}
+
+	logs, err := jobLogs(ctx, r.Client, r.PodLogs, job)
+	if err != nil {
+		return err
+	}
+	current, err := backup.ParseDump(kafkaCluster.Name, logs)
+	if err != nil {
+		r.fail(instance, status, fmt.Sprintf("failed to parse the dump of job %s: %s", job.Name, err))
+		return nil
+	}
+	snapshot, err := r.snapshot(ctx, instance, store, status)
+	if snapshot == nil || err != nil {
+		return err
+	}
+
+	plan, err := backup.Plan(snapshot, current, backup.ConflictPolicy(instance.Spec.ConflictPolicy), BackupCommandConfig)
+	if errors.Is(err, backup.ErrConflict) {
+		r.fail(instance, status, err.Error())
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	name := restoreJobName(instance)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: instance.Namespace},
+		Data:       map[string]string{BackupScriptFilename: plan.Script},
+	}
+	if err := ctrl.SetControllerReference(instance, cm, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, cm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if err := r.createJob(ctx, instance, name, NewBackupJobSpec(kafkaCluster, clientSecurity, "", name)); err != nil {
+		return err
+	}
+
+	logger.Info("Restoring snapshot", "snapshot", instance.Spec.Snapshot, "cluster", kafkaCluster.Name,
+		"applied", len(plan.Applied), "skipped", len(plan.Skipped))
+	status.Phase = kafkav1alpha1.RestorePhaseRestoring
+	status.Applied = plan.Applied
+	status.Skipped = plan.Skipped
+	return nil
+}
+
+// restore completes the restore once the restore Job finished
+func (r *KafkaRestoreReconciler) restore(ctx context.Context, instance *kafkav1alpha1.KafkaRestore, status *kafkav1alpha1.KafkaRestoreStatus) error {
+	job, err := r.finishedJob(ctx, instance, restoreJobName(instance), status)
+	if job == nil || err != nil {
+		return err
+	}
+
+	status.Phase = kafkav1alpha1.RestorePhaseSucceeded
+	status.Message = ""
+	status.CompletionTime = job.Status.CompletionTime
+	r.Recorder.Normal(instance, EventReasonRestoreCompleted, "Restore", "Snapshot %s restored into KafkaCluster %s",
+		instance.Spec.Snapshot, instance.Spec.ClusterRef)
+	return nil
+}
+
+// snapshot reads the snapshot of the restore, the restore fails if it does not exist
+func (r *KafkaRestoreReconciler) snapshot(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaRestore,
+	store backup.Store,
+	status *kafkav1alpha1.KafkaRestoreStatus,
+) (*backup.Snapshot, error) {
+	data, err := store.Get(ctx, instance.Spec.Snapshot)
+	if errors.Is(err, backup.ErrSnapshotNotFound) {
+		r.fail(instance, status, err.Error())
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snapshot, err := backup.Unmarshal(data)
+	if err != nil {
+		r.fail(instance, status, fmt.Sprintf("invalid snapshot %s: %s", instance.Spec.Snapshot, err))
+		return nil, nil
+	}
+	return snapshot, nil
+}
+
+// finishedJob returns the Job if it succeeded. The restore fails with the Job, a deleted Job is created again.
+func (r *KafkaRestoreReconciler) finishedJob(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaRestore,
+	name string,
+	status *kafkav1alpha1.KafkaRestoreStatus,
+) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: name}, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			// start over, the Job is created again
+			status.Phase = kafkav1alpha1.RestorePhasePending
+			return nil, nil
+		}
+		return nil, err
+	}
+	finished, failed, message := jobFinished(job)
+	if !finished {
+		return nil, nil
+	}
+	if failed {
+		r.fail(instance, status, fmt.Sprintf("job %s failed: %s", job.Name, message))
+		return nil, nil
+	}
+	return job, nil
+}
+
+func (r *KafkaRestoreReconciler) createJob(ctx context.Context, instance *kafkav1alpha1.KafkaRestore, name string, jobSpec batchv1.JobSpec) error {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: instance.Namespace},
+		Spec:       jobSpec,
+	}
+	if err := ctrl.SetControllerReference(instance, job, r.Scheme); err != nil {
+		return err
+	}
+	logger.Info("Creating restore job", "job", job.Name, "namespace", job.Namespace)
+	if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *KafkaRestoreReconciler) fail(instance *kafkav1alpha1.KafkaRestore, status *kafkav1alpha1.KafkaRestoreStatus, message string) {
+	now := metav1.Now()
+	status.Phase = kafkav1alpha1.RestorePhaseFailed
+	status.Message = message
+	status.CompletionTime = &now
+	r.Recorder.Warning(instance, EventReasonRestoreFailed, "Restore", "Restore of snapshot %s failed: %s", instance.Spec.Snapshot, message)
+}
+
+func (r *KafkaRestoreReconciler) updateStatus(ctx context.Context, instance *kafkav1alpha1.KafkaRestore, status *kafkav1alpha1.KafkaRestoreStatus) error {
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.Status().Update(ctx, instance)
+}
+
+func restoreInspectJobName(instance *kafkav1alpha1.KafkaRestore) string {
+	return instance.Name + "-inspect"
+}
+
+func restoreJobName(instance *kafkav1alpha1.KafkaRestore) string {
+	return instance.Name + "-restore"
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KafkaRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1alpha1.KafkaRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,466 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlevent "sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+)
+
+const (
+	EventReasonSnapshotCompleted = "SnapshotCompleted"
+	EventReasonSnapshotFailed    = "SnapshotFailed"
+
+	// snapshotPollInterval polls the VolumeSnapshots and the held broker,
+	// VolumeSnapshots are not watched as their CRD is optional
+	snapshotPollInterval = 10 * time.Second
+)
+
+// KafkaSnapshotReconciler reconciles a KafkaSnapshot object
+type KafkaSnapshotReconciler struct {
+	ctrlclient.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder *event.Recorder
+}
+
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkasnapshots,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkasnapshots/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkasnapshots/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kafka.kubedoop.dev,resources=kafkaclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+
+// Reconcile creates a VolumeSnapshot of the data volume of every broker of the role group.
+// With controlled shutdown the brokers are stopped one at a time: the operator holds the broker pod with
+// SnapshotHoldAnnotation, the broker shuts down and waits, the volume is snapshotted, the hold is released
+// and the next broker is held once the pod is ready again.
+func (r *KafkaSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	logger.V(1).Info("Reconciling KafkaSnapshot")
+
+	instance := &kafkav1alpha1.KafkaSnapshot{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			logger.V(1).Info("KafkaSnapshot not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, instance)
+	}
+
+	status := instance.Status.DeepCopy()
+	switch status.Phase {
+	case kafkav1alpha1.SnapshotPhaseSucceeded, kafkav1alpha1.SnapshotPhaseFailed:
+		return ctrl.Result{}, r.finalize(ctx, instance)
+	case "":
+		now := metav1.Now()
+		status.Phase = kafkav1alpha1.SnapshotPhasePending
+		status.StartTime = &now
+	}
+	status.ObservedGeneration = instance.Generation
+
+	if instance.Spec.ControlledShutdown && controllerutil.AddFinalizer(instance, kafkav1alpha1.KafkaSnapshotFinalizer) {
+		if err := r.Update(ctx, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	installed, err := isKindInstalled(r.Client, VolumeSnapshotGVK)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !installed {
+		r.fail(instance, status, "the VolumeSnapshot CRD is not installed, a CSI snapshot controller is required")
+		return ctrl.Result{}, r.updateStatus(ctx, instance, status)
+	}
+
+	kafkaCluster := &kafkav1alpha1.KafkaCluster{}
+	kafkaClusterKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterRef}
+	if err := r.Get(ctx, kafkaClusterKey, kafkaCluster); err != nil {
+		if ctrlclient.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		status.Message = fmt.Sprintf("KafkaCluster %s not found", kafkaClusterKey)
+		return ctrl.Result{RequeueAfter: kafkaClusterNotFoundInterval}, r.updateStatus(ctx, instance, status)
+	}
+
+	statefulSet, err := r.statefulSet(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if statefulSet == nil {
+		status.Message = fmt.Sprintf("waiting for the StatefulSet of role group %s", instance.Spec.RoleGroup)
+		return ctrl.Result{RequeueAfter: kafkaClusterNotFoundInterval}, r.updateStatus(ctx, instance, status)
+	}
+
+	var result ctrl.Result
+	switch status.Phase {
+	case kafkav1alpha1.SnapshotPhasePending:
+		result = r.pending(instance, statefulSet, status)
+	case kafkav1alpha1.SnapshotPhaseSnapshotting:
+		result, err = r.snapshotting(ctx, instance, statefulSet, status)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.V(1).Info("Reconcile finished.", "snapshot", instance.Name, "namespace", instance.Namespace, "phase", status.Phase)
+	return result, nil
+}
+
+// pending lists the volumes to snapshot once the role group is available
+func (r *KafkaSnapshotReconciler) pending(
+	instance *kafkav1alpha1.KafkaSnapshot,
+	statefulSet *appv1.StatefulSet,
+	status *kafkav1alpha1.KafkaSnapshotStatus,
+) ctrl.Result {
+	replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+	if replicas == 0 {
+		r.fail(instance, status, fmt.Sprintf("role group %s has no brokers", instance.Spec.RoleGroup))
+		return ctrl.Result{}
+	}
+	// a held broker is unavailable, the other brokers must be ready to not lose availability.
+	// The brokers support the hold once they rolled out with it, it is added for this snapshot by the KafkaCluster reconciler.
+	if instance.Spec.ControlledShutdown && (!supportsSnapshotHold(statefulSet) || !statefulSetRolledOut(statefulSet)) {
+		status.Message = fmt.Sprintf("waiting for the brokers of role group %s to be ready", instance.Spec.RoleGroup)
+		return ctrl.Result{RequeueAfter: snapshotPollInterval}
+	}
+
+	status.Volumes = make([]kafkav1alpha1.KafkaVolumeSnapshotStatus, 0, replicas)
+	for ordinal := range replicas {
+		status.Volumes = append(status.Volumes, kafkav1alpha1.KafkaVolumeSnapshotStatus{
+			Ordinal:               ordinal,
+			PersistentVolumeClaim: DataPvcName(statefulSet.Name, ordinal),
+			VolumeSnapshot:        fmt.Sprintf("%s-%d", instance.Name, ordinal),
+		})
+	}
+	status.Phase = kafkav1alpha1.SnapshotPhaseSnapshotting
+	status.Message = ""
+	return ctrl.Result{}
+}
+
+// snapshotting creates the VolumeSnapshots, one broker at a time with controlled shutdown,
+// and completes the snapshot once all of them are ready to use
+func (r *KafkaSnapshotReconciler) snapshotting(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaSnapshot,
+	statefulSet *appv1.StatefulSet,
+	status *kafkav1alpha1.KafkaSnapshotStatus,
+) (ctrl.Result, error) {
+	// the volumes were listed in the Pending phase, a held broker of a removed pod would be waited for forever
+	if replicas := ptr.Deref(statefulSet.Spec.Replicas, 1); int(replicas) != len(status.Volumes) {
+		r.fail(instance, status, fmt.Sprintf("role group %s was scaled from %d to %d brokers during the snapshot",
+			instance.Spec.RoleGroup, len(status.Volumes), replicas))
+		return ctrl.Result{}, nil
+	}
+
+	status.Message = ""
+	for i := range status.Volumes {
+		volume := &status.Volumes[i]
+		if instance.Spec.ControlledShutdown {
+			done, err := r.snapshotHeld(ctx, instance, statefulSet, volume, status)
+			if err != nil || !done {
+				return ctrl.Result{RequeueAfter: snapshotPollInterval}, err
+			}
+		} else if _, err := r.syncVolumeSnapshot(ctx, instance, volume, status, true); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	for _, volume := range status.Volumes {
+		if !volume.ReadyToUse {
+			if status.Message == "" {
+				status.Message = fmt.Sprintf("waiting for VolumeSnapshot %s to be ready to use", volume.VolumeSnapshot)
+			}
+			return ctrl.Result{RequeueAfter: snapshotPollInterval}, nil
+		}
+	}
+
+	now := metav1.Now()
+	status.Phase = kafkav1alpha1.SnapshotPhaseSucceeded
+	status.Message = ""
+	status.CompletionTime = &now
+	r.Recorder.Normal(instance, EventReasonSnapshotCompleted, "Snapshot", "Data volumes of role group %s of KafkaCluster %s snapshotted",
+		instance.Spec.RoleGroup, instance.Spec.ClusterRef)
+	return ctrl.Result{}, nil
+}
+
+// snapshotHeld snapshots the volume of a stopped broker. It returns true once the snapshot is cut
+// and the broker is ready again.
+func (r *KafkaSnapshotReconciler) snapshotHeld(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaSnapshot,
+	statefulSet *appv1.StatefulSet,
+	volume *kafkav1alpha1.KafkaVolumeSnapshotStatus,
+	status *kafkav1alpha1.KafkaSnapshotStatus,
+) (bool, error) {
+	pod := &corev1.Pod{}
+	podName := fmt.Sprintf("%s-%d", statefulSet.Name, volume.Ordinal)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			status.Message = fmt.Sprintf("waiting for broker pod %s", podName)
+			return false, nil
+		}
+		return false, err
+	}
+
+	snapshotFound, err := r.syncVolumeSnapshot(ctx, instance, volume, status, false)
+	if err != nil {
+		return false, err
+	}
+
+	if !snapshotFound {
+		holdTime, held := pod.Annotations[kafkav1alpha1.SnapshotHoldAnnotation]
+		if !held {
+			patch := ctrlclient.MergeFrom(pod.DeepCopy())
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[kafkav1alpha1.SnapshotHoldAnnotation] = metav1.Now().UTC().Format(time.RFC3339)
+			if err := r.Patch(ctx, pod, patch); err != nil {
+				return false, err
+			}
+			logger.Info("Holding broker for a volume snapshot", "pod", podName, "namespace", pod.Namespace, "snapshot", instance.Name)
+			status.Message = fmt.Sprintf("stopping broker pod %s", podName)
+			return false, nil
+		}
+
+		since, err := time.Parse(time.RFC3339, holdTime)
+		if err != nil {
+			return false, fmt.Errorf("invalid annotation %s of pod %s: %w", kafkav1alpha1.SnapshotHoldAnnotation, podName, err)
+		}
+		if !brokerStoppedSince(pod, since) {
+			status.Message = fmt.Sprintf("waiting for broker pod %s to stop", podName)
+			return false, nil
+		}
+		_, err = r.syncVolumeSnapshot(ctx, instance, volume, status, true)
+		return false, err
+	}
+
+	if volume.CreationTime == nil {
+		return false, nil
+	}
+
+	if _, held := pod.Annotations[kafkav1alpha1.SnapshotHoldAnnotation]; held {
+		if err := r.release(ctx, pod); err != nil {
+			return false, err
+		}
+		status.Message = fmt.Sprintf("waiting for broker pod %s to be ready", podName)
+		return false, nil
+	}
+	if !isPodReady(pod) {
+		status.Message = fmt.Sprintf("waiting for broker pod %s to be ready", podName)
+		return false, nil
+	}
+	return true, nil
+}
+
+// syncVolumeSnapshot copies the status of the VolumeSnapshot of the volume, the VolumeSnapshot is created if
+// create is set. It returns whether the VolumeSnapshot exists.
+func (r *KafkaSnapshotReconciler) syncVolumeSnapshot(
+	ctx context.Context,
+	instance *kafkav1alpha1.KafkaSnapshot,
+	volume *kafkav1alpha1.KafkaVolumeSnapshotStatus,
+	status *kafkav1alpha1.KafkaSnapshotStatus,
+	create bool,
+) (bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(VolumeSnapshotGVK)
+	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: volume.VolumeSnapshot}, obj)
+	if apierrors.IsNotFound(err) {
+		if !create {
+			return false, nil
+		}
+		labels := maps.Clone(BrokerPodLabels(instance.Spec.ClusterRef))
+		labels[constants.LabelKubernetesRoleGroup] = instance.Spec.RoleGroup
+		labels[kafkav1alpha1.SnapshotLabel] = instance.Name
+		obj = newVolumeSnapshot(instance.Namespace, volume.VolumeSnapshot, volume.PersistentVolumeClaim,
+			instance.Spec.VolumeSnapshotClassName, labels)
+		if err := ctrl.SetControllerReference(instance, obj, r.Scheme); err != nil {
+			return false, err
+		}
+		logger.Info("Creating VolumeSnapshot", "volumeSnapshot", volume.VolumeSnapshot, "pvc", volume.PersistentVolumeClaim,
+			"namespace", instance.Namespace)
+		if err := r.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return false, err
+		}
+		status.Message = fmt.Sprintf("waiting for VolumeSnapshot %s to be taken", volume.VolumeSnapshot)
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	state, err := getVolumeSnapshotState(obj)
+	if err != nil {
+		return true, err
+	}
+	volume.CreationTime = state.CreationTime
+	volume.ReadyToUse = state.ReadyToUse
+	volume.RestoreSize = state.RestoreSize
+	// the snapshotter retries, errors may be transient
+	if state.Error != "" {
+		status.Message = fmt.Sprintf("VolumeSnapshot %s: %s", volume.VolumeSnapshot, state.Error)
+	} else if volume.CreationTime == nil {
+		status.Message = fmt.Sprintf("waiting for VolumeSnapshot %s to be taken", volume.VolumeSnapshot)
+	}
+	return true, nil
+}
+
+// statefulSet returns the StatefulSet of the role group, or nil if it does not exist
+func (r *KafkaSnapshotReconciler) statefulSet(ctx context.Context, instance *kafkav1alpha1.KafkaSnapshot) (*appv1.StatefulSet, error) {
+	labels := maps.Clone(BrokerPodLabels(instance.Spec.ClusterRef))
+	labels[constants.LabelKubernetesRoleGroup] = instance.Spec.RoleGroup
+	statefulSets := &appv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSets, ctrlclient.InNamespace(instance.Namespace), ctrlclient.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	if len(statefulSets.Items) == 0 {
+		return nil, nil
+	}
+	return &statefulSets.Items[0], nil
+}
+
+// finalize releases a held broker and removes the finalizer
+func (r *KafkaSnapshotReconciler) finalize(ctx context.Context, instance *kafkav1alpha1.KafkaSnapshot) error {
+	if !controllerutil.ContainsFinalizer(instance, kafkav1alpha1.KafkaSnapshotFinalizer) {
+		return nil
+	}
+
+	labels := maps.Clone(BrokerPodLabels(instance.Spec.ClusterRef))
+	labels[constants.LabelKubernetesRoleGroup] = instance.Spec.RoleGroup
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, ctrlclient.InNamespace(instance.Namespace), ctrlclient.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		if _, held := pods.Items[i].Annotations[kafkav1alpha1.SnapshotHoldAnnotation]; held {
+			if err := r.release(ctx, &pods.Items[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(instance, kafkav1alpha1.KafkaSnapshotFinalizer)
+	return r.Update(ctx, instance)
+}
+
+// release removes the hold of a broker pod, the broker starts again
+func (r *KafkaSnapshotReconciler) release(ctx context.Context, pod *corev1.Pod) error {
+	patch := ctrlclient.MergeFrom(pod.DeepCopy())
+	delete(pod.Annotations, kafkav1alpha1.SnapshotHoldAnnotation)
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return err
+	}
+	logger.Info("Released broker after the volume snapshot", "pod", pod.Name, "namespace", pod.Namespace)
+	return nil
+}
+
+// controlledShutdownSnapshotToCluster enqueues the KafkaCluster of a KafkaSnapshot with controlled shutdown,
+// the brokers of its role group can be held while the snapshot exists
+func controlledShutdownSnapshotToCluster(_ context.Context, obj ctrlclient.Object) []reconcile.Request {
+	snapshot, ok := obj.(*kafkav1alpha1.KafkaSnapshot)
+	if !ok || !snapshot.Spec.ControlledShutdown {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: snapshot.Namespace, Name: snapshot.Spec.ClusterRef}}}
+}
+
+// snapshotCreatedOrDeleted passes KafkaSnapshots being created or deleted, their spec is immutable
+var snapshotCreatedOrDeleted = predicate.Funcs{
+	UpdateFunc: func(ctrlevent.UpdateEvent) bool { return false },
+}
+
+func (r *KafkaSnapshotReconciler) fail(instance *kafkav1alpha1.KafkaSnapshot, status *kafkav1alpha1.KafkaSnapshotStatus, message string) {
+	now := metav1.Now()
+	status.Phase = kafkav1alpha1.SnapshotPhaseFailed
+	status.Message = message
+	status.CompletionTime = &now
+	r.Recorder.Warning(instance, EventReasonSnapshotFailed, "Snapshot", "Snapshot of role group %s failed: %s", instance.Spec.RoleGroup, message)
+}
+
+func (r *KafkaSnapshotReconciler) updateStatus(ctx context.Context, instance *kafkav1alpha1.KafkaSnapshot, status *kafkav1alpha1.KafkaSnapshotStatus) error {
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.Status().Update(ctx, instance)
+}
+
+// brokerStoppedSince returns whether the kafka container of the pod terminated after the time,
+// the container restarts and waits for the release of the hold
+func brokerStoppedSince(pod *corev1.Pod, since time.Time) bool {
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.Name != string(Kafka) {
+			continue
+		}
+		for _, terminated := range []*corev1.ContainerStateTerminated{container.State.Terminated, container.LastTerminationState.Terminated} {
+			if terminated != nil && !terminated.FinishedAt.Time.Before(since) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KafkaSnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1alpha1.KafkaSnapshot{}).
+		Complete(r)
+}
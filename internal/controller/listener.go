@@ -2,6 +2,8 @@ package controller
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -30,7 +32,8 @@ func NewRoleGroupBootstrapListenerReconciler(
 			lbo.ContainerPorts = KafkaContainerPorts(kafkaTlsSecurity)
 			lbo.PublishNotReadyAddresses = true
 			lbo.ExtraPodSelectorLabels = map[string]string{
-				LabelListenerBootstrap: LabelValueTrue, // "app.kubernetes.io/listener-bootstrap: true", add this label for search in discovery
+				LabelListenerBootstrap:      LabelValueTrue, // "app.kubernetes.io/listener-bootstrap: true", add this label for search in discovery
+				LabelListenerBootstrapClass: bootstrapListenerClass,
 			}
 		},
 	)
@@ -100,9 +103,10 @@ func (config *KafkaListenerConfig) AdvertisedListenersString() string {
 }
 
 func (config *KafkaListenerConfig) ListenerSecurityProtocolMapString() string {
+	// sorted, a changing order changes the broker command and rolls the brokers
 	protocolMap := make([]string, 0, len(config.ListenerSecurityProtocolMap))
-	for name, protocol := range config.ListenerSecurityProtocolMap {
-		protocolMap = append(protocolMap, fmt.Sprintf("%s:%s", name, protocol))
+	for _, name := range slices.Sorted(maps.Keys(config.ListenerSecurityProtocolMap)) {
+		protocolMap = append(protocolMap, fmt.Sprintf("%s:%s", name, config.ListenerSecurityProtocolMap[name]))
 	}
 	return strings.Join(protocolMap, ",")
 }
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/util"
+)
+
+// metricsTlsCertificateAlias is the alias of the certificate in the PKCS12 keystores of the secret operator,
+// which does not set a friendly name, so java names the entry by its position.
+const metricsTlsCertificateAlias = "1"
+
+// defaultJmxExporterRules exports the broker MBeans, adapted from the Kafka example of the JMX exporter.
+const defaultJmxExporterRules = `
+- pattern: kafka.server<type=(.+), name=(.+), clientId=(.+), topic=(.+), partition=(.*)><>Value
+  name: kafka_server_$1_$2
+  type: GAUGE
+  labels:
+    clientId: "$3"
+    topic: "$4"
+    partition: "$5"
+- pattern: kafka.server<type=(.+), name=(.+), clientId=(.+), brokerHost=(.+), brokerPort=(.+)><>Value
+  name: kafka_server_$1_$2
+  type: GAUGE
+  labels:
+    clientId: "$3"
+    broker: "$4:$5"
+- pattern: kafka.server<type=KafkaRequestHandlerPool, name=RequestHandlerAvgIdlePercent><>OneMinuteRate
+  name: kafka_server_kafkarequesthandlerpool_requesthandleravgidlepercent
+  type: GAUGE
+- pattern: kafka.coordinator.(\w+)<type=(.+), name=(.+)><>Value
+  name: kafka_coordinator_$1_$2_$3
+  type: GAUGE
+- pattern: kafka.(\w+)<type=(.+), name=(.+)PerSec\w*, (.+)=(.+), (.+)=(.+)><>Count
+  name: kafka_$1_$2_$3_total
+  type: COUNTER
+  labels:
+    "$4": "$5"
+    "$6": "$7"
+- pattern: kafka.(\w+)<type=(.+), name=(.+)PerSec\w*, (.+)=(.+)><>Count
+  name: kafka_$1_$2_$3_total
+  type: COUNTER
+  labels:
+    "$4": "$5"
+- pattern: kafka.(\w+)<type=(.+), name=(.+)PerSec\w*><>Count
+  name: kafka_$1_$2_$3_total
+  type: COUNTER
+- pattern: kafka.(\w+)<type=(.+), name=(.+), (.+)=(.+), (.+)=(.+)><>Value
+  name: kafka_$1_$2_$3
+  type: GAUGE
+  labels:
+    "$4": "$5"
+    "$6": "$7"
+- pattern: kafka.(\w+)<type=(.+), name=(.+), (.+)=(.+)><>Value
+  name: kafka_$1_$2_$3
+  type: GAUGE
+  labels:
+    "$4": "$5"
+- pattern: kafka.(\w+)<type=(.+), name=(.+)><>Value
+  name: kafka_$1_$2_$3
+  type: GAUGE
+- pattern: kafka.(\w+)<type=(.+), name=(.+), (.+)=(.+), (.+)=(.+)><>Count
+  name: kafka_$1_$2_$3_count
+  type: COUNTER
+  labels:
+    "$4": "$5"
+    "$6": "$7"
+- pattern: kafka.(\w+)<type=(.+), name=(.+), (.+)=(.*), (.+)=(.+)><>(\d+)thPercentile
+  name: kafka_$1_$2_$3
+  type: GAUGE
+  labels:
+    "$4": "$5"
+    "$6": "$7"
+    quantile: "0.$8"
+- pattern: kafka.(\w+)<type=(.+), name=(.+), (.+)=(.+)><>Count
+  name: kafka_$1_$2_$3_count
+  type: COUNTER
+  labels:
+    "$4": "$5"
+- pattern: kafka.(\w+)<type=(.+), name=(.+), (.+)=(.*)><>(\d+)thPercentile
+  name: kafka_$1_$2_$3
+  type: GAUGE
+  labels:
+    "$4": "$5"
+    quantile: "0.$6"
+- pattern: kafka.(\w+)<type=(.+), name=(.+)><>Count
+  name: kafka_$1_$2_$3_count
+  type: COUNTER
+- pattern: kafka.(\w+)<type=(.+), name=(.+)><>(\d+)thPercentile
+  name: kafka_$1_$2_$3
+  type: GAUGE
+  labels:
+    quantile: "0.$4"
+`
+
+// IsMetricsEnabled returns whether the JMX exporter runs in the broker containers, it is enabled by default
+func IsMetricsEnabled(metrics *kafkav1alpha1.MetricsSpec) bool {
+	return metrics == nil || metrics.Enabled == nil || *metrics.Enabled
+}
+
+// IsMetricsTlsEnabled returns whether the metrics endpoint is served over https
+func IsMetricsTlsEnabled(metrics *kafkav1alpha1.MetricsSpec) bool {
+	return IsMetricsEnabled(metrics) && metrics != nil && metrics.Tls != nil
+}
+
+// GetMetricsPort returns the port of the metrics endpoint
+func GetMetricsPort(metrics *kafkav1alpha1.MetricsSpec) int32 {
+	if metrics == nil || metrics.Port == 0 {
+		return kafkav1alpha1.MetricsPort
+	}
+	return metrics.Port
+}
+
+// GetMetricsScheme returns the scheme of the metrics endpoint, `http` or `https`
+func GetMetricsScheme(metrics *kafkav1alpha1.MetricsSpec) string {
+	if IsMetricsTlsEnabled(metrics) {
+		return "https"
+	}
+	return "http"
+}
+
+// MetricsContainerPorts returns the metrics port of the container, if metrics are enabled
+func MetricsContainerPorts(metrics *kafkav1alpha1.MetricsSpec) []corev1.ContainerPort {
+	if !IsMetricsEnabled(metrics) {
+		return nil
+	}
+	return []corev1.ContainerPort{
+		{
+			Name:          kafkav1alpha1.MetricsPortName,
+			ContainerPort: GetMetricsPort(metrics),
+			Protocol:      corev1.ProtocolTCP,
+		},
+	}
+}
+
+// JmxExporterJavaAgent returns the java agent option starting the JMX exporter with the config rendered in the role group ConfigMap
+func JmxExporterJavaAgent(metrics *kafkav1alpha1.MetricsSpec) string {
+	return fmt.Sprintf("-javaagent:%s/jmx/jmx_prometheus_javaagent.jar=%d:%s/%s",
+		kafkav1alpha1.KubedoopRoot, GetMetricsPort(metrics), kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.JmxExporterFileName)
+}
+
+// jmxExporterConfig is the config file of the JMX exporter
+type jmxExporterConfig struct {
+	LowercaseOutputName bool                    `json:"lowercaseOutputName"`
+	HttpServer          *jmxExporterHttpServer  `json:"httpServer,omitempty"`
+	Rules               []jmxExporterRuleConfig `json:"rules"`
+}
+
+type jmxExporterHttpServer struct {
+	SSL jmxExporterSSL `json:"ssl"`
+}
+
+type jmxExporterSSL struct {
+	KeyStore    jmxExporterKeyStore    `json:"keyStore"`
+	Certificate jmxExporterCertificate `json:"certificate"`
+}
+
+type jmxExporterKeyStore struct {
+	Filename string `json:"filename"`
+	Type     string `json:"type"`
+	Password string `json:"password"`
+}
+
+type jmxExporterCertificate struct {
+	Alias string `json:"alias"`
+}
+
+// jmxExporterRuleConfig is a JmxExporterRule with a numeric value factor, as expected by the exporter
+type jmxExporterRuleConfig struct {
+	kafkav1alpha1.JmxExporterRule `json:",inline"`
+	ValueFactor                   *float64 `json:"valueFactor,omitempty"`
+}
+
+// JmxExporterConfig renders the config of the JMX exporter.
+// The user rules are evaluated first and followed by the default Kafka rules, unless they are replaced.
+func JmxExporterConfig(metrics *kafkav1alpha1.MetricsSpec) (string, error) {
+	if metrics == nil {
+		metrics = &kafkav1alpha1.MetricsSpec{}
+	}
+
+	rules := metrics.Rules
+	if !metrics.ReplaceDefaultRules {
+		var defaultRules []kafkav1alpha1.JmxExporterRule
+		if err := yaml.Unmarshal([]byte(defaultJmxExporterRules), &defaultRules); err != nil {
+			return "", fmt.Errorf("failed to parse the default jmx exporter rules: %w", err)
+		}
+		rules = append(append([]kafkav1alpha1.JmxExporterRule{}, metrics.Rules...), defaultRules...)
+	}
+
+	config := jmxExporterConfig{
+		LowercaseOutputName: true,
+		Rules:               make([]jmxExporterRuleConfig, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		ruleConfig := jmxExporterRuleConfig{JmxExporterRule: rule}
+		if rule.ValueFactor != "" {
+			valueFactor, err := strconv.ParseFloat(rule.ValueFactor, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid valueFactor %q of jmx exporter rule %q: %w", rule.ValueFactor, rule.Pattern, err)
+			}
+			ruleConfig.ValueFactor = &valueFactor
+			ruleConfig.JmxExporterRule.ValueFactor = ""
+		}
+		config.Rules = append(config.Rules, ruleConfig)
+	}
+
+	if IsMetricsTlsEnabled(metrics) {
+		config.HttpServer = &jmxExporterHttpServer{
+			SSL: jmxExporterSSL{
+				KeyStore: jmxExporterKeyStore{
+					Filename: kafkav1alpha1.KubedoopMetricsTlsDir + "/keystore.p12",
+					Type:     "PKCS12",
+					Password: metrics.Tls.SSLStorePassword,
+				},
+				Certificate: jmxExporterCertificate{Alias: metricsTlsCertificateAlias},
+			},
+		}
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// MetricsTlsVolume returns the secret operator volume holding the keystore of the metrics endpoint.
+// The certificate is valid for the pod and the metrics Service of the role group.
+func MetricsTlsVolume(metrics *kafkav1alpha1.MetricsSpec, roleGroupInfo *reconciler.RoleGroupInfo, requestedSecretLifeTime string) corev1.Volume {
+	builder := util.SecretVolumeBuilder{VolumeName: kafkav1alpha1.KubedoopMetricsTlsName}
+	builder.SetAnnotations(map[string]string{
+		constants.AnnotationSecretsClass: metrics.Tls.SecretClass,
+		constants.AnnotationSecretsScope: strings.Join([]string{
+			string(constants.PodScope),
+			string(constants.NodeScope),
+			string(constants.ServiceScope) + "=" + CreateServiceMetricsName(roleGroupInfo),
+		}, constants.CommonDelimiter),
+		constants.AnnotationSecretsFormat: string(constants.TLSP12),
+	})
+	if metrics.Tls.SSLStorePassword != "" {
+		builder.AddAnnotation(constants.AnnotationSecretsPKCS12Password, metrics.Tls.SSLStorePassword)
+	}
+	if requestedSecretLifeTime != "" {
+		builder.AddAnnotation(constants.AnnotationSecretCertLifeTime, requestedSecretLifeTime)
+	}
+	return builder.Build()
+}
+
+// defaultMetricsSpec returns the metrics defaults merged into the role group config
+func defaultMetricsSpec() *kafkav1alpha1.MetricsSpec {
+	return &kafkav1alpha1.MetricsSpec{
+		Enabled: ptr.To(true),
+		Port:    kafkav1alpha1.MetricsPort,
+	}
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+var _ reconciler.Reconciler = &MirrorMaker2Reconciler{}
+
+// MirrorMaker2Reconciler reconciles the resources of a KafkaMirrorMaker2
+type MirrorMaker2Reconciler struct {
+	reconciler.BaseCluster[*kafkav1alpha1.KafkaMirrorMaker2Spec]
+	ClusterConfig *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec
+
+	source *MirrorMaker2Cluster
+	target *MirrorMaker2Cluster
+}
+
+func NewMirrorMaker2ClusterReconciler(
+	client *client.Client,
+	clusterInfo reconciler.ClusterInfo,
+	spec *kafkav1alpha1.KafkaMirrorMaker2Spec,
+	source *MirrorMaker2Cluster,
+	target *MirrorMaker2Cluster,
+) *MirrorMaker2Reconciler {
+	return &MirrorMaker2Reconciler{
+		BaseCluster: *reconciler.NewBaseCluster(
+			client,
+			clusterInfo,
+			spec.ClusterOperation,
+			spec,
+		),
+		ClusterConfig: spec.ClusterConfig,
+		source:        source,
+		target:        target,
+	}
+}
+
+func (r *MirrorMaker2Reconciler) GetImage() *opgoutil.Image {
+	return NewImage(r.Spec.Image)
+}
+
+func (r *MirrorMaker2Reconciler) RegisterResources(ctx context.Context) error {
+	// RBAC
+	sa := NewServiceAccountReconciler(r.Client, r.GetName())
+	r.AddResource(sa)
+
+	// role `worker`
+	roleInfo := reconciler.RoleInfo{ClusterInfo: r.ClusterInfo, RoleName: MirrorMaker2RoleName}
+
+	worker := NewMirrorMaker2WorkerReconciler(
+		r.Client,
+		roleInfo,
+		r.Spec.Workers,
+		r.GetImage(),
+		r.ClusterConfig,
+		r.Spec.ClusterOperation,
+		r.source,
+		r.target,
+	)
+	if err := worker.RegisterResources(ctx); err != nil {
+		return err
+	}
+	r.AddResource(worker)
+
+	return nil
+}
+
+// MirrorMaker2WorkerReconciler reconciles the role groups of the MirrorMaker2 workers
+type MirrorMaker2WorkerReconciler struct {
+	reconciler.BaseRoleReconciler[*kafkav1alpha1.KafkaMirrorMaker2WorkersSpec]
+
+	clusterConfig    *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec
+	image            *opgoutil.Image
+	source           *MirrorMaker2Cluster
+	target           *MirrorMaker2Cluster
+}
+
+func NewMirrorMaker2WorkerReconciler(
+	client *client.Client,
+	roleInfo reconciler.RoleInfo,
+	spec *kafkav1alpha1.KafkaMirrorMaker2WorkersSpec,
+	image *opgoutil.Image,
+	clusterConfig *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec,
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
+	source *MirrorMaker2Cluster,
+	target *MirrorMaker2Cluster,
+) *MirrorMaker2WorkerReconciler {
+	stopped := clusterOperation != nil && clusterOperation.Stopped
+
+	return &MirrorMaker2WorkerReconciler{
+		BaseRoleReconciler: *reconciler.NewBaseRoleReconciler(
+			client,
+			stopped,
+			roleInfo,
+			spec,
+		),
+		clusterConfig:    clusterConfig,
+		clusterOperation: clusterOperation,
+		image:            image,
+		source:           source,
+		target:           target,
+	}
+}
+
+func (r *MirrorMaker2WorkerReconciler) RegisterResources(ctx context.Context) error {
+	for name, roleGroup := range r.Spec.RoleGroups {
+		if roleGroup == nil {
+			roleGroup = &kafkav1alpha1.KafkaMirrorMaker2RoleGroupSpec{Replicas: 1}
+		}
+		mergedConfig, err := opgoutil.MergeObject(r.Spec.Config, roleGroup.Config)
+		if err != nil {
+			return err
+		}
+		overrides, err := opgoutil.MergeObject(r.Spec.OverridesSpec, roleGroup.OverridesSpec)
+		if err != nil {
+			return err
+		}
+
+		// merge default config to the user provided config
+		if overrides == nil {
+			overrides = &commonsv1alpha1.OverridesSpec{}
+		}
+		if mergedConfig == nil {
+			mergedConfig = &kafkav1alpha1.KafkaMirrorMaker2ConfigSpec{}
+		}
+		if err := MergeFromUserMirrorMaker2Config(mergedConfig, overrides, r.GetClusterName()); err != nil {
+			return err
+		}
+
+		info := &reconciler.RoleGroupInfo{
+			RoleInfo:      r.RoleInfo,
+			RoleGroupName: name,
+		}
+		for _, reconciler := range r.registerResourceWithRoleGroup(ctx, roleGroup.Replicas, info, overrides, mergedConfig) {
+			r.AddResource(reconciler)
+			logger.Info("registered resource", "role", r.GetName(), "roleGroup", name, "reconciler", reconciler.GetName())
+		}
+	}
+	return nil
+}
+
+func (r *MirrorMaker2WorkerReconciler) registerResourceWithRoleGroup(
+	ctx context.Context,
+	replicas int32,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	overrides *commonsv1alpha1.OverridesSpec,
+	mm2Config *kafkav1alpha1.KafkaMirrorMaker2ConfigSpec,
+) []reconciler.Reconciler {
+	return []reconciler.Reconciler{
+		// headless service of the statefulset, used by the workers to forward requests to the leader
+		NewRoleGroupService(r.Client, roleGroupInfo),
+		// metrics service
+		NewRoleGroupMetricsService(r.Client, roleGroupInfo, nil),
+		// configmap
+		NewMirrorMaker2ConfigmapReconciler(
+			r.Client,
+			roleGroupInfo,
+			r.clusterConfig,
+			r.source,
+			r.target,
+			overrides,
+			mm2Config.RoleGroupConfigSpec,
+		),
+		// statefulset
+		NewMirrorMaker2StatefulSetReconciler(
+			ctx,
+			r.Client,
+			r.image,
+			&replicas,
+			r.clusterConfig,
+			r.clusterOperation,
+			roleGroupInfo,
+			mm2Config,
+			overrides,
+			r.source,
+			r.target,
+		),
+	}
+}
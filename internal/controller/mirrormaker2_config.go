@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+const (
+	MirrorMaker2RoleName = "worker"
+
+	// MirrorMaker2JmxExporterFileName is the JMX exporter configuration of the workers
+	MirrorMaker2JmxExporterFileName = "jmx-exporter.yaml"
+
+	// defaultMirrorMaker2ReplicationFactor is used for external target clusters, where the number of brokers is unknown
+	defaultMirrorMaker2ReplicationFactor int32 = 3
+)
+
+var _ OverrideConfiguration = &MirrorMaker2Config{}
+
+type MirrorMaker2Config struct {
+	commonsv1alpha1.RoleGroupConfigSpec
+
+	RequestedSecretLifetime string
+}
+
+// ComputeCli implements OverrideConfiguration.
+func (c *MirrorMaker2Config) ComputeCli() ([]string, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ComputeEnv implements OverrideConfiguration.
+func (c *MirrorMaker2Config) ComputeEnv() (map[string]string, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ComputeFile implements OverrideConfiguration.
+func (c *MirrorMaker2Config) ComputeFile() (map[string]map[string]string, error) {
+	return map[string]map[string]string{
+		kafkav1alpha1.MirrorMaker2FileName: {
+			"tasks.max":                           "1",
+			"refresh.topics.interval.seconds":     "60",
+			"refresh.groups.interval.seconds":     "60",
+			"emit.heartbeats.enabled":             "true",
+			"emit.heartbeats.interval.seconds":    "5",
+			"sync.topic.configs.interval.seconds": "600",
+		},
+		SecurityPropertiesFilename: {
+			"networkaddress.cache.ttl":          "30",
+			"networkaddress.cache.negative.ttl": "0",
+		},
+	}, nil
+}
+
+func DefaultMirrorMaker2Config(clusterName string) MirrorMaker2Config {
+	rawAffinity, err := json.Marshal(defaultAffinity(MirrorMaker2RoleName, clusterName))
+	if err != nil {
+		clusterConfigLogger.Error(err, "Failed to marshal affinity")
+	}
+
+	return MirrorMaker2Config{
+		RoleGroupConfigSpec: commonsv1alpha1.RoleGroupConfigSpec{
+			Affinity: &runtime.RawExtension{
+				Raw: rawAffinity,
+			},
+			GracefulShutdownTimeout: "30s",
+			Logging: &commonsv1alpha1.LoggingSpec{
+				EnableVectorAgent: ptr.To(false),
+				Containers:        nil,
+			},
+			Resources: &commonsv1alpha1.ResourcesSpec{
+				CPU: &commonsv1alpha1.CPUResource{
+					Max: resource.MustParse("1000m"),
+					Min: resource.MustParse("250m"),
+				},
+				Memory: &commonsv1alpha1.MemoryResource{
+					Limit: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		RequestedSecretLifetime: "1d",
+	}
+}
+
+// MergeFromUserMirrorMaker2Config merges the default worker configuration into the user provided configuration
+func MergeFromUserMirrorMaker2Config(
+	userConfig *kafkav1alpha1.KafkaMirrorMaker2ConfigSpec,
+	userOverrides *commonsv1alpha1.OverridesSpec,
+	clusterName string,
+) error {
+	defaultConfig := DefaultMirrorMaker2Config(clusterName)
+
+	if userConfig.RoleGroupConfigSpec == nil {
+		userConfig.RoleGroupConfigSpec = &commonsv1alpha1.RoleGroupConfigSpec{
+			Affinity:                defaultConfig.Affinity,
+			GracefulShutdownTimeout: defaultConfig.GracefulShutdownTimeout,
+			Logging:                 defaultConfig.Logging,
+			Resources:               defaultConfig.Resources,
+		}
+	}
+	if userConfig.Affinity == nil {
+		userConfig.Affinity = defaultConfig.Affinity
+	}
+	if userConfig.Logging == nil {
+		userConfig.Logging = defaultConfig.Logging
+	}
+	if userConfig.Resources == nil {
+		userConfig.Resources = defaultConfig.Resources
+	} else {
+		mergeResources(userConfig.Resources, defaultConfig.Resources)
+	}
+
+	if userConfig.RequestedSecretLifeTime == "" {
+		userConfig.RequestedSecretLifeTime = defaultConfig.RequestedSecretLifetime
+	}
+
+	return mergeOverrides(userOverrides, &defaultConfig)
+}
+
+// MirrorMaker2Cluster is a source or target cluster of MirrorMaker2, resolved from the spec and the referenced KafkaCluster
+type MirrorMaker2Cluster struct {
+	Alias string
+
+	// static bootstrap servers of an external cluster
+	BootstrapServers string
+	// the KafkaCluster providing the bootstrap servers through its discovery ConfigMap
+	KafkaCluster *kafkav1alpha1.KafkaCluster
+
+	Security *security.ClientSecurity
+	Config   map[string]string
+}
+
+// NewMirrorMaker2Cluster resolves a cluster of MirrorMaker2. kafkaCluster is nil for external clusters.
+// name distinguishes the security volumes of the source and target cluster in the worker pods.
+func NewMirrorMaker2Cluster(
+	name string,
+	spec *kafkav1alpha1.MirrorMaker2ClusterSpec,
+	kafkaCluster *kafkav1alpha1.KafkaCluster,
+) *MirrorMaker2Cluster {
+	cluster := &MirrorMaker2Cluster{
+		Alias:            spec.Alias,
+		BootstrapServers: spec.BootstrapServers,
+		KafkaCluster:     kafkaCluster,
+		Config:           spec.Config,
+	}
+
+	if kafkaCluster != nil {
+		cluster.Security = security.NewKafkaSecurity(kafkaCluster).Client()
+	} else {
+		cluster.Security = &security.ClientSecurity{}
+		if tls := spec.Tls; tls != nil {
+			cluster.Security.ServerSecretClass = tls.ServerSecretClass
+			cluster.Security.SSLStorePassword = tls.SSLStorePassword
+		}
+		if kerberos := spec.Kerberos; kerberos != nil {
+			cluster.Security.KerberosSecretClass = kerberos.KerberosSecretClass
+			cluster.Security.BrokerKerberosServiceName = kerberos.ServiceName
+		}
+	}
+	cluster.Security.Name = name
+	return cluster
+}
+
+// BootstrapServersEnvName returns the environment variable holding the bootstrap servers of a KafkaCluster
+func (c *MirrorMaker2Cluster) BootstrapServersEnvName() string {
+	return EnvKafkaBootstrapServers + "_" + strings.ToUpper(strings.ReplaceAll(c.Security.Name, "-", "_"))
+}
+
+// DefaultReplicationFactor returns the number of brokers of the KafkaCluster, capped at 3
+func (c *MirrorMaker2Cluster) DefaultReplicationFactor() int32 {
+	if c.KafkaCluster == nil {
+		return defaultMirrorMaker2ReplicationFactor
+	}
+	return max(min(BrokerCount(c.KafkaCluster), defaultMirrorMaker2ReplicationFactor), 1)
+}
+
+// ReplicationPolicyClass returns the class implementing the replication policy
+func ReplicationPolicyClass(policy kafkav1alpha1.ReplicationPolicy) string {
+	if policy == kafkav1alpha1.ReplicationPolicyIdentity {
+		return "org.apache.kafka.connect.mirror.IdentityReplicationPolicy"
+	}
+	return "org.apache.kafka.connect.mirror.DefaultReplicationPolicy"
+}
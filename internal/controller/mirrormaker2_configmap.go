@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/config/properties"
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/connect"
+)
+
+const (
+	MirrorMaker2Log4jFilename = "mirrormaker2.log4j.xml"
+)
+
+func NewMirrorMaker2ConfigmapReconciler(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	clusterConfig *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec,
+	source *MirrorMaker2Cluster,
+	target *MirrorMaker2Cluster,
+	overrides *commonsv1alpha1.OverridesSpec,
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+) reconciler.ResourceReconciler[builder.ConfigBuilder] {
+	builder := NewMirrorMaker2ConfigmapBuilder(
+		client,
+		roleGroupInfo,
+		clusterConfig,
+		source,
+		target,
+		overrides,
+		roleGroupConfig,
+	)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
+
+func NewMirrorMaker2ConfigmapBuilder(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	clusterConfig *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec,
+	source *MirrorMaker2Cluster,
+	target *MirrorMaker2Cluster,
+	overrides *commonsv1alpha1.OverridesSpec,
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+) builder.ConfigBuilder {
+	return &MirrorMaker2ConfigmapBuilder{
+		ConfigMapBuilder: *builder.NewConfigMapBuilder(
+			client,
+			roleGroupInfo.GetFullName(),
+			func(o *builder.Options) {
+				o.Labels = roleGroupInfo.GetLabels()
+				o.Annotations = roleGroupInfo.GetAnnotations()
+			},
+		),
+		clusterConfig:   clusterConfig,
+		source:          source,
+		target:          target,
+		overrides:       overrides,
+		roleGroupConfig: roleGroupConfig,
+		roleGroupInfo:   roleGroupInfo,
+	}
+}
+
+type MirrorMaker2ConfigmapBuilder struct {
+	builder.ConfigMapBuilder
+
+	clusterConfig   *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec
+	source          *MirrorMaker2Cluster
+	target          *MirrorMaker2Cluster
+	overrides       *commonsv1alpha1.OverridesSpec
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec
+	roleGroupInfo   *reconciler.RoleGroupInfo
+}
+
+func (b *MirrorMaker2ConfigmapBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	propertyFiles := map[string]func() (string, error){
+		kafkav1alpha1.MirrorMaker2FileName: b.buildMirrorMaker2Properties, // mm2.properties
+		SecurityPropertiesFilename:         b.buildSecurityProperties,     // security.properties
+		Log4jPropertiesFilename:            b.buildLog4jProperties,        // log4j.properties
+	}
+
+	for filename, builder := range propertyFiles {
+		content, err := builder()
+		if err != nil {
+			return nil, err
+		}
+		if content != "" {
+			b.AddItem(filename, content)
+		}
+	}
+
+	// jmx exporter config, exports the replication lag
+	b.AddItem(MirrorMaker2JmxExporterFileName, connect.MirrorMetricsExporterConfig)
+
+	// vector config
+	if IsVectorEnable(b.roleGroupConfig.Logging) {
+		if b.clusterConfig.VectorAggregatorConfigMapName == "" {
+			return nil, ErrVectorAggregatorNotSet
+		}
+		vectorConfig, err := productlogging.MakeVectorYaml(
+			ctx,
+			b.Client.Client,
+			b.Client.GetOwnerNamespace(),
+			b.roleGroupInfo.ClusterName,
+			b.roleGroupInfo.RoleName,
+			b.roleGroupInfo.RoleGroupName,
+			b.clusterConfig.VectorAggregatorConfigMapName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		b.AddItem(VectorConfigFilename, vectorConfig) // vector.yaml
+	}
+
+	return b.GetObject(), nil
+}
+
+// mm2.properties
+//
+// The bootstrap servers of KafkaClusters, the advertised REST host and the Kerberos JAAS configurations
+// are only known at runtime and are appended by the container command.
+func (b *MirrorMaker2ConfigmapBuilder) buildMirrorMaker2Properties() (string, error) {
+	source, target := b.source.Alias, b.target.Alias
+	flow := source + "->" + target
+
+	replicationFactor := strconv.Itoa(int(b.target.DefaultReplicationFactor()))
+	replication := b.clusterConfig.Replication
+	if replication == nil {
+		replication = &kafkav1alpha1.MirrorMaker2ReplicationSpec{}
+	}
+	if replication.ReplicationFactor != nil {
+		replicationFactor = strconv.Itoa(int(*replication.ReplicationFactor))
+	}
+	syncTopicConfigs := strconv.FormatBool(replication.SyncTopicConfigs == nil || *replication.SyncTopicConfigs)
+
+	data := map[string]string{
+		"clusters":                          source + "," + target,
+		flow + ".enabled":                   "true",
+		target + "->" + source + ".enabled": "false",
+
+		"replication.policy.class":              ReplicationPolicyClass(replication.Policy),
+		"replication.factor":                    replicationFactor,
+		"checkpoints.topic.replication.factor":  replicationFactor,
+		"heartbeats.topic.replication.factor":   replicationFactor,
+		"offset-syncs.topic.replication.factor": replicationFactor,
+		"config.storage.replication.factor":     replicationFactor,
+		"offset.storage.replication.factor":     replicationFactor,
+		"status.storage.replication.factor":     replicationFactor,
+		"sync.topic.configs.enabled":            syncTopicConfigs,
+		"sync.topic.acls.enabled":               syncTopicConfigs,
+
+		// the workers forward task configurations to the leader through the internal REST API
+		"dedicated.mode.enable.internal.rest": "true",
+		"listeners":                           "http://0.0.0.0:" + strconv.Itoa(kafkav1alpha1.ConnectRestPort),
+		"rest.advertised.listener":            "http",
+		"rest.advertised.port":                strconv.Itoa(kafkav1alpha1.ConnectRestPort),
+	}
+	maps.Copy(data, mirrorMaker2FilterSettings(flow+".topics", b.clusterConfig.Topics))
+	maps.Copy(data, mirrorMaker2FilterSettings(flow+".groups", b.clusterConfig.Groups))
+	maps.Copy(data, mirrorMaker2OffsetSettings(b.clusterConfig.OffsetSync, b.clusterConfig.Checkpoints))
+
+	for _, cluster := range []*MirrorMaker2Cluster{b.source, b.target} {
+		if cluster.KafkaCluster == nil {
+			data[cluster.Alias+".bootstrap.servers"] = cluster.BootstrapServers
+		}
+		for k, v := range cluster.Config {
+			data[cluster.Alias+"."+k] = v
+		}
+	}
+
+	if b.overrides != nil && b.overrides.ConfigOverrides != nil {
+		maps.Copy(data, b.overrides.ConfigOverrides[kafkav1alpha1.MirrorMaker2FileName])
+	}
+
+	for _, cluster := range []*MirrorMaker2Cluster{b.source, b.target} {
+		maps.Copy(data, cluster.Security.ConfigSettings(cluster.Alias+".")) // tls, kerberos
+	}
+
+	return properties.NewPropertiesFromMap(data).Marshal()
+}
+
+// mirrorMaker2FilterSettings returns the include and exclude patterns, e.g. `a->b.topics` and `a->b.topics.exclude`
+func mirrorMaker2FilterSettings(key string, filter *kafkav1alpha1.MirrorMaker2FilterSpec) map[string]string {
+	include := []string{".*"}
+	var exclude []string
+	if filter != nil {
+		if len(filter.Include) > 0 {
+			include = filter.Include
+		}
+		exclude = filter.Exclude
+	}
+
+	settings := map[string]string{key: strings.Join(include, ",")}
+	if len(exclude) > 0 {
+		settings[key+".exclude"] = strings.Join(exclude, ",")
+	}
+	return settings
+}
+
+func mirrorMaker2OffsetSettings(offsetSync *kafkav1alpha1.MirrorMaker2OffsetSyncSpec, checkpoints *kafkav1alpha1.MirrorMaker2CheckpointsSpec) map[string]string {
+	if offsetSync == nil {
+		offsetSync = &kafkav1alpha1.MirrorMaker2OffsetSyncSpec{}
+	}
+	if checkpoints == nil {
+		checkpoints = &kafkav1alpha1.MirrorMaker2CheckpointsSpec{}
+	}
+
+	settings := map[string]string{
+		"emit.checkpoints.enabled":   strconv.FormatBool(checkpoints.Enabled == nil || *checkpoints.Enabled),
+		"sync.group.offsets.enabled": strconv.FormatBool(offsetSync.Enabled == nil || *offsetSync.Enabled),
+	}
+	if checkpoints.IntervalSeconds > 0 {
+		settings["emit.checkpoints.interval.seconds"] = fmt.Sprint(checkpoints.IntervalSeconds)
+	}
+	if offsetSync.IntervalSeconds > 0 {
+		settings["sync.group.offsets.interval.seconds"] = fmt.Sprint(offsetSync.IntervalSeconds)
+	}
+	if offsetSync.OffsetSyncsTopicLocation != "" {
+		settings["offset-syncs.topic.location"] = string(offsetSync.OffsetSyncsTopicLocation)
+	}
+	return settings
+}
+
+// security properties
+func (b *MirrorMaker2ConfigmapBuilder) buildSecurityProperties() (string, error) {
+	if b.overrides != nil && b.overrides.ConfigOverrides != nil {
+		if data, ok := b.overrides.ConfigOverrides[SecurityPropertiesFilename]; ok {
+			return properties.NewPropertiesFromMap(data).Marshal()
+		}
+	}
+	return "", nil
+}
+
+// log4j properties
+func (b *MirrorMaker2ConfigmapBuilder) buildLog4jProperties() (string, error) {
+	var loggingSpec *commonsv1alpha1.LoggingConfigSpec
+	if b.roleGroupConfig != nil && b.roleGroupConfig.Logging != nil && b.roleGroupConfig.Logging.Containers != nil {
+		if mainContainerLogging, ok := b.roleGroupConfig.Logging.Containers[MirrorMaker2RoleName]; ok {
+			loggingSpec = &mainContainerLogging
+		}
+	}
+	loggingConfig, err := productlogging.NewConfigGenerator(
+		loggingSpec,
+		MirrorMaker2RoleName,
+		MirrorMaker2Log4jFilename,
+		productlogging.LogTypeLog4j,
+		func(cgo *productlogging.ConfigGeneratorOption) {
+			cgo.ConsoleHandlerFormatter = ptr.To(ConsoleConversionPattern)
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return loggingConfig.Content()
+}
@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/util"
+)
+
+const (
+	// MirrorMaker2KerberosServiceName is the service name of the principal used by the workers
+	MirrorMaker2KerberosServiceName = "kafka-mirrormaker2"
+
+	mirrorMaker2RuntimePropertiesPath = "/tmp/" + kafkav1alpha1.MirrorMaker2FileName
+)
+
+func NewMirrorMaker2StatefulSetReconciler(
+	ctx context.Context,
+	client *client.Client,
+	image *opgoutil.Image,
+	replicas *int32,
+	clusterConfig *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec,
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	mm2Config *kafkav1alpha1.KafkaMirrorMaker2ConfigSpec,
+	overrides *commonsv1alpha1.OverridesSpec,
+	source *MirrorMaker2Cluster,
+	target *MirrorMaker2Cluster,
+) reconciler.ResourceReconciler[builder.StatefulSetBuilder] {
+	stopped := clusterOperation != nil && clusterOperation.Stopped
+
+	builder := &MirrorMaker2StatefulSetBuilder{
+		StatefulSet: *builder.NewStatefulSetBuilder(
+			client,
+			roleGroupInfo.GetFullName(),
+			replicas,
+			image,
+			overrides,
+			mm2Config.RoleGroupConfigSpec,
+			func(o *builder.Options) {
+				o.ClusterName = roleGroupInfo.ClusterName
+				o.Labels = roleGroupInfo.GetLabels()
+				o.Annotations = roleGroupInfo.GetAnnotations()
+				o.RoleName = roleGroupInfo.RoleName
+				o.RoleGroupName = roleGroupInfo.GetFullName()
+			},
+		),
+		clusterConfig: clusterConfig,
+		roleGroupInfo: roleGroupInfo,
+		mm2Config:     mm2Config,
+		source:        source,
+		target:        target,
+	}
+	return reconciler.NewStatefulSet(client, builder, stopped)
+}
+
+var _ builder.StatefulSetBuilder = &MirrorMaker2StatefulSetBuilder{}
+
+type MirrorMaker2StatefulSetBuilder struct {
+	builder.StatefulSet
+
+	clusterConfig *kafkav1alpha1.KafkaMirrorMaker2ClusterConfigSpec
+	roleGroupInfo *reconciler.RoleGroupInfo
+	mm2Config     *kafkav1alpha1.KafkaMirrorMaker2ConfigSpec
+	source        *MirrorMaker2Cluster
+	target        *MirrorMaker2Cluster
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) GetObject() (*appv1.StatefulSet, error) {
+	tpl, err := b.GetPodTemplate()
+	if err != nil {
+		return nil, err
+	}
+	obj := &appv1.StatefulSet{
+		ObjectMeta: b.GetObjectMeta(),
+		Spec: appv1.StatefulSetSpec{
+			Replicas:             b.GetReplicas(),
+			Selector:             b.GetLabelSelector(),
+			ServiceName:          b.GetName(),
+			Template:             *tpl,
+			VolumeClaimTemplates: b.GetVolumeClaimTemplates(),
+		},
+	}
+	return obj, nil
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	b.AddContainer(b.createMainContainer())
+
+	b.AddVolumes(b.volumes())
+	for _, cluster := range b.clusters() {
+		b.AddVolumes(cluster.Security.Volumes(MirrorMaker2KerberosServiceName, b.mm2Config.RequestedSecretLifeTime))
+	}
+
+	// vector
+	if IsVectorEnable(b.mm2Config.Logging) {
+		vectorFactory := GetVectorFactory(b.GetImage())
+		b.AddContainer(vectorFactory.GetContainer())
+		b.AddVolumes(vectorFactory.GetVolumes())
+	}
+
+	sts, err := b.GetObject()
+	if err != nil {
+		return nil, err
+	}
+
+	sts.Spec.Template.Spec.ServiceAccountName = ServiceAccountName(b.ClusterName)
+	// parallel pod management
+	sts.Spec.PodManagementPolicy = appv1.ParallelPodManagement
+
+	return sts, nil
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) clusters() []*MirrorMaker2Cluster {
+	return []*MirrorMaker2Cluster{b.source, b.target}
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) createMainContainer() *corev1.Container {
+	containerBuilder := builder.NewContainerBuilder(MirrorMaker2RoleName, b.GetImage()).
+		AddEnvVars(b.containerEnv()).
+		SetCommand([]string{"sh", "-c"}).
+		SetArgs(b.commandArgs()).
+		AddVolumeMounts(b.volumeMounts()).
+		SetResources(b.mm2Config.Resources).
+		SetLivenessProbe(&corev1.Probe{
+			FailureThreshold:    6,
+			InitialDelaySeconds: 30,
+			PeriodSeconds:       30,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      5,
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(kafkav1alpha1.ConnectRestPortName)},
+			},
+		}).
+		SetReadinessProbe(&corev1.Probe{
+			FailureThreshold:    3,
+			InitialDelaySeconds: 20,
+			PeriodSeconds:       10,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      3,
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(kafkav1alpha1.ConnectRestPortName)},
+			},
+		}).
+		AddPorts(ConnectRestContainerPorts()).
+		AddPorts([]corev1.ContainerPort{
+			{
+				Name:          kafkav1alpha1.MetricsPortName,
+				ContainerPort: kafkav1alpha1.MetricsPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		})
+	for _, cluster := range b.clusters() {
+		containerBuilder.AddVolumeMounts(cluster.Security.VolumeMounts())
+	}
+	return containerBuilder.Build()
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) containerEnv() []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{
+			Name: EnvPodName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		},
+		{
+			Name:  EnvKafkaLog4jOpts,
+			Value: fmt.Sprintf("-Dlog4j.configuration=file:%s/%s", kafkav1alpha1.KubedoopLogConfigDir, Log4jPropertiesFilename),
+		},
+		{
+			Name: EnvJvmArgs,
+			Value: fmt.Sprintf("-Djava.security.properties=%s/%s -javaagent:%s/jmx/jmx_prometheus_javaagent.jar=%d:%s/%s",
+				kafkav1alpha1.KubedoopConfigDir, SecurityPropertiesFilename,
+				kafkav1alpha1.KubedoopRoot, kafkav1alpha1.MetricsPort,
+				kafkav1alpha1.KubedoopConfigDir, MirrorMaker2JmxExporterFileName),
+		},
+	}
+
+	for _, cluster := range b.clusters() {
+		if cluster.KafkaCluster == nil {
+			continue
+		}
+		envs = append(envs, corev1.EnvVar{
+			Name: cluster.BootstrapServersEnvName(),
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cluster.KafkaCluster.Name,
+					},
+					Key: KafkaDiscoveryKey,
+				},
+			},
+		})
+	}
+
+	// the JVM reads a single krb5.conf, the one of the target cluster is preferred
+	for _, cluster := range []*MirrorMaker2Cluster{b.target, b.source} {
+		if cluster.Security.IsKerberosEnabled() {
+			envs = append(envs, cluster.Security.Envs()...)
+			break
+		}
+	}
+
+	if resources := b.mm2Config.Resources; resources != nil && resources.Memory != nil {
+		heap := fmt.Sprintf("-Xmx%dm", int(util.QuantityToMB(resources.Memory.Limit)*0.8))
+		envs = append(envs, corev1.EnvVar{Name: EnvKafkaHeapOpts, Value: heap})
+	}
+	return envs
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) commandArgs() []string {
+	podFqdn := util.PodFqdn(b.GetObjectMeta().Namespace, b.GetName())
+
+	var args []string
+	args = append(args, opgoutil.CommonBashTrapFunctions)
+	args = append(args, opgoutil.RemoveVectorShutdownFileCommand())
+	args = append(args, "prepare_signal_handlers")
+
+	// bootstrap servers of KafkaClusters and the advertised host are only known at runtime
+	args = append(args, fmt.Sprintf("cp %s/%s %s", kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.MirrorMaker2FileName, mirrorMaker2RuntimePropertiesPath))
+	args = append(args, fmt.Sprintf("echo \"rest.advertised.host.name=%s\" >> %s", podFqdn, mirrorMaker2RuntimePropertiesPath))
+	for _, cluster := range b.clusters() {
+		if cluster.KafkaCluster != nil {
+			args = append(args, fmt.Sprintf("echo \"%s.bootstrap.servers=${%s}\" >> %s", cluster.Alias, cluster.BootstrapServersEnvName(), mirrorMaker2RuntimePropertiesPath))
+		}
+		if cluster.Security.IsKerberosEnabled() {
+			// the principal is passed as printf argument, so the shell expands the realm of the cluster
+			realm := fmt.Sprintf("$(grep -oP 'default_realm = \\K.*' %s)", cluster.Security.Krb5Path())
+			principal := fmt.Sprintf("%s/%s@%s", MirrorMaker2KerberosServiceName, podFqdn, realm)
+			jaasConfig := cluster.Security.KerberosJaasConfig("%s")
+			args = append(args, fmt.Sprintf("printf '%s.sasl.jaas.config=%s\\n' \"%s\" >> %s", cluster.Alias, jaasConfig, principal, mirrorMaker2RuntimePropertiesPath))
+		}
+	}
+
+	// dedicated mode, the workers only run the flows into the target cluster
+	args = append(args, fmt.Sprintf("bin/connect-mirror-maker.sh %s --clusters %s &", mirrorMaker2RuntimePropertiesPath, b.target.Alias))
+	args = append(args, opgoutil.InvokeWaitForTermination)
+	args = append(args, opgoutil.CreateVectorShutdownFileCommand())
+
+	return []string{strings.Join(args, "\n")}
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) volumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      kafkav1alpha1.KubedoopConfigDirName,
+			MountPath: kafkav1alpha1.KubedoopConfigDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopLogDirName,
+			MountPath: kafkav1alpha1.KubedoopLogDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopLogConfigDirName,
+			MountPath: kafkav1alpha1.KubedoopLogConfigDir,
+		},
+	}
+}
+
+func (b *MirrorMaker2StatefulSetBuilder) volumes() []corev1.Volume {
+	configMapVolumeSource := corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: RoleGroupConfigMapName(b.roleGroupInfo),
+			},
+		},
+	}
+	return []corev1.Volume{
+		{
+			Name:         kafkav1alpha1.KubedoopLogDirName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopLogConfigDirName,
+			VolumeSource: configMapVolumeSource,
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopConfigDirName,
+			VolumeSource: configMapVolumeSource,
+		},
+	}
+}
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+	PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+)
+
+// monitorTargetLabels are copied from the Service or Pod to the scraped metrics,
+// as `app_kubernetes_io_instance` etc., the alerts select the metrics of a cluster by them
+var monitorTargetLabels = []string{
+	constants.LabelKubernetesInstance,
+	constants.LabelKubernetesComponent,
+	constants.LabelKubernetesRoleGroup,
+}
+
+var _ reconciler.Reconciler = &PrometheusResourceReconciler{}
+
+// PrometheusResourceReconciler creates or updates a Prometheus Operator object.
+// The Prometheus Operator is optional, so the object is skipped if its CRD is not installed.
+// The objects are unstructured to not depend on the Prometheus Operator API.
+type PrometheusResourceReconciler struct {
+	client *client.Client
+	name   string
+	gvk    schema.GroupVersionKind
+	labels map[string]string
+	spec   any
+}
+
+func NewPrometheusResourceReconciler(
+	client *client.Client,
+	name string,
+	gvk schema.GroupVersionKind,
+	labels map[string]string,
+	spec any,
+) *PrometheusResourceReconciler {
+	return &PrometheusResourceReconciler{
+		client: client,
+		name:   name,
+		gvk:    gvk,
+		labels: labels,
+		spec:   spec,
+	}
+}
+
+func (r *PrometheusResourceReconciler) GetName() string {
+	return r.name
+}
+
+func (r *PrometheusResourceReconciler) GetNamespace() string {
+	return r.client.GetOwnerNamespace()
+}
+
+func (r *PrometheusResourceReconciler) GetClient() *client.Client {
+	return r.client
+}
+
+func (r *PrometheusResourceReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	installed, err := isKindInstalled(r.client.Client, r.gvk)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !installed {
+		logger.V(1).Info("CRD is not installed, skipping", "kind", r.gvk.Kind, "name", r.name, "namespace", r.GetNamespace())
+		return ctrl.Result{}, nil
+	}
+
+	// round trip through json, unstructured objects only hold json types
+	rawSpec, err := json.Marshal(r.spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	spec := map[string]any{}
+	if err := json.Unmarshal(rawSpec, &spec); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(r.gvk)
+	obj.SetName(r.name)
+	obj.SetNamespace(r.GetNamespace())
+	result, err := controllerutil.CreateOrUpdate(ctx, r.client.Client, obj, func() error {
+		obj.SetLabels(r.labels)
+		obj.Object["spec"] = spec
+		return controllerutil.SetControllerReference(r.client.GetOwnerReference(), obj, r.client.GetCtrlScheme())
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if result != controllerutil.OperationResultNone {
+		logger.Info("Resource created or updated", "kind", r.gvk.Kind, "name", r.name, "namespace", r.GetNamespace(), "result", result)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *PrometheusResourceReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
+
+// isKindInstalled returns whether the API server serves the kind, i.e. its CRD is installed
+func isKindInstalled(c ctrlclient.Client, gvk schema.GroupVersionKind) (bool, error) {
+	if _, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// NewRoleGroupMonitorReconciler returns a ServiceMonitor targeting the `-metrics` Service of the role group,
+// or a PodMonitor targeting its pods. It returns nil if no monitor is configured.
+func NewRoleGroupMonitorReconciler(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	monitoring *kafkav1alpha1.MonitoringSpec,
+	metrics *kafkav1alpha1.MetricsSpec,
+) reconciler.Reconciler {
+	if monitoring == nil || !IsMetricsEnabled(metrics) {
+		return nil
+	}
+
+	endpoint := map[string]any{
+		"port":   kafkav1alpha1.MetricsPortName,
+		"path":   "/metrics",
+		"scheme": GetMetricsScheme(metrics),
+	}
+	if monitoring.ScrapeInterval != "" {
+		endpoint["interval"] = monitoring.ScrapeInterval
+	}
+	if IsMetricsTlsEnabled(metrics) {
+		// the certificate is issued by the secret operator, whose CA is unknown to Prometheus
+		endpoint["tlsConfig"] = map[string]any{"insecureSkipVerify": true}
+	}
+
+	labels := roleGroupInfo.GetLabels()
+	maps.Copy(labels, monitoring.Labels)
+	name := CreateServiceMetricsName(roleGroupInfo)
+
+	switch monitoring.Monitor {
+	case kafkav1alpha1.MonitorKindServiceMonitor:
+		return NewPrometheusResourceReconciler(client, name, ServiceMonitorGVK, labels, map[string]any{
+			"selector":          map[string]any{"matchLabels": roleGroupInfo.GetLabels()},
+			"namespaceSelector": map[string]any{"matchNames": []string{client.GetOwnerNamespace()}},
+			"targetLabels":      monitorTargetLabels,
+			"endpoints":         []any{endpoint},
+		})
+	case kafkav1alpha1.MonitorKindPodMonitor:
+		return NewPrometheusResourceReconciler(client, name, PodMonitorGVK, labels, map[string]any{
+			"selector":            map[string]any{"matchLabels": roleGroupInfo.GetLabels()},
+			"namespaceSelector":   map[string]any{"matchNames": []string{client.GetOwnerNamespace()}},
+			"podTargetLabels":     monitorTargetLabels,
+			"podMetricsEndpoints": []any{endpoint},
+		})
+	default:
+		return nil
+	}
+}
+
+// NewKafkaPrometheusRuleReconciler returns a PrometheusRule `<clusterName>` with alerts on the health of the cluster.
+// The alerts select the metrics by the labels added by the monitors, see monitorTargetLabels.
+func NewKafkaPrometheusRuleReconciler(
+	client *client.Client,
+	clusterName string,
+	monitoring *kafkav1alpha1.MonitoringSpec,
+) reconciler.Reconciler {
+	alerts := monitoring.Alerts
+	namespace := client.GetOwnerNamespace()
+	selector := fmt.Sprintf(`namespace=%q,app_kubernetes_io_instance=%q`, namespace, clusterName)
+	volumeSelector := fmt.Sprintf(`namespace=%q,persistentvolumeclaim=~%q`,
+		namespace, fmt.Sprintf("%s-%s-%s-.+", kafkav1alpha1.KubedoopKafkaDataDirName, clusterName, RoleName))
+
+	rule := func(alert, expr, severity, summary, description string) map[string]any {
+		r := map[string]any{
+			"alert":       alert,
+			"expr":        expr,
+			"labels":      map[string]string{"severity": severity, "kafka_cluster": clusterName},
+			"annotations": map[string]string{"summary": summary, "description": description},
+		}
+		if alerts.For != "" {
+			r["for"] = alerts.For
+		}
+		return r
+	}
+
+	rules := []any{
+		rule(
+			"KafkaOfflinePartitions",
+			fmt.Sprintf(`sum(kafka_controller_kafkacontroller_offlinepartitionscount{%s}) > %d`, selector, alerts.OfflinePartitionsThreshold),
+			"critical",
+			"Kafka cluster has offline partitions",
+			"{{ $value }} partitions of the Kafka cluster have no active leader.",
+		),
+		rule(
+			"KafkaUnderReplicatedPartitions",
+			fmt.Sprintf(`kafka_server_replicamanager_underreplicatedpartitions{%s} > %d`, selector, alerts.UnderReplicatedPartitionsThreshold),
+			"warning",
+			"Kafka broker has under-replicated partitions",
+			"Broker {{ $labels.pod }} has {{ $value }} under-replicated partitions.",
+		),
+		rule(
+			"KafkaActiveControllerCount",
+			fmt.Sprintf(`sum(kafka_controller_kafkacontroller_activecontrollercount{%s}) != 1`, selector),
+			"critical",
+			"Kafka cluster does not have exactly one active controller",
+			"The Kafka cluster has {{ $value }} active controllers.",
+		),
+		rule(
+			"KafkaDiskUsage",
+			fmt.Sprintf(`100 * kubelet_volume_stats_used_bytes{%s} / kubelet_volume_stats_capacity_bytes{%s} > %d`,
+				volumeSelector, volumeSelector, alerts.DiskUsagePercent),
+			"warning",
+			"Kafka broker data volume is filling up",
+			"The data volume {{ $labels.persistentvolumeclaim }} is {{ $value | humanize }}% full.",
+		),
+		rule(
+			"KafkaRequestHandlerIdleRatio",
+			fmt.Sprintf(`kafka_server_kafkarequesthandlerpool_requesthandleravgidlepercent{%s} < %g`,
+				selector, float64(alerts.RequestHandlerIdlePercent)/100),
+			"warning",
+			"Kafka broker request handler threads are busy",
+			"The request handler threads of broker {{ $labels.pod }} are idle {{ $value | humanizePercentage }} of the time.",
+		),
+	}
+
+	labels := maps.Clone(client.GetOwnerReference().GetLabels())
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, monitoring.Labels)
+
+	return NewPrometheusResourceReconciler(client, clusterName, PrometheusRuleGVK, labels, map[string]any{
+		"groups": []any{
+			map[string]any{
+				"name":  "kafka-" + clusterName,
+				"rules": rules,
+			},
+		},
+	})
+}
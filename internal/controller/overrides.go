@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reservedEnvVars are set by the operator and read by the launch command, overriding them breaks the broker
+var reservedEnvVars = []string{
+	EnvPodName,
+	EnvNode,
+	EnvZookeeperConnections,
+	EnvBrokerId,
+	EnvBrokerRack,
+	EnvKerberosRealm,
+}
+
+// reservedCliProperties are passed as `--override` by the launch command, they depend on the pod and its listeners
+var reservedCliProperties = []string{
+	"broker.id",
+	"broker.rack",
+	"zookeeper.connect",
+	"listeners",
+	"advertised.listeners",
+	"listener.security.protocol.map",
+	InterBrokerProtocolVersionProperty,
+}
+
+// validateOverrides checks the env and cli overrides of a role group do not clobber what the operator relies on.
+// Every cli override is a `key=value` Kafka property.
+func validateOverrides(roleGroup string, overrides *commonsv1alpha1.OverridesSpec) error {
+	if overrides == nil {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(overrides.EnvOverrides)) {
+		if slices.Contains(reservedEnvVars, name) {
+			return fmt.Errorf("%w: role group %s overrides env %s set by the operator", ErrInvalidOverride, roleGroup, name)
+		}
+	}
+	for _, arg := range overrides.CliOverrides {
+		key, _, ok := strings.Cut(arg, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%w: role group %s cli override %q is not a key=value property", ErrInvalidOverride, roleGroup, arg)
+		}
+		if slices.Contains(reservedCliProperties, strings.TrimSpace(key)) {
+			return fmt.Errorf("%w: role group %s cli override %s is set by the operator", ErrInvalidOverride, roleGroup, key)
+		}
+	}
+	return nil
+}
+
+// applyEnvOverrides replaces the value of the env vars in envOverrides, the remaining ones are appended in sorted order
+func applyEnvOverrides(envs []corev1.EnvVar, envOverrides map[string]string) []corev1.EnvVar {
+	if len(envOverrides) == 0 {
+		return envs
+	}
+	for i := range envs {
+		if value, ok := envOverrides[envs[i].Name]; ok {
+			envs[i] = corev1.EnvVar{Name: envs[i].Name, Value: value}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(envOverrides)) {
+		if !slices.ContainsFunc(envs, func(env corev1.EnvVar) bool { return env.Name == name }) {
+			envs = append(envs, corev1.EnvVar{Name: name, Value: envOverrides[name]})
+		}
+	}
+	return envs
+}
+
+// cliOverrideArgs renders the cli overrides as `--override` arguments of kafka-server-start.sh.
+// They are single quoted, so the shell passes them on literally without expanding variables or commands.
+func cliOverrideArgs(cliOverrides []string) string {
+	var sb strings.Builder
+	for _, arg := range cliOverrides {
+		fmt.Fprintf(&sb, `--override '%s' `, strings.ReplaceAll(arg, `'`, `'\''`))
+	}
+	return sb.String()
+}
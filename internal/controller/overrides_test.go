@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"os/exec"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cliOverrideArgs", func() {
+	It("passes the values on to the broker literally", func() {
+		overrides := []string{
+			"log.retention.hours=168",
+			"ssl.principal.mapping.rules=RULE:^CN=(.*?),OU=.*$/$1/,DEFAULT",
+			"client.id=$(echo injected)`echo injected`${POD_NAME}",
+			`sasl.jaas.config=org.example.Login required user="it's me" password='\secret';`,
+		}
+
+		// the shell parses the arguments like in the broker command
+		cmd := exec.Command("sh", "-c", `printf '%s\n' `+cliOverrideArgs(overrides))
+		cmd.Env = []string{"POD_NAME=kafka-broker-default-0"}
+		out, err := cmd.Output()
+
+		Expect(err).NotTo(HaveOccurred())
+		var expected []string
+		for _, override := range overrides {
+			expected = append(expected, "--override", override)
+		}
+		Expect(strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")).To(Equal(expected))
+	})
+})
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultBrokerMaxUnavailable is the maxUnavailable of the broker PodDisruptionBudget with the Fixed policy
+	DefaultBrokerMaxUnavailable int32 = 1
+
+	ReplicationFactorKey = "default.replication.factor"
+	MinInsyncReplicasKey = "min.insync.replicas"
+)
+
+// replicationSetting is the replication factor and minimum ISR of topics created by the brokers, with their Kafka defaults
+type replicationSetting struct {
+	replicationFactorKey     string
+	defaultReplicationFactor int32
+	minInsyncReplicasKey     string
+	defaultMinInsyncReplicas int32
+}
+
+var replicationSettings = []replicationSetting{
+	// topics created without a replication factor
+	{ReplicationFactorKey, 1, MinInsyncReplicasKey, 1},
+	// the committed offsets of the consumer groups
+	{"offsets.topic.replication.factor", 3, MinInsyncReplicasKey, 1},
+	// the state of the transactions
+	{"transaction.state.log.replication.factor", 3, "transaction.state.log.min.isr", 2},
+}
+
+// NewBrokerPDBReconciler creates the PodDisruptionBudget `<cluster>-broker` covering the pods of all broker role groups.
+// It merge patches the PodDisruptionBudget, the three-way patch of the generic reconciler always replaces its selector
+// and so requeues every reconciliation before the resources registered after it.
+func NewBrokerPDBReconciler(
+	client *client.Client,
+	roleInfo reconciler.RoleInfo,
+	maxUnavailable int32,
+) (*reconciler.PDB, error) {
+	b, err := builder.NewDefaultPDBBuilder(
+		client,
+		roleInfo.GetFullName(),
+		func(opt *builder.PDBBuilderOptions) {
+			opt.Labels = roleInfo.GetLabels()
+			opt.Annotations = roleInfo.GetAnnotations()
+			opt.MaxUnavailableAmount = &maxUnavailable
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &reconciler.PDB{
+		GenericResourceReconciler: *reconciler.NewGenericResourceReconciler[builder.PodDisruptionBudgetBuilder](
+			client,
+			&brokerPDBBuilder{DefaultPDBBuilder: b},
+		),
+	}, nil
+}
+
+// brokerPDBBuilder sets the owner reference, the PDB reconciler creates the PodDisruptionBudget without one
+type brokerPDBBuilder struct {
+	*builder.DefaultPDBBuilder
+}
+
+func (b *brokerPDBBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	obj, err := b.DefaultPDBBuilder.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := client.GetObjectGVK(b.Client.GetCtrlClient().Scheme(), obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Client.SetOwnerReference(obj, gvk); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// brokerMaxUnavailable returns maxUnavailable of the broker PodDisruptionBudget, or false if none should be created.
+// serverProperties are the merged `server.properties` overrides of each role group.
+func (r *BrokerReconciler) brokerMaxUnavailable(serverProperties []map[string]string) (int32, bool, error) {
+	if r.Spec.Roleconfig != nil && r.Spec.Roleconfig.PodDisruptionBudget != nil {
+		pdb := r.Spec.Roleconfig.PodDisruptionBudget
+		if !pdb.Enabled {
+			return 0, false, nil
+		}
+		if pdb.MaxUnavailable != nil {
+			return *pdb.MaxUnavailable, true, nil
+		}
+	}
+
+	if r.Spec.PodDisruptionBudgetPolicy != kafkav1alpha1.PodDisruptionBudgetPolicyDurability {
+		return DefaultBrokerMaxUnavailable, true, nil
+	}
+	margin, err := durabilityMargin(serverProperties)
+	if err != nil {
+		return 0, false, err
+	}
+	// a PodDisruptionBudget without any allowed disruption blocks every node drain
+	if margin < 1 {
+		r.recorder.Warning(r.Client.OwnerReference, EventReasonNoReplicationMargin, "Validate",
+			"podDisruptionBudgetPolicy Durability needs the replication factors above the minimum ISR, e.g. %s above %s, "+
+				"allowing %d unavailable broker", ReplicationFactorKey, MinInsyncReplicasKey, DefaultBrokerMaxUnavailable)
+		return DefaultBrokerMaxUnavailable, true, nil
+	}
+	return margin, true, nil
+}
+
+// durabilityMargin returns the lowest margin of replication factor over minimum ISR of the topics the brokers create,
+// so evicting that many brokers keeps every partition at or above its minimum ISR. The settings of the role groups
+// are their server.properties overrides, falling back to the Kafka defaults, so a cluster without overrides has no
+// margin. Only the broker settings are known: topics created with their own replication factor or min.insync.replicas
+// and values set by serverPropertiesFrom are not considered.
+func durabilityMargin(serverProperties []map[string]string) (int32, error) {
+	// without any role group the Kafka defaults apply
+	if len(serverProperties) == 0 {
+		serverProperties = []map[string]string{nil}
+	}
+	var lowest int32
+	for i, properties := range serverProperties {
+		for j, setting := range replicationSettings {
+			replicationFactor, err := int32Property(properties, setting.replicationFactorKey, setting.defaultReplicationFactor)
+			if err != nil {
+				return 0, err
+			}
+			minInsyncReplicas, err := int32Property(properties, setting.minInsyncReplicasKey, setting.defaultMinInsyncReplicas)
+			if err != nil {
+				return 0, err
+			}
+			if margin := replicationFactor - minInsyncReplicas; i == 0 && j == 0 || margin < lowest {
+				lowest = margin
+			}
+		}
+	}
+	return lowest, nil
+}
+
+func int32Property(properties map[string]string, key string, defaultValue int32) (int32, error) {
+	value, ok := properties[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q in server.properties overrides: %w", key, value, err)
+	}
+	return int32(parsed), nil
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+)
+
+var _ = Describe("durabilityMargin", func() {
+	DescribeTable("returns the lowest margin of the topics the brokers create",
+		func(serverProperties []map[string]string, expected int32) {
+			margin, err := durabilityMargin(serverProperties)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(margin).To(Equal(expected))
+		},
+		Entry("the Kafka defaults without role groups", nil, int32(0)),
+		Entry("the Kafka defaults", []map[string]string{{}}, int32(0)),
+		Entry("a default replication factor above the minimum ISR",
+			[]map[string]string{{ReplicationFactorKey: "3", MinInsyncReplicasKey: "2"}}, int32(1)),
+		Entry("the internal topics keeping their default replication factor of 3",
+			[]map[string]string{{ReplicationFactorKey: "5", MinInsyncReplicasKey: "1", "transaction.state.log.min.isr": "1"}}, int32(2)),
+		Entry("the transaction topic with its own minimum ISR",
+			[]map[string]string{{ReplicationFactorKey: "5", MinInsyncReplicasKey: "1", "offsets.topic.replication.factor": "5"}}, int32(1)),
+		Entry("all topics replicated above their minimum ISR",
+			[]map[string]string{{
+				ReplicationFactorKey:                       "5",
+				MinInsyncReplicasKey:                       "2",
+				"offsets.topic.replication.factor":         "5",
+				"transaction.state.log.replication.factor": "5",
+				"transaction.state.log.min.isr":            "2",
+			}}, int32(3)),
+		Entry("the lowest margin of all role groups",
+			[]map[string]string{
+				{ReplicationFactorKey: "3", MinInsyncReplicasKey: "1", "transaction.state.log.min.isr": "1"},
+				{ReplicationFactorKey: "3", MinInsyncReplicasKey: "2"},
+			}, int32(1)),
+	)
+
+	It("fails on invalid values", func() {
+		_, err := durabilityMargin([]map[string]string{{MinInsyncReplicasKey: "two"}})
+		Expect(err).To(MatchError(ContainSubstring(`invalid min.insync.replicas "two"`)))
+	})
+})
+
+var _ = Describe("brokerMaxUnavailable", func() {
+	var (
+		fake   *events.FakeRecorder
+		broker *BrokerReconciler
+	)
+
+	BeforeEach(func() {
+		fake = events.NewFakeRecorder(10)
+		cluster := &kafkav1alpha1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "default"}}
+		broker = &BrokerReconciler{
+			BaseRoleReconciler: reconciler.BaseRoleReconciler[*kafkav1alpha1.BrokersSpec]{
+				BaseReconciler: reconciler.BaseReconciler[*kafkav1alpha1.BrokersSpec]{
+					Client: &client.Client{OwnerReference: cluster},
+					Spec:   &kafkav1alpha1.BrokersSpec{},
+				},
+			},
+			recorder: event.NewRecorder(fake, time.Minute),
+		}
+	})
+
+	durable := []map[string]string{{
+		ReplicationFactorKey:                       "4",
+		MinInsyncReplicasKey:                       "2",
+		"offsets.topic.replication.factor":         "4",
+		"transaction.state.log.replication.factor": "4",
+	}}
+
+	It("allows one unavailable broker with the Fixed policy", func() {
+		maxUnavailable, enabled, err := broker.brokerMaxUnavailable(durable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enabled).To(BeTrue())
+		Expect(maxUnavailable).To(Equal(DefaultBrokerMaxUnavailable))
+	})
+
+	It("allows the replication margin with the Durability policy", func() {
+		broker.Spec.PodDisruptionBudgetPolicy = kafkav1alpha1.PodDisruptionBudgetPolicyDurability
+
+		maxUnavailable, enabled, err := broker.brokerMaxUnavailable(durable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enabled).To(BeTrue())
+		Expect(maxUnavailable).To(BeEquivalentTo(2))
+		Expect(fake.Events).To(BeEmpty())
+	})
+
+	It("warns and allows one unavailable broker without a replication margin", func() {
+		broker.Spec.PodDisruptionBudgetPolicy = kafkav1alpha1.PodDisruptionBudgetPolicyDurability
+
+		maxUnavailable, enabled, err := broker.brokerMaxUnavailable([]map[string]string{{}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enabled).To(BeTrue())
+		Expect(maxUnavailable).To(Equal(DefaultBrokerMaxUnavailable))
+		Expect(fake.Events).To(Receive(ContainSubstring("Warning " + EventReasonNoReplicationMargin)))
+	})
+
+	It("prefers maxUnavailable of the role config over the policy", func() {
+		broker.Spec.PodDisruptionBudgetPolicy = kafkav1alpha1.PodDisruptionBudgetPolicyDurability
+		broker.Spec.Roleconfig = &commonsv1alpha1.RoleConfigSpec{
+			PodDisruptionBudget: &commonsv1alpha1.PodDisruptionBudgetSpec{Enabled: true, MaxUnavailable: ptr.To[int32](3)},
+		}
+
+		maxUnavailable, enabled, err := broker.brokerMaxUnavailable(durable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enabled).To(BeTrue())
+		Expect(maxUnavailable).To(BeEquivalentTo(3))
+	})
+
+	It("creates no PodDisruptionBudget if it is disabled", func() {
+		broker.Spec.Roleconfig = &commonsv1alpha1.RoleConfigSpec{
+			PodDisruptionBudget: &commonsv1alpha1.PodDisruptionBudgetSpec{Enabled: false},
+		}
+
+		_, enabled, err := broker.brokerMaxUnavailable(durable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enabled).To(BeFalse())
+	})
+})
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+// KafkaRacksDiscoveryKey lists the racks of the brokers, clients can pick one as `client.rack` to fetch from the closest replica
+const KafkaRacksDiscoveryKey = "KAFKA_RACKS"
+
+// rackWaitTimeout is how long a broker waits for the rack of its node before it starts without one
+const rackWaitTimeout = 5 * time.Minute
+
+// kafkaClusterKind is the `app.kubernetes.io/name` label of the resources of a KafkaCluster
+const kafkaClusterKind = "kafkacluster"
+
+// BrokerPodLabels selects the broker pods of the cluster
+func BrokerPodLabels(clusterName string) map[string]string {
+	return map[string]string{
+		opconstants.LabelKubernetesName:      kafkaClusterKind,
+		opconstants.LabelKubernetesInstance:  clusterName,
+		opconstants.LabelKubernetesComponent: RoleName,
+	}
+}
+
+// ManagedPodSelector selects the pods of the workloads and Jobs of the operator, the only pods it reads.
+// The pod cache is restricted to them, the rack assignment watches pods and would otherwise cache every pod.
+func ManagedPodSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{opconstants.LabelKubernetesManagedBy: kafkav1alpha1.GroupVersion.Group})
+}
+
+// rackSpreadConstraints spreads the brokers evenly across the racks
+func rackSpreadConstraints(rackAwareness *kafkav1alpha1.RackAwarenessSpec, clusterName string) []corev1.TopologySpreadConstraint {
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       rackAwareness.NodeLabel,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     defaultAffinityLabelSelector(RoleName, clusterName),
+		},
+	}
+}
+
+// rackVolume exposes the rack annotation to the broker, the kubelet updates the file once the operator set it
+func rackVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: kafkav1alpha1.KubedoopRackDirName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{
+					{
+						Path:     kafkav1alpha1.RackFileName,
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations['" + kafkav1alpha1.RackAnnotation + "']"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// AssignRacks annotates the scheduled broker pods of the cluster with the value of the rack awareness label of their node.
+// It returns the names of the nodes missing the label, their pods start without a rack after rackWaitTimeout.
+func AssignRacks(ctx context.Context, c ctrlclient.Client, instance *kafkav1alpha1.KafkaCluster) ([]string, error) {
+	rackAwareness := instance.Spec.ClusterConfig.RackAwareness
+	if rackAwareness == nil {
+		return nil, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, ctrlclient.InNamespace(instance.Namespace), ctrlclient.MatchingLabels(BrokerPodLabels(instance.Name))); err != nil {
+		return nil, err
+	}
+
+	var unlabeledNodes []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := c.Get(ctx, ctrlclient.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+			return nil, err
+		}
+		rack, ok := node.Labels[rackAwareness.NodeLabel]
+		if !ok || rack == "" {
+			if !slices.Contains(unlabeledNodes, node.Name) {
+				unlabeledNodes = append(unlabeledNodes, node.Name)
+			}
+			continue
+		}
+		if pod.Annotations[kafkav1alpha1.RackAnnotation] == rack {
+			continue
+		}
+
+		patch := ctrlclient.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[kafkav1alpha1.RackAnnotation] = rack
+		if err := c.Patch(ctx, pod, patch); err != nil {
+			return nil, err
+		}
+		logger.V(1).Info("assigned rack to broker pod", "namespace", pod.Namespace, "pod", pod.Name, "rack", rack)
+	}
+	return unlabeledNodes, nil
+}
+
+// BrokerRacks returns the sorted, distinct racks assigned to the broker pods of the cluster
+func BrokerRacks(ctx context.Context, c ctrlclient.Reader, namespace, clusterName string) ([]string, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabels(BrokerPodLabels(clusterName))); err != nil {
+		return nil, err
+	}
+	var racks []string
+	for _, pod := range pods.Items {
+		if rack := pod.Annotations[kafkav1alpha1.RackAnnotation]; rack != "" && !slices.Contains(racks, rack) {
+			racks = append(racks, rack)
+		}
+	}
+	slices.Sort(racks)
+	return racks, nil
+}
+
+// brokerPodToCluster maps a broker pod to its KafkaCluster
+func brokerPodToCluster(_ context.Context, obj ctrlclient.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[opconstants.LabelKubernetesName] != kafkaClusterKind || labels[opconstants.LabelKubernetesComponent] != RoleName {
+		return nil
+	}
+	name := labels[opconstants.LabelKubernetesInstance]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+// podScheduled only passes broker pods being bound to a node, they have to be assigned a rack
+var podScheduled = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		pod, ok := e.Object.(*corev1.Pod)
+		return ok && pod.Spec.NodeName != ""
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, ok := e.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		newPod, ok := e.ObjectNew.(*corev1.Pod)
+		return ok && oldPod.Spec.NodeName != newPod.Spec.NodeName
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+)
+
+// maxRenderReconciles bounds the reconciliations of RenderResources, a resource changing on every reconciliation never settles
+const maxRenderReconciles = 100
+
+// RenderResources reconciles the resources RegisterResources registers for the cluster with c, e.g. a fake client.
+// Unlike KafkaClusterReconciler it neither writes the status nor waits for the resources to be ready,
+// so the resources are the ones the operator creates on its first reconciliation of the cluster.
+// The broker ID offsets the status would keep are set in instance.
+func RenderResources(ctx context.Context, c ctrlclient.Client, instance *kafkav1alpha1.KafkaCluster, recorder *event.Recorder) error {
+	// an upgrade in progress in the status keeps the protocol version of the brokers pinned
+	protocolVersion, err := startUpgrade(instance.Status.DeepCopy(), NewImage(instance.Spec.Image).ProductVersion)
+	if err != nil {
+		return err
+	}
+
+	// a reconciliation stops at the first resource it creates or updates, so it is repeated until nothing changes.
+	// Like in KafkaClusterReconciler, the resources are registered again for every reconciliation.
+	gvk := instance.GetObjectKind().GroupVersionKind()
+	for range maxRenderReconciles {
+		clusterReconciler := NewClusterReconciler(
+			&client.Client{Client: c, OwnerReference: instance},
+			reconciler.ClusterInfo{
+				GVK: &metav1.GroupVersionKind{
+					Group:   gvk.Group,
+					Version: gvk.Version,
+					Kind:    gvk.Kind,
+				},
+				ClusterName: instance.Name,
+			},
+			&instance.Spec,
+			recorder,
+			protocolVersion,
+		)
+		if err := clusterReconciler.RegisterResources(ctx); err != nil {
+			return err
+		}
+		// KafkaClusterReconciler keeps the broker ID offsets in the status before the StatefulSets are created
+		instance.Status.BrokerIdOffsets = clusterReconciler.BrokerIdOffsets()
+		result, err := clusterReconciler.Reconcile(ctx)
+		if err != nil {
+			return err
+		}
+		if result.IsZero() {
+			return nil
+		}
+	}
+	return fmt.Errorf("the resources of KafkaCluster %s did not settle after %d reconciliations", instance.Name, maxRenderReconciles)
+}
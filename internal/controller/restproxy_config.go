@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const (
+	RestProxyRoleName = "rest-proxy"
+)
+
+var _ OverrideConfiguration = &RestProxyConfig{}
+
+type RestProxyConfig struct {
+	commonsv1alpha1.RoleGroupConfigSpec
+
+	ListenerClass string
+
+	RequestedSecretLifetime string
+}
+
+// ComputeCli implements OverrideConfiguration.
+func (c *RestProxyConfig) ComputeCli() ([]string, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ComputeEnv implements OverrideConfiguration.
+func (c *RestProxyConfig) ComputeEnv() (map[string]string, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ComputeFile implements OverrideConfiguration.
+func (c *RestProxyConfig) ComputeFile() (map[string]map[string]string, error) {
+	return map[string]map[string]string{
+		kafkav1alpha1.RestProxyFileName: {
+			"consumer.instance.timeout.ms": "300000",
+			"compression.enable":           "true",
+		},
+		SecurityPropertiesFilename: {
+			"networkaddress.cache.ttl":          "30",
+			"networkaddress.cache.negative.ttl": "0",
+		},
+	}, nil
+}
+
+func DefaultRestProxyConfig(clusterName string) RestProxyConfig {
+	rawAffinity, err := json.Marshal(defaultAffinity(RestProxyRoleName, clusterName))
+	if err != nil {
+		clusterConfigLogger.Error(err, "Failed to marshal affinity")
+	}
+
+	return RestProxyConfig{
+		RoleGroupConfigSpec: commonsv1alpha1.RoleGroupConfigSpec{
+			Affinity: &runtime.RawExtension{
+				Raw: rawAffinity,
+			},
+			GracefulShutdownTimeout: "30s",
+			Logging: &commonsv1alpha1.LoggingSpec{
+				EnableVectorAgent: ptr.To(false),
+				Containers:        nil,
+			},
+			Resources: &commonsv1alpha1.ResourcesSpec{
+				CPU: &commonsv1alpha1.CPUResource{
+					Max: resource.MustParse("500m"),
+					Min: resource.MustParse("100m"),
+				},
+				Memory: &commonsv1alpha1.MemoryResource{
+					Limit: resource.MustParse("768Mi"),
+				},
+			},
+		},
+		ListenerClass:           "cluster-internal",
+		RequestedSecretLifetime: "1d",
+	}
+}
+
+// MergeFromUserRestProxyConfig merges the default REST proxy configuration into the user provided configuration
+func MergeFromUserRestProxyConfig(
+	userConfig *kafkav1alpha1.RestProxyConfigSpec,
+	userOverrides *commonsv1alpha1.OverridesSpec,
+	clusterName string,
+) error {
+	defaultConfig := DefaultRestProxyConfig(clusterName)
+
+	if userConfig.RoleGroupConfigSpec == nil {
+		userConfig.RoleGroupConfigSpec = &commonsv1alpha1.RoleGroupConfigSpec{
+			Affinity:                defaultConfig.Affinity,
+			GracefulShutdownTimeout: defaultConfig.GracefulShutdownTimeout,
+			Logging:                 defaultConfig.Logging,
+			Resources:               defaultConfig.Resources,
+		}
+	}
+	if userConfig.Affinity == nil {
+		userConfig.Affinity = defaultConfig.Affinity
+	}
+	if userConfig.Logging == nil {
+		userConfig.Logging = defaultConfig.Logging
+	}
+	if userConfig.Resources == nil {
+		userConfig.Resources = defaultConfig.Resources
+	} else {
+		mergeResources(userConfig.Resources, defaultConfig.Resources)
+	}
+
+	if userConfig.ListenerClass == "" {
+		userConfig.ListenerClass = defaultConfig.ListenerClass
+	}
+	if userConfig.RequestedSecretLifeTime == "" {
+		userConfig.RequestedSecretLifeTime = defaultConfig.RequestedSecretLifetime
+	}
+
+	return mergeOverrides(userOverrides, &defaultConfig)
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"maps"
+	"strconv"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/config/properties"
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+const (
+	RestProxyLog4jFilename = "rest-proxy.log4j.xml"
+
+	// restProxyClientPrefix is the prefix of the properties of the Kafka clients used by the REST proxy
+	restProxyClientPrefix = "client."
+)
+
+func NewRestProxyConfigmapReconciler(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	clusterConfig *kafkav1alpha1.ClusterConfigSpec,
+	clientSecurity *security.ClientSecurity,
+	overrides *commonsv1alpha1.OverridesSpec,
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+) reconciler.ResourceReconciler[builder.ConfigBuilder] {
+	builder := NewRestProxyConfigmapBuilder(
+		client,
+		roleGroupInfo,
+		clusterConfig,
+		clientSecurity,
+		overrides,
+		roleGroupConfig,
+	)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
+
+func NewRestProxyConfigmapBuilder(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	clusterConfig *kafkav1alpha1.ClusterConfigSpec,
+	clientSecurity *security.ClientSecurity,
+	overrides *commonsv1alpha1.OverridesSpec,
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+) builder.ConfigBuilder {
+	return &RestProxyConfigmapBuilder{
+		ConfigMapBuilder: *builder.NewConfigMapBuilder(
+			client,
+			roleGroupInfo.GetFullName(),
+			func(o *builder.Options) {
+				o.Labels = roleGroupInfo.GetLabels()
+				o.Annotations = roleGroupInfo.GetAnnotations()
+			},
+		),
+		clusterConfig:   clusterConfig,
+		clientSecurity:  clientSecurity,
+		overrides:       overrides,
+		roleGroupConfig: roleGroupConfig,
+		roleGroupInfo:   roleGroupInfo,
+	}
+}
+
+type RestProxyConfigmapBuilder struct {
+	builder.ConfigMapBuilder
+
+	clusterConfig   *kafkav1alpha1.ClusterConfigSpec
+	clientSecurity  *security.ClientSecurity
+	overrides       *commonsv1alpha1.OverridesSpec
+	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec
+	roleGroupInfo   *reconciler.RoleGroupInfo
+}
+
+func (b *RestProxyConfigmapBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	propertyFiles := map[string]func() (string, error){
+		kafkav1alpha1.RestProxyFileName: b.buildRestProxyProperties, // kafka-rest.properties
+		SecurityPropertiesFilename:      b.buildSecurityProperties,  // security.properties
+		Log4jPropertiesFilename:         b.buildLog4jProperties,     // log4j.properties
+	}
+
+	for filename, builder := range propertyFiles {
+		content, err := builder()
+		if err != nil {
+			return nil, err
+		}
+		if content != "" {
+			b.AddItem(filename, content)
+		}
+	}
+
+	// vector config
+	if IsVectorEnable(b.roleGroupConfig.Logging) {
+		if b.clusterConfig.VectorAggregatorConfigMapName == "" {
+			return nil, ErrVectorAggregatorNotSet
+		}
+		vectorConfig, err := productlogging.MakeVectorYaml(
+			ctx,
+			b.Client.Client,
+			b.Client.GetOwnerNamespace(),
+			b.roleGroupInfo.ClusterName,
+			b.roleGroupInfo.RoleName,
+			b.roleGroupInfo.RoleGroupName,
+			b.clusterConfig.VectorAggregatorConfigMapName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		b.AddItem(VectorConfigFilename, vectorConfig) // vector.yaml
+	}
+
+	return b.GetObject(), nil
+}
+
+// kafka-rest.properties
+//
+// The bootstrap servers and the advertised host are only known at runtime and are appended by the container command.
+func (b *RestProxyConfigmapBuilder) buildRestProxyProperties() (string, error) {
+	data := map[string]string{
+		"id":        b.roleGroupInfo.GetFullName(),
+		"listeners": "http://0.0.0.0:" + strconv.Itoa(kafkav1alpha1.RestProxyPort),
+	}
+
+	if b.overrides != nil && b.overrides.ConfigOverrides != nil {
+		maps.Copy(data, b.overrides.ConfigOverrides[kafkav1alpha1.RestProxyFileName])
+	}
+
+	maps.Copy(data, b.clientSecurity.ConfigSettings(restProxyClientPrefix)) // tls, kerberos
+
+	return properties.NewPropertiesFromMap(data).Marshal()
+}
+
+// security properties
+func (b *RestProxyConfigmapBuilder) buildSecurityProperties() (string, error) {
+	if b.overrides != nil && b.overrides.ConfigOverrides != nil {
+		if data, ok := b.overrides.ConfigOverrides[SecurityPropertiesFilename]; ok {
+			return properties.NewPropertiesFromMap(data).Marshal()
+		}
+	}
+	return "", nil
+}
+
+// log4j properties
+func (b *RestProxyConfigmapBuilder) buildLog4jProperties() (string, error) {
+	var loggingSpec *commonsv1alpha1.LoggingConfigSpec
+	if b.roleGroupConfig != nil && b.roleGroupConfig.Logging != nil && b.roleGroupConfig.Logging.Containers != nil {
+		if mainContainerLogging, ok := b.roleGroupConfig.Logging.Containers[RestProxyRoleName]; ok {
+			loggingSpec = &mainContainerLogging
+		}
+	}
+	loggingConfig, err := productlogging.NewConfigGenerator(
+		loggingSpec,
+		RestProxyRoleName,
+		RestProxyLog4jFilename,
+		productlogging.LogTypeLog4j,
+		func(cgo *productlogging.ConfigGeneratorOption) {
+			cgo.ConsoleHandlerFormatter = ptr.To(ConsoleConversionPattern)
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return loggingConfig.Content()
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/pkg"
+)
+
+const (
+	KafkaRestProxyDiscoveryKey = "KAFKA_REST"
+
+	LabelListenerRestProxy = "app.kubernetes.io/listener-rest-proxy"
+)
+
+// RestProxyDiscoveryConfigMapName returns the name of the discovery ConfigMap containing the HTTP endpoints of the REST proxy
+func RestProxyDiscoveryConfigMapName(clusterName string) string {
+	return clusterName + "-" + RestProxyRoleName
+}
+
+func RestProxyListenerName(roleGroupInfo *reconciler.RoleGroupInfo) string {
+	return roleGroupInfo.GetFullName() + "-http"
+}
+
+func RestProxyContainerPorts() []corev1.ContainerPort {
+	return []corev1.ContainerPort{
+		{
+			Name:          kafkav1alpha1.RestProxyPortName,
+			ContainerPort: kafkav1alpha1.RestProxyPort,
+			Protocol:      corev1.ProtocolTCP,
+		},
+	}
+}
+
+func NewRestProxyListenerReconciler(
+	client *client.Client,
+	listenerClass string,
+	info *reconciler.RoleGroupInfo,
+) reconciler.ResourceReconciler[pkg.ListenerBuidler] {
+	builder := pkg.NewListenerBuilder(
+		client,
+		RestProxyListenerName(info),
+		listenerClass,
+		func(lbo *pkg.ListenerBuilderOptions) {
+			lbo.ContainerPorts = RestProxyContainerPorts()
+			lbo.ExtraPodSelectorLabels = map[string]string{
+				LabelListenerRestProxy: LabelValueTrue, // add this label for search in discovery
+			}
+		},
+	)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
+
+// NewRestProxyDiscoveryReconciler creates the discovery ConfigMap of the REST proxy,
+// containing the HTTP endpoints of all REST proxy role groups.
+func NewRestProxyDiscoveryReconciler(
+	client *client.Client,
+	clusterName string,
+) reconciler.ResourceReconciler[builder.ConfigBuilder] {
+	builder := NewHttpDiscoveryBuilder(
+		client,
+		RestProxyDiscoveryConfigMapName(clusterName),
+		LabelListenerRestProxy,
+		kafkav1alpha1.RestProxyPortName,
+		KafkaRestProxyDiscoveryKey,
+	)
+	return reconciler.NewGenericResourceReconciler(client, builder)
+}
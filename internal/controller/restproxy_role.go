@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+)
+
+// RestProxyReconciler reconciles the role groups of the REST proxy of a KafkaCluster
+type RestProxyReconciler struct {
+	reconciler.BaseRoleReconciler[*kafkav1alpha1.RestProxySpec]
+
+	clusterConfig    *kafkav1alpha1.ClusterConfigSpec
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec
+	image            *opgoutil.Image
+	clientSecurity   *security.ClientSecurity
+}
+
+func NewRestProxyReconciler(
+	client *client.Client,
+	roleInfo reconciler.RoleInfo,
+	spec *kafkav1alpha1.RestProxySpec,
+	clusterConfig *kafkav1alpha1.ClusterConfigSpec,
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
+	clientSecurity *security.ClientSecurity,
+) *RestProxyReconciler {
+	stopped := clusterOperation != nil && clusterOperation.Stopped
+
+	return &RestProxyReconciler{
+		BaseRoleReconciler: *reconciler.NewBaseRoleReconciler(
+			client,
+			stopped,
+			roleInfo,
+			spec,
+		),
+		clusterConfig:    clusterConfig,
+		clusterOperation: clusterOperation,
+		image:            NewImage(spec.Image),
+		clientSecurity:   clientSecurity,
+	}
+}
+
+func (r *RestProxyReconciler) RegisterResources(ctx context.Context) error {
+	for name, roleGroup := range r.Spec.RoleGroups {
+		if roleGroup == nil {
+			roleGroup = &kafkav1alpha1.RestProxyRoleGroupSpec{Replicas: 1}
+		}
+		mergedConfig, err := opgoutil.MergeObject(r.Spec.Config, roleGroup.Config)
+		if err != nil {
+			return err
+		}
+		overrides, err := opgoutil.MergeObject(r.Spec.OverridesSpec, roleGroup.OverridesSpec)
+		if err != nil {
+			return err
+		}
+
+		// merge default config to the user provided config
+		if overrides == nil {
+			overrides = &commonsv1alpha1.OverridesSpec{}
+		}
+		if mergedConfig == nil {
+			mergedConfig = &kafkav1alpha1.RestProxyConfigSpec{}
+		}
+		if err := MergeFromUserRestProxyConfig(mergedConfig, overrides, r.GetClusterName()); err != nil {
+			return err
+		}
+
+		info := &reconciler.RoleGroupInfo{
+			RoleInfo:      r.RoleInfo,
+			RoleGroupName: name,
+		}
+		for _, reconciler := range r.registerResourceWithRoleGroup(ctx, roleGroup.Replicas, info, overrides, mergedConfig) {
+			r.AddResource(reconciler)
+			logger.Info("registered resource", "role", r.GetName(), "roleGroup", name, "reconciler", reconciler.GetName())
+		}
+	}
+	return nil
+}
+
+func (r *RestProxyReconciler) registerResourceWithRoleGroup(
+	ctx context.Context,
+	replicas int32,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	overrides *commonsv1alpha1.OverridesSpec,
+	restProxyConfig *kafkav1alpha1.RestProxyConfigSpec,
+) []reconciler.Reconciler {
+	return []reconciler.Reconciler{
+		// headless service of the statefulset
+		NewRoleGroupService(r.Client, roleGroupInfo),
+		// configmap
+		NewRestProxyConfigmapReconciler(
+			r.Client,
+			roleGroupInfo,
+			r.clusterConfig,
+			r.clientSecurity,
+			overrides,
+			restProxyConfig.RoleGroupConfigSpec,
+		),
+		// statefulset
+		NewRestProxyStatefulSetReconciler(
+			ctx,
+			r.Client,
+			r.image,
+			&replicas,
+			r.clusterOperation,
+			roleGroupInfo,
+			restProxyConfig,
+			overrides,
+			r.clientSecurity,
+		),
+		// http listener
+		NewRestProxyListenerReconciler(r.Client, restProxyConfig.ListenerClass, roleGroupInfo),
+	}
+}
@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/security"
+	"github.com/zncdatadev/kafka-operator/internal/util"
+)
+
+const (
+	// RestProxyKerberosServiceName is the service name of the principal used by the REST proxy
+	RestProxyKerberosServiceName = "kafka-rest-proxy"
+
+	// environment variables read by the start scripts of the REST proxy
+	EnvKafkaRestOpts      = "KAFKAREST_OPTS"
+	EnvKafkaRestHeapOpts  = "KAFKAREST_HEAP_OPTS"
+	EnvKafkaRestLog4jOpts = "KAFKAREST_LOG4J_OPTS"
+
+	restProxyRuntimePropertiesPath = "/tmp/" + kafkav1alpha1.RestProxyFileName
+)
+
+func NewRestProxyStatefulSetReconciler(
+	ctx context.Context,
+	client *client.Client,
+	image *opgoutil.Image,
+	replicas *int32,
+	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	restProxyConfig *kafkav1alpha1.RestProxyConfigSpec,
+	overrides *commonsv1alpha1.OverridesSpec,
+	clientSecurity *security.ClientSecurity,
+) reconciler.ResourceReconciler[builder.StatefulSetBuilder] {
+	stopped := clusterOperation != nil && clusterOperation.Stopped
+
+	builder := &RestProxyStatefulSetBuilder{
+		StatefulSet: *builder.NewStatefulSetBuilder(
+			client,
+			roleGroupInfo.GetFullName(),
+			replicas,
+			image,
+			overrides,
+			restProxyConfig.RoleGroupConfigSpec,
+			func(o *builder.Options) {
+				o.ClusterName = roleGroupInfo.ClusterName
+				o.Labels = roleGroupInfo.GetLabels()
+				o.Annotations = roleGroupInfo.GetAnnotations()
+				o.RoleName = roleGroupInfo.RoleName
+				o.RoleGroupName = roleGroupInfo.GetFullName()
+			},
+		),
+		roleGroupInfo:   roleGroupInfo,
+		restProxyConfig: restProxyConfig,
+		clientSecurity:  clientSecurity,
+	}
+	return reconciler.NewStatefulSet(client, builder, stopped)
+}
+
+var _ builder.StatefulSetBuilder = &RestProxyStatefulSetBuilder{}
+
+type RestProxyStatefulSetBuilder struct {
+	builder.StatefulSet
+
+	roleGroupInfo   *reconciler.RoleGroupInfo
+	restProxyConfig *kafkav1alpha1.RestProxyConfigSpec
+	clientSecurity  *security.ClientSecurity
+}
+
+func (b *RestProxyStatefulSetBuilder) GetObject() (*appv1.StatefulSet, error) {
+	tpl, err := b.GetPodTemplate()
+	if err != nil {
+		return nil, err
+	}
+	obj := &appv1.StatefulSet{
+		ObjectMeta: b.GetObjectMeta(),
+		Spec: appv1.StatefulSetSpec{
+			Replicas:             b.GetReplicas(),
+			Selector:             b.GetLabelSelector(),
+			ServiceName:          b.GetName(),
+			Template:             *tpl,
+			VolumeClaimTemplates: b.GetVolumeClaimTemplates(),
+		},
+	}
+	return obj, nil
+}
+
+func (b *RestProxyStatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	b.AddContainer(b.createMainContainer())
+
+	listenerPVC, err := util.NewListenerOperatorVolumeSourceBuilder(
+		&util.ListenerReference{
+			ListenerName: RestProxyListenerName(b.roleGroupInfo),
+		}, b.GetLabels(),
+	).BuildPVC(kafkav1alpha1.KubedoopListenerRestProxy)
+	if err != nil {
+		return nil, err
+	}
+	b.AddVolumeClaimTemplate(listenerPVC)
+
+	b.AddVolumes(b.volumes())
+	b.AddVolumes(b.clientSecurity.Volumes(RestProxyKerberosServiceName, b.restProxyConfig.RequestedSecretLifeTime))
+
+	// vector
+	if IsVectorEnable(b.restProxyConfig.Logging) {
+		vectorFactory := GetVectorFactory(b.GetImage())
+		b.AddContainer(vectorFactory.GetContainer())
+		b.AddVolumes(vectorFactory.GetVolumes())
+	}
+
+	sts, err := b.GetObject()
+	if err != nil {
+		return nil, err
+	}
+
+	sts.Spec.Template.Spec.ServiceAccountName = ServiceAccountName(b.ClusterName)
+	// parallel pod management
+	sts.Spec.PodManagementPolicy = appv1.ParallelPodManagement
+
+	return sts, nil
+}
+
+func (b *RestProxyStatefulSetBuilder) createMainContainer() *corev1.Container {
+	return builder.NewContainerBuilder(RestProxyRoleName, b.GetImage()).
+		AddEnvVars(b.containerEnv()).
+		SetCommand([]string{"bash", "-c"}).
+		SetArgs(b.commandArgs()).
+		AddVolumeMounts(b.volumeMounts()).
+		AddVolumeMounts(b.clientSecurity.VolumeMounts()).
+		SetResources(b.restProxyConfig.Resources).
+		SetReadinessProbe(&corev1.Probe{
+			FailureThreshold:    3,
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       10,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      3,
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/v3/clusters",
+					Port: intstr.FromString(kafkav1alpha1.RestProxyPortName),
+				},
+			},
+		}).
+		SetLivenessProbe(&corev1.Probe{
+			FailureThreshold:    6,
+			InitialDelaySeconds: 30,
+			PeriodSeconds:       30,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      5,
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(kafkav1alpha1.RestProxyPortName)},
+			},
+		}).
+		AddPorts(RestProxyContainerPorts()).
+		Build()
+}
+
+func (b *RestProxyStatefulSetBuilder) containerEnv() []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{
+			Name: EnvPodName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		},
+		{
+			Name: EnvKafkaBootstrapServers,
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: b.roleGroupInfo.ClusterName,
+					},
+					Key: KafkaDiscoveryKey,
+				},
+			},
+		},
+		{
+			Name:  EnvKafkaRestLog4jOpts,
+			Value: fmt.Sprintf("-Dlog4j.configuration=file:%s/%s", kafkav1alpha1.KubedoopLogConfigDir, Log4jPropertiesFilename),
+		},
+		{
+			Name:  EnvKafkaRestOpts,
+			Value: fmt.Sprintf("-Djava.security.properties=%s/%s", kafkav1alpha1.KubedoopConfigDir, SecurityPropertiesFilename),
+		},
+	}
+	envs = append(envs, b.clientSecurity.Envs()...)
+
+	if resources := b.restProxyConfig.Resources; resources != nil && resources.Memory != nil {
+		heap := fmt.Sprintf("-Xmx%dm", int(util.QuantityToMB(resources.Memory.Limit)*0.8))
+		envs = append(envs, corev1.EnvVar{Name: EnvKafkaRestHeapOpts, Value: heap})
+	}
+	return envs
+}
+
+func (b *RestProxyStatefulSetBuilder) commandArgs() []string {
+	podFqdn := util.PodFqdn(b.GetObjectMeta().Namespace, b.GetName())
+
+	var args []string
+	args = append(args, opgoutil.CommonBashTrapFunctions)
+	args = append(args, opgoutil.RemoveVectorShutdownFileCommand())
+	args = append(args, "prepare_signal_handlers")
+
+	if b.clientSecurity.IsKerberosEnabled() {
+		args = append(args, fmt.Sprintf("export KERBEROS_REALM=$(grep -oP 'default_realm = \\K.*' %s)", b.clientSecurity.Krb5Path()))
+	}
+
+	// bootstrap servers and the advertised host are only known at runtime
+	args = append(args, fmt.Sprintf("cp %s/%s %s", kafkav1alpha1.KubedoopConfigDir, kafkav1alpha1.RestProxyFileName, restProxyRuntimePropertiesPath))
+	args = append(args, fmt.Sprintf("echo \"bootstrap.servers=${%s}\" >> %s", EnvKafkaBootstrapServers, restProxyRuntimePropertiesPath))
+	args = append(args, fmt.Sprintf("echo \"host.name=%s\" >> %s", podFqdn, restProxyRuntimePropertiesPath))
+	if b.clientSecurity.IsKerberosEnabled() {
+		// the principal is passed as printf argument, so the shell expands the pod name and realm
+		jaasConfig := b.clientSecurity.KerberosJaasConfig("%s")
+		principal := fmt.Sprintf("%s/%s@${KERBEROS_REALM}", RestProxyKerberosServiceName, podFqdn)
+		args = append(args, fmt.Sprintf("printf '%ssasl.jaas.config=%s\\n' \"%s\" >> %s", restProxyClientPrefix, jaasConfig, principal, restProxyRuntimePropertiesPath))
+	}
+
+	args = append(args, fmt.Sprintf("kafka-rest-start %s &", restProxyRuntimePropertiesPath))
+	args = append(args, opgoutil.InvokeWaitForTermination)
+	args = append(args, opgoutil.CreateVectorShutdownFileCommand())
+
+	return []string{strings.Join(args, "\n")}
+}
+
+func (b *RestProxyStatefulSetBuilder) volumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      kafkav1alpha1.KubedoopConfigDirName,
+			MountPath: kafkav1alpha1.KubedoopConfigDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopLogDirName,
+			MountPath: kafkav1alpha1.KubedoopLogDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopLogConfigDirName,
+			MountPath: kafkav1alpha1.KubedoopLogConfigDir,
+		},
+		{
+			Name:      kafkav1alpha1.KubedoopListenerRestProxy,
+			MountPath: kafkav1alpha1.KubedoopListenerRestProxyDir,
+		},
+	}
+}
+
+func (b *RestProxyStatefulSetBuilder) volumes() []corev1.Volume {
+	configMapVolumeSource := corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: RoleGroupConfigMapName(b.roleGroupInfo),
+			},
+		},
+	}
+	return []corev1.Volume{
+		{
+			Name:         kafkav1alpha1.KubedoopLogDirName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopLogConfigDirName,
+			VolumeSource: configMapVolumeSource,
+		},
+		{
+			Name:         kafkav1alpha1.KubedoopConfigDirName,
+			VolumeSource: configMapVolumeSource,
+		},
+	}
+}
@@ -2,18 +2,30 @@ package controller
 
 import (
 	"context"
+	"maps"
+	"slices"
 
 	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/catalog"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+	"github.com/zncdatadev/kafka-operator/internal/metrics"
 	"github.com/zncdatadev/kafka-operator/internal/security"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var logger = ctrl.Log.WithName("role-reconciler")
 
+// lookupKafkaVersion returns the release line of the brokers from the version catalog, tests replace it
+var lookupKafkaVersion = catalog.Lookup
+
 func NewBrokerReconciler(
 	client *client.Client,
 	roleInfo reconciler.RoleInfo,
@@ -22,6 +34,9 @@ func NewBrokerReconciler(
 	clusterConfig *kafkav1alpha1.ClusterConfigSpec,
 	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
 	kafkaTlsSecurity *security.KafkaSecurity,
+	recorder *event.Recorder,
+	assignedBrokerIdOffsets map[string]int32,
+	protocolVersion string,
 ) *BrokerReconciler {
 
 	stopped := clusterOperation != nil && clusterOperation.Stopped
@@ -37,6 +52,10 @@ func NewBrokerReconciler(
 		clusterConfig:    clusterConfig,
 		clusterOperation: clusterOperation,
 		kafkaTlsSecurity: kafkaTlsSecurity,
+		recorder:         recorder,
+
+		assignedBrokerIdOffsets: assignedBrokerIdOffsets,
+		protocolVersion:         protocolVersion,
 	}
 }
 
@@ -47,9 +66,47 @@ type BrokerReconciler struct {
 	clusterOperation *commonsv1alpha1.ClusterOperationSpec
 	image            *opgoutil.Image
 	kafkaTlsSecurity *security.KafkaSecurity
+	recorder         *event.Recorder
+
+	// bootstrap listener class of each role group, filled by RegisterResources
+	bootstrapListenerClasses map[string]string
+
+	// broker ID offsets of the role groups from the status, see BrokerIdOffsets
+	assignedBrokerIdOffsets map[string]int32
+	// broker ID offset of each role group and ID of each pod, filled by RegisterResources
+	brokerIdOffsets map[string]int32
+	brokerIds       map[string]int32
+
+	// inter.broker.protocol.version pinned by the upgrade of the cluster
+	protocolVersion string
+
+	// role groups a KafkaSnapshot with controlled shutdown can hold brokers of, filled by RegisterResources
+	snapshotHoldRoleGroups []string
 }
 
 func (r *BrokerReconciler) RegisterResources(ctx context.Context) error {
+	kafkaVersion, err := lookupKafkaVersion(r.image.ProductVersion)
+	if err != nil {
+		return err
+	}
+	autoIds, err := r.autoBrokerIdRoleGroups(ctx)
+	if err != nil {
+		return err
+	}
+	brokerIdOffsets, err := BrokerIdOffsets(r.Spec.RoleGroups, r.assignedBrokerIdOffsets, autoIds)
+	if err != nil {
+		return err
+	}
+	r.brokerIdOffsets = brokerIdOffsets
+	r.brokerIds = map[string]int32{}
+
+	r.snapshotHoldRoleGroups, err = r.controlledShutdownSnapshotRoleGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.bootstrapListenerClasses = make(map[string]string, len(r.Spec.RoleGroups))
+	serverProperties := make([]map[string]string, 0, len(r.Spec.RoleGroups))
 	for name, roleGroup := range r.Spec.RoleGroups {
 		mergedConfig, err := opgoutil.MergeObject(r.Spec.Config, roleGroup.Config)
 		if err != nil {
@@ -60,9 +117,20 @@ func (r *BrokerReconciler) RegisterResources(ctx context.Context) error {
 			return err
 		}
 
-		// merge default config to the user provided config
+		// merge default config to the user provided config.
+		// MergeObject returns the role level overrides if the role group has none, they are shared by the role groups.
 		if overrides == nil {
 			overrides = &commonsv1alpha1.OverridesSpec{}
+		} else {
+			overrides = overrides.DeepCopy()
+		}
+		// the defaults of the operator merged below are valid for every release running with ZooKeeper
+		for _, warning := range kafkaVersion.AdaptServerProperties(overrides.ConfigOverrides[ServerPropertiesFilename]) {
+			r.recorder.Warning(r.Client.OwnerReference, EventReasonDeprecatedProperty, "Validate", "role group %s: %s", name, warning)
+		}
+		if _, ok := overrides.ConfigOverrides[ServerPropertiesFilename][InterBrokerProtocolVersionProperty]; ok && r.protocolVersion != "" {
+			r.recorder.Warning(r.Client.OwnerReference, EventReasonInvalidOverride, "Validate",
+				"role group %s: %s is managed by the operator and set to %s", name, InterBrokerProtocolVersionProperty, r.protocolVersion)
 		}
 		if mergedConfig == nil {
 			mergedConfig = &kafkav1alpha1.BrokersConfigSpec{}
@@ -71,14 +139,32 @@ func (r *BrokerReconciler) RegisterResources(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		if err := validateOverrides(name, overrides); err != nil {
+			return err
+		}
+		if err := validateServerPropertiesFrom(name, mergedConfig.ServerPropertiesFrom); err != nil {
+			return err
+		}
+		r.bootstrapListenerClasses[name] = mergedConfig.BootstrapListenerClass
+		serverProperties = append(serverProperties, overrides.ConfigOverrides[ServerPropertiesFilename])
 
 		info := &reconciler.RoleGroupInfo{
 			RoleInfo:      r.RoleInfo,
 			RoleGroupName: name,
 		}
+		// nil for a role group keeping the IDs auto-generated by ZooKeeper
+		var brokerIdOffset *int32
+		if offset, ok := brokerIdOffsets[name]; ok {
+			if err := validateReservedBrokerMaxId(name, offset, roleGroup.Replicas, overrides.ConfigOverrides[ServerPropertiesFilename]); err != nil {
+				return err
+			}
+			maps.Copy(r.brokerIds, BrokerIds(info.GetFullName(), offset, roleGroup.Replicas))
+			brokerIdOffset = &offset
+		}
 		reconcilers, err := r.RegisterResourceWithRoleGroup(
 			ctx,
 			roleGroup.Replicas,
+			brokerIdOffset,
 			info,
 			overrides,
 			mergedConfig,
@@ -92,12 +178,121 @@ func (r *BrokerReconciler) RegisterResources(ctx context.Context) error {
 			logger.Info("registered resource", "role", r.GetName(), "roleGroup", name, "reconciler", reconciler.GetName())
 		}
 	}
+
+	// role pdb, the spec field is not named `RoleConfig`, so BaseRoleReconciler does not pick it up
+	maxUnavailable, enabled, err := r.brokerMaxUnavailable(serverProperties)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		pdb, err := NewBrokerPDBReconciler(r.Client, r.RoleInfo, maxUnavailable)
+		if err != nil {
+			return err
+		}
+		r.AddResource(observeResource(r.recorder, metrics.ResourcePodDisruptionBudget, newPodDisruptionBudget, pdb))
+		logger.Info("registered resource", "role", r.GetName(), "reconciler", pdb.GetName(), "maxUnavailable", maxUnavailable)
+	}
 	return nil
 }
 
+// autoBrokerIdRoleGroups returns the role groups without a broker ID offset whose StatefulSet or first data volume exists.
+// Their brokers wrote data with IDs auto-generated by ZooKeeper before the operator assigned IDs, so they keep them.
+// Offsets picked by the operator are kept in the status before the StatefulSet is created.
+func (r *BrokerReconciler) autoBrokerIdRoleGroups(ctx context.Context) ([]string, error) {
+	var names []string
+	for name, roleGroup := range r.Spec.RoleGroups {
+		if _, ok := r.assignedBrokerIdOffsets[name]; ok || roleGroup.BrokerIdOffset != nil {
+			continue
+		}
+		statefulSetName := (&reconciler.RoleGroupInfo{RoleInfo: r.RoleInfo, RoleGroupName: name}).GetFullName()
+		found, err := r.exists(ctx, statefulSetName, &appv1.StatefulSet{})
+		if err == nil && !found {
+			found, err = r.exists(ctx, DataPvcName(statefulSetName, 0), &corev1.PersistentVolumeClaim{})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// controlledShutdownSnapshotRoleGroups returns the role groups of the KafkaSnapshots with controlled shutdown of the cluster.
+// Only their brokers can be held, the hold is added once such a snapshot is created and kept while it exists,
+// so repeated snapshots do not roll the brokers.
+func (r *BrokerReconciler) controlledShutdownSnapshotRoleGroups(ctx context.Context) ([]string, error) {
+	snapshots := &kafkav1alpha1.KafkaSnapshotList{}
+	if err := r.Client.Client.List(ctx, snapshots, ctrlclient.InNamespace(r.Client.GetOwnerNamespace())); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, snapshot := range snapshots.Items {
+		if snapshot.Spec.ClusterRef == r.GetClusterName() && snapshot.Spec.ControlledShutdown && !slices.Contains(names, snapshot.Spec.RoleGroup) {
+			names = append(names, snapshot.Spec.RoleGroup)
+		}
+	}
+	return names, nil
+}
+
+func (r *BrokerReconciler) exists(ctx context.Context, name string, obj ctrlclient.Object) (bool, error) {
+	if err := r.Client.GetWithOwnerNamespace(ctx, name, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// BrokerIdOffsets returns the broker ID offset of each role group.
+// It must be called after RegisterResources.
+func (r *BrokerReconciler) BrokerIdOffsets() map[string]int32 {
+	return r.brokerIdOffsets
+}
+
+// BrokerIds returns the broker ID of each pod.
+// It must be called after RegisterResources.
+func (r *BrokerReconciler) BrokerIds() map[string]int32 {
+	return r.brokerIds
+}
+
+// BootstrapListenerClasses returns the sorted, distinct bootstrap ListenerClasses used by the role groups.
+// It must be called after RegisterResources.
+func (r *BrokerReconciler) BootstrapListenerClasses() []string {
+	classes := make([]string, 0, len(r.bootstrapListenerClasses))
+	for _, class := range r.bootstrapListenerClasses {
+		if !slices.Contains(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+	slices.Sort(classes)
+	return classes
+}
+
+// PrimaryBootstrapListenerClass returns the ListenerClass published in the `<clusterName>` discovery ConfigMap.
+// It is the role level bootstrap ListenerClass if any role group uses it, otherwise the first one in sorted order.
+func (r *BrokerReconciler) PrimaryBootstrapListenerClass() string {
+	classes := r.BootstrapListenerClasses()
+	if len(classes) == 0 {
+		return ""
+	}
+
+	roleClass := DefaultKafkaConfig(r.GetClusterName()).BootstrapListenerClass
+	if r.Spec.Config != nil && r.Spec.Config.BootstrapListenerClass != "" {
+		roleClass = r.Spec.Config.BootstrapListenerClass
+	}
+	if slices.Contains(classes, roleClass) {
+		return roleClass
+	}
+	return classes[0]
+}
+
 func (r *BrokerReconciler) RegisterResourceWithRoleGroup(
 	ctx context.Context,
 	replicas int32,
+	brokerIdOffset *int32,
 	roleGroupInfo *reconciler.RoleGroupInfo,
 	overrides *commonsv1alpha1.OverridesSpec,
 	brokerConfig *kafkav1alpha1.BrokersConfigSpec,
@@ -107,7 +302,7 @@ func (r *BrokerReconciler) RegisterResourceWithRoleGroup(
 
 	// svc
 	svc := NewRoleGroupService(r.Client, roleGroupInfo)
-	reconcilers = append(reconcilers, svc)
+	reconcilers = append(reconcilers, observeResource(r.recorder, metrics.ResourceService, newService, svc))
 
 	// configmap
 	cm := NewKafkaConfigmapReconciler(
@@ -118,8 +313,22 @@ func (r *BrokerReconciler) RegisterResourceWithRoleGroup(
 		roleGroupInfo,
 		overrides,
 		brokerConfig.RoleGroupConfigSpec,
+		brokerConfig.Metrics,
+		brokerConfig.Jvm,
+		brokerConfig.ServerPropertiesFrom,
 	)
-	reconcilers = append(reconcilers, cm)
+	reconcilers = append(reconcilers, observeResource(r.recorder, metrics.ResourceConfigMap, newConfigMap, cm))
+
+	// data volumes restored from a KafkaSnapshot, they must exist before the StatefulSet creates its pods
+	for _, source := range r.clusterConfig.DataSnapshots {
+		if source.RoleGroup != roleGroupInfo.RoleGroupName {
+			continue
+		}
+		template := newDataPvc(r.Client.GetOwnerNamespace(), roleGroupInfo.GetLabels(), brokerConfig.RoleGroupConfigSpec)
+		restore := NewDataSnapshotRestoreReconciler(r.Client, roleGroupInfo, replicas, template, source.KafkaSnapshot)
+		reconcilers = append(reconcilers, metrics.Instrument(metrics.ResourcePersistentVolumeClaim, restore))
+		break
+	}
 
 	// statefulset
 	sts := NewStatefulSetReconciler(
@@ -127,14 +336,17 @@ func (r *BrokerReconciler) RegisterResourceWithRoleGroup(
 		r.Client,
 		r.image,
 		&replicas,
+		brokerIdOffset,
 		r.clusterConfig,
 		r.clusterOperation,
 		roleGroupInfo,
 		brokerConfig,
 		overrides,
 		r.kafkaTlsSecurity,
+		r.protocolVersion,
+		slices.Contains(r.snapshotHoldRoleGroups, roleGroupInfo.RoleGroupName),
 	)
-	reconcilers = append(reconcilers, sts)
+	reconcilers = append(reconcilers, observeResource(r.recorder, metrics.ResourceStatefulSet, newStatefulSet, sts))
 
 	// role group listener
 	listener := NewRoleGroupBootstrapListenerReconciler(
@@ -143,13 +355,21 @@ func (r *BrokerReconciler) RegisterResourceWithRoleGroup(
 		roleGroupInfo,
 		r.kafkaTlsSecurity,
 	)
-	reconcilers = append(reconcilers, listener)
+	reconcilers = append(reconcilers, observeResource(r.recorder, metrics.ResourceListener, newListener, listener))
 
 	// role group metrics service
-	metricsSvc := NewRoleGroupMetricsService(
-		r.Client,
-		roleGroupInfo,
-	)
-	reconcilers = append(reconcilers, metricsSvc)
+	if IsMetricsEnabled(brokerConfig.Metrics) {
+		metricsSvc := NewRoleGroupMetricsService(
+			r.Client,
+			roleGroupInfo,
+			brokerConfig.Metrics,
+		)
+		reconcilers = append(reconcilers, observeResource(r.recorder, metrics.ResourceService, newService, metricsSvc))
+	}
+
+	// role group ServiceMonitor or PodMonitor
+	if monitor := NewRoleGroupMonitorReconciler(r.Client, roleGroupInfo, r.clusterConfig.Monitoring, brokerConfig.Metrics); monitor != nil {
+		reconcilers = append(reconcilers, metrics.Instrument(metrics.ResourceMonitoring, monitor))
+	}
 	return reconcilers, nil
 }
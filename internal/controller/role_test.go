@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/productlogging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/catalog"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+)
+
+var _ = Describe("BrokerReconciler", func() {
+	It("adapts the server.properties overrides to the release of the brokers", func() {
+		// no supported release removes or renames properties yet
+		lookupKafkaVersion = func(productVersion string) (*catalog.KafkaVersion, error) {
+			return &catalog.KafkaVersion{
+				Line:                 "3.9",
+				Zookeeper:            true,
+				LogType:              productlogging.LogTypeLog4j,
+				RemovedProperties:    []string{"log.message.format.version"},
+				RenamedProperties:    map[string]string{"log.cleaner.io.buffer.load.factor": "log.cleaner.buffer.load.factor"},
+				DeprecatedProperties: []string{"delegation.token.master.key"},
+			}, nil
+		}
+		DeferCleanup(func() { lookupKafkaVersion = catalog.Lookup })
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(kafkav1alpha1.AddToScheme(scheme))
+		utilruntime.Must(listenerv1alpha1.AddToScheme(scheme))
+
+		instance := &kafkav1alpha1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "default", UID: "uid"},
+			Spec: kafkav1alpha1.KafkaClusterSpec{
+				Image:         &kafkav1alpha1.ImageSpec{PullPolicy: ptr.To(corev1.PullIfNotPresent)},
+				ClusterConfig: &kafkav1alpha1.ClusterConfigSpec{ZookeeperConfigMapName: "kafka-znode"},
+				Brokers: &kafkav1alpha1.BrokersSpec{RoleGroups: map[string]*kafkav1alpha1.BrokersRoleGroupSpec{
+					"default": {
+						Replicas: 1,
+						OverridesSpec: &commonsv1alpha1.OverridesSpec{ConfigOverrides: map[string]map[string]string{
+							ServerPropertiesFilename: {
+								"log.message.format.version":        "3.0",
+								"log.cleaner.io.buffer.load.factor": "0.8",
+								"delegation.token.master.key":       "secret",
+							},
+						}},
+					},
+				}},
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(instance, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka-znode", Namespace: "default"},
+				Data:       map[string]string{"ZOOKEEPER": "zookeeper:2181/kafka"},
+			}, &listenerv1alpha1.Listener{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka-broker-default-bootstrap", Namespace: "default"},
+				Status: listenerv1alpha1.ListenerStatus{IngressAddresses: []listenerv1alpha1.IngressAddressSpec{{
+					Address: "kafka-broker-default-bootstrap", AddressType: listenerv1alpha1.AddressTypeHostname,
+					Ports: map[string]int32{"kafka": 9092},
+				}}},
+			}).
+			WithStatusSubresource(&kafkav1alpha1.KafkaCluster{}, &listenerv1alpha1.Listener{}).
+			Build()
+		recorder := events.NewFakeRecorder(100)
+
+		Expect(RenderResources(context.Background(), c, instance, event.NewRecorder(recorder, time.Minute))).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.Background(), ctrlclient.ObjectKey{Namespace: "default", Name: "kafka-broker-default"}, configMap)).To(Succeed())
+		serverProperties := configMap.Data[ServerPropertiesFilename]
+		Expect(serverProperties).NotTo(ContainSubstring("log.message.format.version"))
+		Expect(serverProperties).NotTo(ContainSubstring("log.cleaner.io.buffer.load.factor"))
+		Expect(serverProperties).To(ContainSubstring("log.cleaner.buffer.load.factor=0.8\n"))
+		Expect(serverProperties).To(ContainSubstring("delegation.token.master.key=secret\n"))
+
+		var warnings []string
+		for len(recorder.Events) > 0 {
+			if e := <-recorder.Events; strings.HasPrefix(e, "Warning ") {
+				warnings = append(warnings, e)
+			}
+		}
+		Expect(warnings).To(ConsistOf(
+			"Warning "+EventReasonDeprecatedProperty+" role group default: delegation.token.master.key is deprecated in Kafka 3.9",
+			"Warning "+EventReasonDeprecatedProperty+" role group default: "+
+				"log.cleaner.io.buffer.load.factor is renamed to log.cleaner.buffer.load.factor in Kafka 3.9",
+			"Warning "+EventReasonDeprecatedProperty+" role group default: log.message.format.version is removed in Kafka 3.9 and ignored",
+		))
+	})
+})
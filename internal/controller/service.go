@@ -73,19 +73,21 @@ func (b *ServiceBuilder) GetObject() *corev1.Service {
 }
 
 // NewRoleGroupMetricsService creates a metrics service reconciler using a simple function approach
-// This creates a headless service for metrics with Prometheus labels and annotations
+// This creates a headless service for metrics with Prometheus labels and annotations.
+// The port and scheme follow the metrics config of the role group, nil means the defaults.
 func NewRoleGroupMetricsService(
 	client *client.Client,
 	roleGroupInfo *reconciler.RoleGroupInfo,
+	metrics *kafkav1alpha1.MetricsSpec,
 ) reconciler.Reconciler {
 	// Get metrics port
-	metricsPort := kafkav1alpha1.MetricsPort
+	metricsPort := GetMetricsPort(metrics)
 
 	// Create service ports
 	servicePorts := []corev1.ContainerPort{
 		{
 			Name:          kafkav1alpha1.MetricsPortName,
-			ContainerPort: int32(metricsPort),
+			ContainerPort: metricsPort,
 			Protocol:      corev1.ProtocolTCP,
 		},
 	}
@@ -93,7 +95,7 @@ func NewRoleGroupMetricsService(
 	// Create service name with -metrics suffix
 	serviceName := CreateServiceMetricsName(roleGroupInfo)
 
-	scheme := "http"
+	scheme := GetMetricsScheme(metrics)
 
 	// Prepare labels (copy from roleGroupInfo and add metrics labels)
 	labels := make(map[string]string)
@@ -109,7 +111,7 @@ func NewRoleGroupMetricsService(
 	}
 	annotations["prometheus.io/scrape"] = LabelValueTrue
 	// annotations["prometheus.io/path"] = "/metrics"  // default path is /metrics
-	annotations["prometheus.io/port"] = strconv.Itoa(metricsPort)
+	annotations["prometheus.io/port"] = strconv.Itoa(int(metricsPort))
 	annotations["prometheus.io/scheme"] = scheme
 
 	// Create base service builder
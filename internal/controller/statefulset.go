@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"maps"
 
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/builder"
@@ -25,12 +26,15 @@ func NewStatefulSetReconciler(
 	client *client.Client,
 	image *opgoutil.Image,
 	replicas *int32,
+	brokerIdOffset *int32,
 	clusterConfig *kafkav1alpha1.ClusterConfigSpec,
 	clusterOperation *commonsv1alpha1.ClusterOperationSpec,
 	roleGroupInf *reconciler.RoleGroupInfo,
 	brokerConfig *kafkav1alpha1.BrokersConfigSpec,
 	overrides *commonsv1alpha1.OverridesSpec,
 	kafkaTlsSecurity *security.KafkaSecurity,
+	protocolVersion string,
+	snapshotHold bool,
 ) reconciler.ResourceReconciler[builder.StatefulSetBuilder] {
 	stopped := clusterOperation != nil && clusterOperation.Stopped
 
@@ -39,11 +43,14 @@ func NewStatefulSetReconciler(
 		client,
 		image,
 		replicas,
+		brokerIdOffset,
 		clusterConfig,
 		roleGroupInf,
 		brokerConfig,
 		overrides,
 		kafkaTlsSecurity,
+		protocolVersion,
+		snapshotHold,
 	)
 	return reconciler.NewStatefulSet(client, builder, stopped)
 }
@@ -53,11 +60,14 @@ func NewStatefulSetBuilder(
 	client *client.Client,
 	image *opgoutil.Image,
 	replicas *int32,
+	brokerIdOffset *int32,
 	clusterConfig *kafkav1alpha1.ClusterConfigSpec,
 	roleGroupInf *reconciler.RoleGroupInfo,
 	brokerConfig *kafkav1alpha1.BrokersConfigSpec,
 	overrdes *commonsv1alpha1.OverridesSpec,
 	kafkaTlsSecurity *security.KafkaSecurity,
+	protocolVersion string,
+	snapshotHold bool,
 ) builder.StatefulSetBuilder {
 
 	return &StatefulSetBuilder{
@@ -80,6 +90,9 @@ func NewStatefulSetBuilder(
 		roleGroupInf:     roleGroupInf,
 		brokerConfig:     brokerConfig,
 		kafkaTlsSecurity: kafkaTlsSecurity,
+		brokerIdOffset:   brokerIdOffset,
+		protocolVersion:  protocolVersion,
+		snapshotHold:     snapshotHold,
 	}
 }
 
@@ -91,6 +104,11 @@ type StatefulSetBuilder struct {
 	roleGroupInf     *reconciler.RoleGroupInfo
 	brokerConfig     *kafkav1alpha1.BrokersConfigSpec
 	kafkaTlsSecurity *security.KafkaSecurity
+	brokerIdOffset   *int32
+	// inter.broker.protocol.version of the brokers, see upgrade.go
+	protocolVersion string
+	// whether a KafkaSnapshot with controlled shutdown of the role group exists, see KafkaSnapshotReconciler
+	snapshotHold bool
 }
 
 func (b *StatefulSetBuilder) GetObject() (*appv1.StatefulSet, error) {
@@ -108,12 +126,21 @@ func (b *StatefulSetBuilder) GetObject() (*appv1.StatefulSet, error) {
 			VolumeClaimTemplates: b.GetVolumeClaimTemplates(),
 		},
 	}
+	// lets the upgrade tell the status of this pod template from the one of a former StatefulSet, see brokersRolledOut.
+	// The annotations are shared with the pod template, which must not change with the revision alone.
+	obj.Annotations = maps.Clone(obj.Annotations)
+	metav1.SetMetaDataAnnotation(&obj.ObjectMeta, kafkav1alpha1.UpgradeRevisionAnnotation,
+		upgradeRevision(b.GetImage().ProductVersion, b.protocolVersion))
 	return obj, nil
 }
 
 func (b *StatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
 
-	b.AddContainer(b.createMainContainer())
+	mainContainer, err := b.createMainContainer()
+	if err != nil {
+		return nil, err
+	}
+	b.AddContainer(mainContainer)
 	bootstrapListenerPVC, err := b.bootstrapListenerPvc()
 	if err != nil {
 		return nil, err
@@ -142,6 +169,17 @@ func (b *StatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, erro
 	sts.Spec.Template.Spec.ServiceAccountName = ServiceAccountName(b.ClusterName) // TODO: add set service account name to builder
 	// parallel pod management
 	sts.Spec.PodManagementPolicy = appv1.ParallelPodManagement // TODO: add set pod management policy to builder.
+	if rackAwareness := b.ClusterConfig.RackAwareness; rackAwareness != nil {
+		sts.Spec.Template.Spec.TopologySpreadConstraints = rackSpreadConstraints(rackAwareness, b.ClusterName)
+	}
+	// a restart requested on the cluster changes the pod template, so the StatefulSet rolls the pods
+	restartKey := kafkav1alpha1.RestartAnnotationPrefix + b.roleGroupInf.RoleGroupName
+	if restartedAt, ok := b.Client.GetOwnerReference().GetAnnotations()[restartKey]; ok {
+		if sts.Spec.Template.Annotations == nil {
+			sts.Spec.Template.Annotations = map[string]string{}
+		}
+		sts.Spec.Template.Annotations[kafkav1alpha1.RestartedAtAnnotation] = restartedAt
+	}
 
 	requestLifeTime := b.brokerConfig.RequestedSecretLifeTime
 	b.kafkaTlsSecurity.AddVolumeAndVolumeMounts(sts, requestLifeTime)
@@ -149,8 +187,12 @@ func (b *StatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, erro
 	return sts, nil
 }
 
-func (b *StatefulSetBuilder) createMainContainer() *corev1.Container {
+func (b *StatefulSetBuilder) createMainContainer() (*corev1.Container, error) {
 	image := b.GetImage()
+	jvm, err := NewJvmConfig(b.brokerConfig.Jvm, b.brokerConfig.Resources, KafkaJvmArgs(b.brokerConfig.Metrics))
+	if err != nil {
+		return nil, err
+	}
 
 	kafkaContainer := NewKafkaContainer(
 		image.String(),
@@ -159,18 +201,31 @@ func (b *StatefulSetBuilder) createMainContainer() *corev1.Container {
 		b.kafkaTlsSecurity,
 		b.GetObjectMeta().Namespace,
 		b.GetName(),
+		b.brokerConfig.Metrics,
+		b.ClusterConfig.RackAwareness,
+		b.brokerIdOffset,
+		jvm,
+		// the builder only applies env and cli overrides to a container named after the role, not to the kafka container
+		b.Overrides,
+		b.protocolVersion,
+		b.snapshotHold,
 	)
 	roleGroupConfig := b.brokerConfig.RoleGroupConfigSpec
-	return builder.NewContainerBuilder(kafkaContainer.ContainerName(), image).
+	_, configValueMounts := configValueVolumes(b.brokerConfig.ServerPropertiesFrom)
+	container := builder.NewContainerBuilder(kafkaContainer.ContainerName(), image).
 		AddEnvVars(kafkaContainer.ContainerEnv()).
 		SetCommand(kafkaContainer.Command()).
 		SetArgs(kafkaContainer.CommandArgs()).
 		AddVolumeMounts(kafkaContainer.VolumeMount()).
+		AddVolumeMounts(configValueMounts).
 		SetResources(roleGroupConfig.Resources).
 		SetReadinessProbe(kafkaContainer.ReadinessProbe()).
 		SetLivenessProbe(kafkaContainer.LivenessProbe()).
+		SetStartupProbe(kafkaContainer.StartupProbe()).
 		AddPorts(kafkaContainer.ContainerPorts()).
 		Build()
+	container.Lifecycle = kafkaContainer.Lifecycle() // TODO: add set lifecycle to builder
+	return container, nil
 }
 
 // Volumes
@@ -220,6 +275,17 @@ func (b *StatefulSetBuilder) Volumes() ([]corev1.Volume, error) {
 		},
 	}
 
+	if IsMetricsTlsEnabled(b.brokerConfig.Metrics) {
+		volumes = append(volumes, MetricsTlsVolume(b.brokerConfig.Metrics, b.roleGroupInf, b.brokerConfig.RequestedSecretLifeTime))
+	}
+	if b.ClusterConfig.RackAwareness != nil {
+		volumes = append(volumes, rackVolume())
+	}
+	if b.snapshotHold {
+		volumes = append(volumes, snapshotHoldVolume())
+	}
+	configValueVolumes, _ := configValueVolumes(b.brokerConfig.ServerPropertiesFrom)
+	volumes = append(volumes, configValueVolumes...)
 	if b.kafkaTlsSecurity.IsKerberosEnabled() {
 		volumes = append(volumes, b.kafkaTlsSecurity.KerberosAuth.GetVolumes()...)
 	}
@@ -228,9 +294,13 @@ func (b *StatefulSetBuilder) Volumes() ([]corev1.Volume, error) {
 
 // kafka log dirs pvc
 func (b *StatefulSetBuilder) dataPvc() *corev1.PersistentVolumeClaim {
+	return newDataPvc(b.GetObjectMeta().Namespace, b.GetLabels(), b.brokerConfig.RoleGroupConfigSpec)
+}
+
+// newDataPvc returns the volume claim template of the data volumes of the brokers of a role group
+func newDataPvc(namespace string, labels map[string]string, roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec) *corev1.PersistentVolumeClaim {
 	capabilities := resource.MustParse("2Gi")
 	var storageClassName *string
-	roleGroupConfig := b.brokerConfig.RoleGroupConfigSpec
 	dataStorage := roleGroupConfig.Resources.Storage
 	if dataStorage != nil {
 		capabilities = dataStorage.Capacity
@@ -242,8 +312,8 @@ func (b *StatefulSetBuilder) dataPvc() *corev1.PersistentVolumeClaim {
 	return &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kafkav1alpha1.KubedoopKafkaDataDirName,
-			Namespace: b.GetObjectMeta().Namespace,
-			Labels:    b.GetLabels(),
+			Namespace: namespace,
+			Labels:    labels,
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			VolumeMode:       ptr.To(corev1.PersistentVolumeFilesystem),
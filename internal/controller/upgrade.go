@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+// Upgrades of the brokers to a new Kafka release line run in two rolling restarts:
+//
+//  1. RollingBinaries: the brokers roll to the new release, inter.broker.protocol.version is pinned to the line of the former
+//     release, so reverting the product version rolls them back.
+//  2. Finalizing: once every broker runs the new release and is ready, the pin is dropped and the brokers roll again with
+//     the protocol version of the new line, the default of the release. Downgrades are no longer possible.
+//
+// With autoFinalize false, the upgrade waits in AwaitingFinalization between both restarts.
+// Patch releases of the same line keep the protocol version and are rolled out directly.
+// Outside of an upgrade the protocol version is not pinned, so the brokers do not roll when the operator is upgraded.
+
+// upgradeRequeueInterval is the period the rollout of the brokers is checked at during an upgrade
+const upgradeRequeueInterval = 10 * time.Second
+
+// protocolVersion returns the inter.broker.protocol.version of a Kafka version, its release line
+func protocolVersion(productVersion string) (string, error) {
+	v, err := version.ParseGeneric(productVersion)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d", v.Major(), v.Minor()), nil
+}
+
+// pinnedProtocolVersion returns the inter.broker.protocol.version pinned by an upgrade, empty if the brokers run the default
+func pinnedProtocolVersion(upgrade *kafkav1alpha1.UpgradeStatus) string {
+	if upgrade == nil || upgrade.Phase == kafkav1alpha1.UpgradePhaseFinalizing {
+		return ""
+	}
+	return upgrade.ProtocolVersion
+}
+
+// upgradeRevision identifies the release and pinned protocol version a broker StatefulSet was last written for,
+// kept in its UpgradeRevisionAnnotation
+func upgradeRevision(productVersion, protocolVersion string) string {
+	if protocolVersion == "" {
+		return productVersion
+	}
+	return productVersion + "/" + protocolVersion
+}
+
+// startUpgrade updates the version and upgrade of the status for the desired product version.
+// It returns the inter.broker.protocol.version pinned for the brokers, or ErrDowngradeNotAllowed.
+func startUpgrade(status *kafkav1alpha1.KafkaClusterStatus, desired string) (string, error) {
+	desiredProtocol, err := protocolVersion(desired)
+	if err != nil {
+		return "", err
+	}
+
+	// new clusters and clusters created before the version was tracked
+	if status.CurrentVersion == "" {
+		status.CurrentVersion = desired
+		return "", nil
+	}
+
+	upgrade := status.Upgrade
+	if upgrade == nil {
+		currentProtocol, err := protocolVersion(status.CurrentVersion)
+		if err != nil {
+			return "", err
+		}
+		if desiredProtocol == currentProtocol {
+			status.CurrentVersion = desired
+			return "", nil
+		}
+		if older, err := versionOlder(desired, status.CurrentVersion); err != nil {
+			return "", err
+		} else if older {
+			return "", fmt.Errorf("%w: the brokers run Kafka %s with protocol version %s, they can not run Kafka %s",
+				ErrDowngradeNotAllowed, status.CurrentVersion, currentProtocol, desired)
+		}
+		status.Upgrade = &kafkav1alpha1.UpgradeStatus{
+			Phase:           kafkav1alpha1.UpgradePhaseRollingBinaries,
+			FromVersion:     status.CurrentVersion,
+			ToVersion:       desired,
+			ProtocolVersion: currentProtocol,
+		}
+		return pinnedProtocolVersion(status.Upgrade), nil
+	}
+
+	if upgrade.Phase == kafkav1alpha1.UpgradePhaseFinalizing {
+		if desired != upgrade.ToVersion {
+			return "", fmt.Errorf("%w: the upgrade to Kafka %s is being finalized with protocol version %s",
+				ErrDowngradeNotAllowed, upgrade.ToVersion, upgrade.ProtocolVersion)
+		}
+		return pinnedProtocolVersion(upgrade), nil
+	}
+
+	// before finalization, the brokers still run the protocol version of the former release
+	if desiredProtocol == upgrade.ProtocolVersion {
+		status.CurrentVersion = desired
+		status.Upgrade = nil
+		return "", nil
+	}
+	if older, err := versionOlder(desired, upgrade.FromVersion); err != nil {
+		return "", err
+	} else if older {
+		return "", fmt.Errorf("%w: the brokers run protocol version %s of Kafka %s, they can not run Kafka %s",
+			ErrDowngradeNotAllowed, upgrade.ProtocolVersion, upgrade.FromVersion, desired)
+	}
+	if desired != upgrade.ToVersion {
+		upgrade.ToVersion = desired
+		upgrade.Phase = kafkav1alpha1.UpgradePhaseRollingBinaries
+	}
+	return pinnedProtocolVersion(upgrade), nil
+}
+
+// advanceUpgrade moves the upgrade of the status to its next phase once the brokers are rolled out
+func advanceUpgrade(status *kafkav1alpha1.KafkaClusterStatus, rolledOut, autoFinalize bool) error {
+	upgrade := status.Upgrade
+	if upgrade == nil || !rolledOut {
+		return nil
+	}
+	switch upgrade.Phase {
+	case kafkav1alpha1.UpgradePhaseRollingBinaries, kafkav1alpha1.UpgradePhaseAwaitingFinalization:
+		if !autoFinalize {
+			upgrade.Phase = kafkav1alpha1.UpgradePhaseAwaitingFinalization
+			return nil
+		}
+		toProtocol, err := protocolVersion(upgrade.ToVersion)
+		if err != nil {
+			return err
+		}
+		upgrade.Phase = kafkav1alpha1.UpgradePhaseFinalizing
+		upgrade.ProtocolVersion = toProtocol
+	case kafkav1alpha1.UpgradePhaseFinalizing:
+		status.CurrentVersion = upgrade.ToVersion
+		status.Upgrade = nil
+	}
+	return nil
+}
+
+func versionOlder(a, b string) (bool, error) {
+	va, err := version.ParseGeneric(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := version.ParseGeneric(b)
+	if err != nil {
+		return false, err
+	}
+	return va.LessThan(vb), nil
+}
+
+// upgradeCondition reflects the upgrade of the status in the Upgrading condition, err is a rejected downgrade
+func upgradeCondition(status *kafkav1alpha1.KafkaClusterStatus, generation int64, err error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               kafkav1alpha1.ConditionTypeUpgrading,
+		Status:             metav1.ConditionFalse,
+		Reason:             "UpToDate",
+		Message:            fmt.Sprintf("The brokers run Kafka %s", status.CurrentVersion),
+		ObservedGeneration: generation,
+	}
+	switch {
+	case err != nil:
+		condition.Reason = EventReasonDowngradeRejected
+		condition.Message = err.Error()
+	case status.Upgrade != nil:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = string(status.Upgrade.Phase)
+		condition.Message = fmt.Sprintf("Upgrading the brokers from Kafka %s to %s, protocol version %s",
+			status.Upgrade.FromVersion, status.Upgrade.ToVersion, status.Upgrade.ProtocolVersion)
+	}
+	return condition
+}
+
+// brokersRolledOut returns whether every broker StatefulSet of the cluster was written for the upgrade revision and runs
+// its latest pod template with all pods ready. A StatefulSet read from the cache before the write of the revision shows up
+// with a former one, its status belongs to the former pod template.
+func brokersRolledOut(ctx context.Context, c ctrlclient.Reader, instance *kafkav1alpha1.KafkaCluster, revision string) (bool, error) {
+	statefulSets := &appv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, ctrlclient.InNamespace(instance.Namespace), ctrlclient.MatchingLabels(BrokerPodLabels(instance.Name))); err != nil {
+		return false, err
+	}
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		if sts.Annotations[kafkav1alpha1.UpgradeRevisionAnnotation] != revision || !statefulSetRolledOut(sts) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// statefulSetRolledOut returns whether the StatefulSet runs its latest pod template with all pods ready
+func statefulSetRolledOut(sts *appv1.StatefulSet) bool {
+	replicas := replicas(sts)
+	return sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.UpdatedReplicas == replicas &&
+		sts.Status.ReadyReplicas == replicas &&
+		sts.Status.CurrentRevision == sts.Status.UpdateRevision
+}
+
+// reconcileUpgrade starts, retargets or reverts the upgrade for the product version of the spec.
+// It returns the inter.broker.protocol.version of the brokers, or ErrDowngradeNotAllowed.
+func (r *KafkaClusterReconciler) reconcileUpgrade(ctx context.Context, instance *kafkav1alpha1.KafkaCluster) (string, error) {
+	newStatus := instance.Status.DeepCopy()
+	protocol, upgradeErr := startUpgrade(newStatus, NewImage(instance.Spec.Image).ProductVersion)
+	if upgradeErr != nil && !errors.Is(upgradeErr, ErrDowngradeNotAllowed) {
+		return "", upgradeErr
+	}
+	apimeta.SetStatusCondition(&newStatus.Conditions, upgradeCondition(newStatus, instance.Generation, upgradeErr))
+
+	if err := r.updateUpgradeStatus(ctx, instance, newStatus); err != nil {
+		return "", err
+	}
+	return protocol, upgradeErr
+}
+
+// advanceUpgrade moves the upgrade in progress to its next phase once the brokers are rolled out.
+// The result requeues the cluster until the upgrade is done.
+func (r *KafkaClusterReconciler) advanceUpgrade(ctx context.Context, instance *kafkav1alpha1.KafkaCluster) (ctrl.Result, error) {
+	// a stopped cluster has no brokers to roll, the upgrade resumes once it is started
+	if instance.Status.Upgrade == nil || instance.Spec.ClusterOperation != nil && instance.Spec.ClusterOperation.Stopped {
+		return ctrl.Result{}, nil
+	}
+	revision := upgradeRevision(NewImage(instance.Spec.Image).ProductVersion, pinnedProtocolVersion(instance.Status.Upgrade))
+	rolledOut, err := brokersRolledOut(ctx, r.Client, instance, revision)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	autoFinalize := true
+	if upgrade := instance.Spec.ClusterConfig.Upgrade; upgrade != nil && upgrade.AutoFinalize != nil {
+		autoFinalize = *upgrade.AutoFinalize
+	}
+	newStatus := instance.Status.DeepCopy()
+	if err := advanceUpgrade(newStatus, rolledOut, autoFinalize); err != nil {
+		return ctrl.Result{}, err
+	}
+	apimeta.SetStatusCondition(&newStatus.Conditions, upgradeCondition(newStatus, instance.Generation, nil))
+
+	from, to := instance.Status.Upgrade, newStatus.Upgrade
+	if err := r.updateUpgradeStatus(ctx, instance, newStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case to == nil:
+		r.Recorder.Normal(instance, EventReasonUpgraded, "Upgrade", "Upgraded the brokers from Kafka %s to %s", from.FromVersion, from.ToVersion)
+		return ctrl.Result{}, nil
+	case to.Phase != from.Phase:
+		r.Recorder.Normal(instance, EventReasonUpgraded, "Upgrade", "Upgrade from Kafka %s to %s is %s, protocol version %s",
+			to.FromVersion, to.ToVersion, to.Phase, to.ProtocolVersion)
+	}
+	if to.Phase == kafkav1alpha1.UpgradePhaseAwaitingFinalization {
+		// resumed by setting autoFinalize, which changes the spec
+		return ctrl.Result{}, nil
+	}
+	// the brokers of a new phase roll on the next reconcile, triggered by the status update
+	return ctrl.Result{RequeueAfter: upgradeRequeueInterval}, nil
+}
+
+func (r *KafkaClusterReconciler) updateUpgradeStatus(ctx context.Context, instance *kafkav1alpha1.KafkaCluster, newStatus *kafkav1alpha1.KafkaClusterStatus) error {
+	if equality.Semantic.DeepEqual(&instance.Status, newStatus) {
+		return nil
+	}
+	instance.Status = *newStatus
+	return r.Status().Update(ctx, instance)
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+var _ = Describe("Upgrade", func() {
+	var (
+		ctx        context.Context
+		instance   *kafkav1alpha1.KafkaCluster
+		fakeClient ctrlclient.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		instance = &kafkav1alpha1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "default"},
+			Status:     kafkav1alpha1.KafkaClusterStatus{CurrentVersion: "3.7.2"},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	})
+
+	// writeStatefulSet creates or updates a broker StatefulSet written for the revision, rolled out or still rolling
+	writeStatefulSet := func(name, revision string, rolledOut bool) {
+		sts := &appv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		Expect(ctrlclient.IgnoreNotFound(fakeClient.Get(ctx, ctrlclient.ObjectKeyFromObject(sts), sts))).To(Succeed())
+		sts.Labels = BrokerPodLabels(instance.Name)
+		sts.Annotations = map[string]string{kafkav1alpha1.UpgradeRevisionAnnotation: revision}
+		sts.Spec.Replicas = ptr.To[int32](3)
+		if sts.ResourceVersion == "" {
+			Expect(fakeClient.Create(ctx, sts)).To(Succeed())
+		} else {
+			Expect(fakeClient.Update(ctx, sts)).To(Succeed())
+		}
+		sts.Status = appv1.StatefulSetStatus{
+			Replicas:        3,
+			UpdatedReplicas: 3,
+			ReadyReplicas:   3,
+			CurrentRevision: name + "-1",
+			UpdateRevision:  name + "-1",
+		}
+		if !rolledOut {
+			sts.Status.UpdatedReplicas = 1
+			sts.Status.UpdateRevision = name + "-2"
+		}
+		Expect(fakeClient.Status().Update(ctx, sts)).To(Succeed())
+	}
+
+	// reconcile runs the upgrade like a reconcile of the cluster, it returns the pinned protocol version
+	// the brokers are written with
+	reconcile := func(desired string) string {
+		pinned, err := startUpgrade(&instance.Status, desired)
+		Expect(err).NotTo(HaveOccurred())
+		if instance.Status.Upgrade == nil {
+			return pinned
+		}
+		revision := upgradeRevision(desired, pinnedProtocolVersion(instance.Status.Upgrade))
+		rolledOut, err := brokersRolledOut(ctx, fakeClient, instance, revision)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(advanceUpgrade(&instance.Status, rolledOut, true)).To(Succeed())
+		return pinned
+	}
+
+	It("keeps the protocol version pinned until every StatefulSet rolled out the written revision", func() {
+		writeStatefulSet("kafka-broker-a", "3.7.2", true)
+		writeStatefulSet("kafka-broker-b", "3.7.2", true)
+
+		By("pinning the protocol version of the former release")
+		Expect(reconcile("3.9.0")).To(Equal("3.7"))
+		Expect(instance.Status.Upgrade.Phase).To(Equal(kafkav1alpha1.UpgradePhaseRollingBinaries))
+
+		By("waiting for the StatefulSets still showing the former revision")
+		writeStatefulSet("kafka-broker-a", "3.9.0/3.7", true)
+		Expect(reconcile("3.9.0")).To(Equal("3.7"))
+		Expect(instance.Status.Upgrade.Phase).To(Equal(kafkav1alpha1.UpgradePhaseRollingBinaries))
+
+		By("waiting for the StatefulSets still rolling the written revision")
+		writeStatefulSet("kafka-broker-b", "3.9.0/3.7", false)
+		Expect(reconcile("3.9.0")).To(Equal("3.7"))
+		Expect(instance.Status.Upgrade.Phase).To(Equal(kafkav1alpha1.UpgradePhaseRollingBinaries))
+
+		By("releasing the pin once every StatefulSet rolled out")
+		writeStatefulSet("kafka-broker-b", "3.9.0/3.7", true)
+		Expect(reconcile("3.9.0")).To(Equal("3.7"))
+		Expect(instance.Status.Upgrade.Phase).To(Equal(kafkav1alpha1.UpgradePhaseFinalizing))
+		Expect(instance.Status.Upgrade.ProtocolVersion).To(Equal("3.9"))
+		Expect(reconcile("3.9.0")).To(BeEmpty())
+
+		By("finishing once every StatefulSet rolled out without the pin")
+		Expect(instance.Status.Upgrade).NotTo(BeNil())
+		writeStatefulSet("kafka-broker-a", "3.9.0", true)
+		writeStatefulSet("kafka-broker-b", "3.9.0", true)
+		Expect(reconcile("3.9.0")).To(BeEmpty())
+		Expect(instance.Status.Upgrade).To(BeNil())
+		Expect(instance.Status.CurrentVersion).To(Equal("3.9.0"))
+	})
+
+	It("keeps the pin while awaiting finalization", func() {
+		writeStatefulSet("kafka-broker-a", "3.9.0/3.7", true)
+		pinned, err := startUpgrade(&instance.Status, "3.9.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pinned).To(Equal("3.7"))
+
+		rolledOut, err := brokersRolledOut(ctx, fakeClient, instance, upgradeRevision("3.9.0", pinned))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledOut).To(BeTrue())
+		Expect(advanceUpgrade(&instance.Status, rolledOut, false)).To(Succeed())
+
+		Expect(instance.Status.Upgrade.Phase).To(Equal(kafkav1alpha1.UpgradePhaseAwaitingFinalization))
+		Expect(pinnedProtocolVersion(instance.Status.Upgrade)).To(Equal("3.7"))
+	})
+
+	It("rejects downgrades once the upgrade is finalizing", func() {
+		instance.Status.Upgrade = &kafkav1alpha1.UpgradeStatus{
+			Phase:           kafkav1alpha1.UpgradePhaseFinalizing,
+			FromVersion:     "3.7.2",
+			ToVersion:       "3.9.0",
+			ProtocolVersion: "3.9",
+		}
+
+		_, err := startUpgrade(&instance.Status, "3.7.2")
+		Expect(err).To(MatchError(ErrDowngradeNotAllowed))
+	})
+})
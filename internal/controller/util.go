@@ -3,10 +3,34 @@ package controller
 import (
 	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
 	"github.com/zncdatadev/kafka-operator/internal/security"
+	"github.com/zncdatadev/kafka-operator/internal/util/version"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// NewImage returns the kafka product image described by the image spec
+func NewImage(imageSpec *kafkav1alpha1.ImageSpec) *opgoutil.Image {
+	image := opgoutil.NewImage(
+		kafkav1alpha1.DefaultProductName,
+		version.BuildVersion,
+		kafkav1alpha1.DefaultProductVersion,
+		func(options *opgoutil.ImageOptions) {
+			options.Custom = imageSpec.Custom
+			options.Repo = imageSpec.Repo
+			options.PullPolicy = *imageSpec.PullPolicy
+		},
+	)
+
+	if imageSpec.KubedoopVersion != "" {
+		image.KubedoopVersion = imageSpec.KubedoopVersion
+	}
+	if imageSpec.ProductVersion != "" {
+		image.ProductVersion = imageSpec.ProductVersion
+	}
+	return image
+}
+
 func RoleGroupConfigMapName(roleGroupInfo *reconciler.RoleGroupInfo) string {
 	return roleGroupInfo.GetFullName()
 }
@@ -19,6 +43,7 @@ func BootstrapListenerName(roleGroupInfo *reconciler.RoleGroupInfo) string {
 	return roleGroupInfo.GetFullName() + "-bootstrap"
 }
 
+// KafkaContainerPorts returns the client ports of the brokers, the metrics port is configured per role group, see MetricsContainerPorts
 func KafkaContainerPorts(kafkaTlsSecurity *security.KafkaSecurity) []corev1.ContainerPort {
 	ports := []corev1.ContainerPort{
 		{
@@ -26,11 +51,6 @@ func KafkaContainerPorts(kafkaTlsSecurity *security.KafkaSecurity) []corev1.Cont
 			ContainerPort: int32(kafkaTlsSecurity.ClientPort()),
 			Protocol:      corev1.ProtocolTCP,
 		},
-		{
-			Name:          kafkav1alpha1.MetricsPortName,
-			ContainerPort: kafkav1alpha1.MetricsPort,
-			Protocol:      corev1.ProtocolTCP,
-		},
 	}
 
 	if kafkaTlsSecurity.IsKerberosEnabled() {
@@ -18,7 +18,7 @@ var vectorLogger = ctrl.Log.WithName("vector")
 const ContainerVector = "vector"
 
 func IsVectorEnable(roleLoggingConfig *commonsv1alpha1.LoggingSpec) bool {
-	if roleLoggingConfig != nil {
+	if roleLoggingConfig != nil && roleLoggingConfig.EnableVectorAgent != nil {
 		return *roleLoggingConfig.EnableVectorAgent
 	}
 	return false
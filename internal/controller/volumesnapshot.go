@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+// VolumeSnapshotGVK is the kind of the external snapshotter, the objects are unstructured to not depend on its API
+var VolumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// dataSnapshotPendingInterval is the requeue interval while a KafkaSnapshot to restore from is not succeeded
+const dataSnapshotPendingInterval = 10 * time.Second
+
+// supportsSnapshotHold returns whether the pods of the StatefulSet can be held by a KafkaSnapshot
+func supportsSnapshotHold(sts *appv1.StatefulSet) bool {
+	return slices.ContainsFunc(sts.Spec.Template.Spec.Volumes, func(volume corev1.Volume) bool {
+		return volume.Name == kafkav1alpha1.KubedoopSnapshotHoldDirName
+	})
+}
+
+// snapshotHoldVolume exposes the snapshot hold annotation to the broker, the broker stops while the file is not empty
+func snapshotHoldVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: kafkav1alpha1.KubedoopSnapshotHoldDirName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{
+					{
+						Path:     kafkav1alpha1.SnapshotHoldFileName,
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations['" + kafkav1alpha1.SnapshotHoldAnnotation + "']"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// DataPvcName returns the name of the data volume of the broker pod with the ordinal,
+// as created by the StatefulSet from its volume claim template
+func DataPvcName(statefulSetName string, ordinal int32) string {
+	return fmt.Sprintf("%s-%s-%d", kafkav1alpha1.KubedoopKafkaDataDirName, statefulSetName, ordinal)
+}
+
+// newVolumeSnapshot returns a VolumeSnapshot of the PersistentVolumeClaim pvc
+func newVolumeSnapshot(namespace, name, pvc string, className *string, labels map[string]string) *unstructured.Unstructured {
+	spec := map[string]any{
+		"source": map[string]any{"persistentVolumeClaimName": pvc},
+	}
+	if className != nil {
+		spec["volumeSnapshotClassName"] = *className
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(VolumeSnapshotGVK)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	obj.Object["spec"] = spec
+	return obj
+}
+
+// volumeSnapshotState is the status of a VolumeSnapshot
+type volumeSnapshotState struct {
+	// CreationTime is set once the storage system took the snapshot, the volume may be written again
+	CreationTime *metav1.Time
+	ReadyToUse   bool
+	RestoreSize  *resource.Quantity
+	Error        string
+}
+
+func getVolumeSnapshotState(obj *unstructured.Unstructured) (*volumeSnapshotState, error) {
+	state := &volumeSnapshotState{}
+
+	creationTime, _, err := unstructured.NestedString(obj.Object, "status", "creationTime")
+	if err != nil {
+		return nil, err
+	}
+	if creationTime != "" {
+		t, err := time.Parse(time.RFC3339, creationTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creationTime of VolumeSnapshot %s: %w", obj.GetName(), err)
+		}
+		state.CreationTime = ptr.To(metav1.NewTime(t))
+	}
+
+	if state.ReadyToUse, _, err = unstructured.NestedBool(obj.Object, "status", "readyToUse"); err != nil {
+		return nil, err
+	}
+
+	restoreSize, _, err := unstructured.NestedString(obj.Object, "status", "restoreSize")
+	if err != nil {
+		return nil, err
+	}
+	if restoreSize != "" {
+		q, err := resource.ParseQuantity(restoreSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid restoreSize of VolumeSnapshot %s: %w", obj.GetName(), err)
+		}
+		state.RestoreSize = &q
+	}
+
+	if state.Error, _, err = unstructured.NestedString(obj.Object, "status", "error", "message"); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+var _ reconciler.Reconciler = &DataSnapshotRestoreReconciler{}
+
+// DataSnapshotRestoreReconciler creates the missing data volumes of the brokers of a role group from the
+// VolumeSnapshots of a KafkaSnapshot, before the StatefulSet creates them empty.
+// The StatefulSet uses existing claims named after its volume claim template, existing volumes are not changed.
+// The claims are not owned by the cluster, like the claims created by the StatefulSet.
+type DataSnapshotRestoreReconciler struct {
+	client        *client.Client
+	roleGroupInfo *reconciler.RoleGroupInfo
+	replicas      int32
+	template      *corev1.PersistentVolumeClaim
+	snapshotName  string
+}
+
+func NewDataSnapshotRestoreReconciler(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	replicas int32,
+	template *corev1.PersistentVolumeClaim,
+	snapshotName string,
+) *DataSnapshotRestoreReconciler {
+	return &DataSnapshotRestoreReconciler{
+		client:        client,
+		roleGroupInfo: roleGroupInfo,
+		replicas:      replicas,
+		template:      template,
+		snapshotName:  snapshotName,
+	}
+}
+
+func (r *DataSnapshotRestoreReconciler) GetName() string {
+	return r.roleGroupInfo.GetFullName() + "-" + kafkav1alpha1.KubedoopKafkaDataDirName
+}
+
+func (r *DataSnapshotRestoreReconciler) GetNamespace() string {
+	return r.client.GetOwnerNamespace()
+}
+
+func (r *DataSnapshotRestoreReconciler) GetClient() *client.Client {
+	return r.client
+}
+
+func (r *DataSnapshotRestoreReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	statefulSetName := r.roleGroupInfo.GetFullName()
+
+	var missing []int32
+	for ordinal := range r.replicas {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.client.GetWithOwnerNamespace(ctx, DataPvcName(statefulSetName, ordinal), pvc)
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		missing = append(missing, ordinal)
+	}
+	if len(missing) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// the snapshot is only needed while volumes are missing, it may be deleted after the restore
+	snapshot := &kafkav1alpha1.KafkaSnapshot{}
+	if err := r.client.GetWithOwnerNamespace(ctx, r.snapshotName, snapshot); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("KafkaSnapshot to restore the data volumes from not found, waiting",
+				"kafkaSnapshot", r.snapshotName, "namespace", r.GetNamespace(), "roleGroup", r.roleGroupInfo.RoleGroupName)
+			return ctrl.Result{RequeueAfter: dataSnapshotPendingInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	switch snapshot.Status.Phase {
+	case kafkav1alpha1.SnapshotPhaseSucceeded:
+	case kafkav1alpha1.SnapshotPhaseFailed:
+		return ctrl.Result{}, fmt.Errorf("KafkaSnapshot %s/%s failed: %s", snapshot.Namespace, snapshot.Name, snapshot.Status.Message)
+	default:
+		logger.Info("KafkaSnapshot to restore the data volumes from is not succeeded, waiting",
+			"kafkaSnapshot", r.snapshotName, "namespace", r.GetNamespace(), "phase", snapshot.Status.Phase)
+		return ctrl.Result{RequeueAfter: dataSnapshotPendingInterval}, nil
+	}
+
+	volumes := make(map[int32]*kafkav1alpha1.KafkaVolumeSnapshotStatus, len(snapshot.Status.Volumes))
+	for i := range snapshot.Status.Volumes {
+		volumes[snapshot.Status.Volumes[i].Ordinal] = &snapshot.Status.Volumes[i]
+	}
+
+	for _, ordinal := range missing {
+		volume, ok := volumes[ordinal]
+		if !ok {
+			// more brokers than snapshotted, the StatefulSet creates an empty volume
+			logger.Info("no VolumeSnapshot for the broker, the data volume is created empty",
+				"kafkaSnapshot", r.snapshotName, "namespace", r.GetNamespace(), "statefulSet", statefulSetName, "ordinal", ordinal)
+			continue
+		}
+
+		pvc := r.template.DeepCopy()
+		pvc.Name = DataPvcName(statefulSetName, ordinal)
+		pvc.Namespace = r.GetNamespace()
+		pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: ptr.To(VolumeSnapshotGVK.Group),
+			Kind:     VolumeSnapshotGVK.Kind,
+			Name:     volume.VolumeSnapshot,
+		}
+		// a volume restored from a snapshot can not be smaller than the snapshotted volume
+		if volume.RestoreSize != nil && volume.RestoreSize.Cmp(pvc.Spec.Resources.Requests[corev1.ResourceStorage]) > 0 {
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *volume.RestoreSize
+		}
+
+		if err := r.client.Client.Create(ctx, pvc); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				continue
+			}
+			return ctrl.Result{}, err
+		}
+		logger.Info("data volume created from VolumeSnapshot", "pvc", pvc.Name, "namespace", pvc.Namespace,
+			"volumeSnapshot", volume.VolumeSnapshot)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *DataSnapshotRestoreReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
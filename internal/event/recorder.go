@@ -0,0 +1,79 @@
+// Package event emits Kubernetes Events on the custom resources managed by the operator.
+package event
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultDedupInterval is the interval within which an identical event is only emitted once
+const DefaultDedupInterval = 10 * time.Minute
+
+// Recorder emits events and drops identical events emitted within the dedup interval,
+// so a failing reconcile loop does not flood the API server.
+// A nil Recorder drops all events.
+type Recorder struct {
+	recorder events.EventRecorder
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	emitted map[string]time.Time
+}
+
+func NewRecorder(recorder events.EventRecorder, interval time.Duration) *Recorder {
+	return &Recorder{
+		recorder: recorder,
+		interval: interval,
+		now:      time.Now,
+		emitted:  map[string]time.Time{},
+	}
+}
+
+// Normal emits an event of type Normal on the object
+func (r *Recorder) Normal(obj ctrlclient.Object, reason, action, note string, args ...any) {
+	r.Eventf(obj, nil, corev1.EventTypeNormal, reason, action, note, args...)
+}
+
+// Warning emits an event of type Warning on the object
+func (r *Recorder) Warning(obj ctrlclient.Object, reason, action, note string, args ...any) {
+	r.Eventf(obj, nil, corev1.EventTypeWarning, reason, action, note, args...)
+}
+
+// Eventf emits an event on the object, unless an identical event was emitted within the dedup interval
+func (r *Recorder) Eventf(obj ctrlclient.Object, related runtime.Object, eventType, reason, action, note string, args ...any) {
+	if r == nil {
+		return
+	}
+	message := fmt.Sprintf(note, args...)
+	if !r.shouldEmit(string(obj.GetUID())+"/"+obj.GetNamespace()+"/"+obj.GetName(), eventType, reason, message) {
+		return
+	}
+	r.recorder.Eventf(obj, related, eventType, reason, action, "%s", message)
+}
+
+func (r *Recorder) shouldEmit(object, eventType, reason, message string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	key := object + "/" + eventType + "/" + reason + "/" + message
+	if last, ok := r.emitted[key]; ok && now.Sub(last) < r.interval {
+		return false
+	}
+
+	// drop expired entries, so the map does not grow with every distinct message
+	for k, last := range r.emitted {
+		if now.Sub(last) >= r.interval {
+			delete(r.emitted, k)
+		}
+	}
+	r.emitted[key] = now
+	return true
+}
@@ -0,0 +1,46 @@
+package event
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		fake     *events.FakeRecorder
+		recorder *Recorder
+		now      time.Time
+		obj      *corev1.ConfigMap
+	)
+
+	BeforeEach(func() {
+		fake = events.NewFakeRecorder(10)
+		recorder = NewRecorder(fake, time.Minute)
+		now = time.Now()
+		recorder.now = func() time.Time { return now }
+		obj = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "default", UID: "1"}}
+	})
+
+	It("drops identical events within the dedup interval", func() {
+		recorder.Warning(obj, "ReconcileFailed", "Reconcile", "failed: %s", "boom")
+		recorder.Warning(obj, "ReconcileFailed", "Reconcile", "failed: %s", "boom")
+		recorder.Warning(obj, "ReconcileFailed", "Reconcile", "failed: %s", "other")
+		recorder.Normal(obj, "ReconcileFailed", "Reconcile", "failed: %s", "boom")
+
+		Expect(fake.Events).To(HaveLen(3))
+
+		now = now.Add(time.Minute)
+		recorder.Warning(obj, "ReconcileFailed", "Reconcile", "failed: %s", "boom")
+		Expect(fake.Events).To(HaveLen(4))
+	})
+
+	It("ignores events on a nil recorder", func() {
+		var nilRecorder *Recorder
+		Expect(func() { nilRecorder.Normal(obj, "Created", "Create", "created") }).NotTo(Panic())
+	})
+})
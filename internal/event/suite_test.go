@@ -0,0 +1,14 @@
+package event
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvent(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Event Suite")
+}
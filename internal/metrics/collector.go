@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+)
+
+const collectTimeout = 10 * time.Second
+
+var collectorLogger = ctrl.Log.WithName("metrics-collector")
+
+var (
+	managedClustersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "managed_clusters"),
+		"Number of managed KafkaClusters by product version.",
+		[]string{"product_version"}, nil,
+	)
+	managedBrokersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "managed_brokers"),
+		"Number of desired brokers of the managed KafkaClusters by product version.",
+		[]string{"product_version"}, nil,
+	)
+	clusterConditionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "cluster_conditions"),
+		"Number of KafkaClusters by status condition type and status.",
+		[]string{"type", "status"}, nil,
+	)
+)
+
+var _ prometheus.Collector = &ClusterCollector{}
+
+// ClusterCollector reports the managed KafkaClusters, it lists them from the cache on every scrape
+type ClusterCollector struct {
+	client ctrlclient.Reader
+}
+
+func NewClusterCollector(client ctrlclient.Reader) *ClusterCollector {
+	return &ClusterCollector{client: client}
+}
+
+func (c *ClusterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedClustersDesc
+	ch <- managedBrokersDesc
+	ch <- clusterConditionsDesc
+}
+
+func (c *ClusterCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	clusters := &kafkav1alpha1.KafkaClusterList{}
+	if err := c.client.List(ctx, clusters); err != nil {
+		collectorLogger.Error(err, "Failed to list KafkaClusters")
+		return
+	}
+
+	type condition struct{ conditionType, status string }
+	clustersByVersion := map[string]int{}
+	brokersByVersion := map[string]int32{}
+	clustersByCondition := map[condition]int{}
+
+	for _, cluster := range clusters.Items {
+		version := productVersion(&cluster)
+		clustersByVersion[version]++
+		if cluster.Spec.Brokers != nil {
+			for _, roleGroup := range cluster.Spec.Brokers.RoleGroups {
+				if roleGroup != nil {
+					brokersByVersion[version] += roleGroup.Replicas
+				}
+			}
+		}
+		for _, cond := range cluster.Status.Conditions {
+			clustersByCondition[condition{cond.Type, string(cond.Status)}]++
+		}
+	}
+
+	for version, count := range clustersByVersion {
+		ch <- prometheus.MustNewConstMetric(managedClustersDesc, prometheus.GaugeValue, float64(count), version)
+		ch <- prometheus.MustNewConstMetric(managedBrokersDesc, prometheus.GaugeValue, float64(brokersByVersion[version]), version)
+	}
+	for cond, count := range clustersByCondition {
+		ch <- prometheus.MustNewConstMetric(clusterConditionsDesc, prometheus.GaugeValue, float64(count), cond.conditionType, cond.status)
+	}
+}
+
+func productVersion(cluster *kafkav1alpha1.KafkaCluster) string {
+	if cluster.Spec.Image != nil && cluster.Spec.Image.ProductVersion != "" {
+		return cluster.Spec.Image.ProductVersion
+	}
+	return kafkav1alpha1.DefaultProductVersion
+}
@@ -0,0 +1,73 @@
+package metrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/metrics"
+)
+
+func newCluster(name, productVersion string, replicas ...int32) *kafkav1alpha1.KafkaCluster {
+	cluster := &kafkav1alpha1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: kafkav1alpha1.KafkaClusterSpec{
+			Image:   &kafkav1alpha1.ImageSpec{ProductVersion: productVersion},
+			Brokers: &kafkav1alpha1.BrokersSpec{RoleGroups: map[string]*kafkav1alpha1.BrokersRoleGroupSpec{}},
+		},
+	}
+	for i, r := range replicas {
+		cluster.Spec.Brokers.RoleGroups[string(rune('a'+i))] = &kafkav1alpha1.BrokersRoleGroupSpec{Replicas: r}
+	}
+	return cluster
+}
+
+// gauges returns the values of the gauges of a metric family by the joined label values
+func gauges(families []*dto.MetricFamily, name string) map[string]float64 {
+	values := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			key := ""
+			for _, label := range metric.GetLabel() {
+				key += label.GetValue() + ","
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+	return values
+}
+
+var _ = Describe("ClusterCollector", func() {
+	It("reports the clusters and brokers by product version and the conditions", func() {
+		scheme := runtime.NewScheme()
+		Expect(kafkav1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		ready := newCluster("ready", "3.9.0", 3, 2)
+		ready.Status.Conditions = []metav1.Condition{{Type: "Available", Status: metav1.ConditionTrue}}
+		preparing := newCluster("preparing", "", 1)
+		preparing.Status.Conditions = []metav1.Condition{{Type: "Available", Status: metav1.ConditionFalse}}
+		old := newCluster("old", "3.7.1", 3)
+
+		client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ready, preparing, old).Build()
+		registry := prometheus.NewRegistry()
+		Expect(registry.Register(metrics.NewClusterCollector(client))).To(Succeed())
+
+		families, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(gauges(families, "kafka_operator_managed_clusters")).To(Equal(map[string]float64{"3.9.0,": 2, "3.7.1,": 1}))
+		Expect(gauges(families, "kafka_operator_managed_brokers")).To(Equal(map[string]float64{"3.9.0,": 6, "3.7.1,": 3}))
+		Expect(gauges(families, "kafka_operator_cluster_conditions")).To(Equal(map[string]float64{
+			"True,Available,":  1,
+			"False,Available,": 1,
+		}))
+	})
+})
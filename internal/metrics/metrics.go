@@ -0,0 +1,134 @@
+// Package metrics defines the Prometheus metrics of the operator itself.
+// They are registered on the metrics server of the controller-runtime manager.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "kafka_operator"
+
+// Resource kinds of the sub-reconcilers
+const (
+	ResourceService     = "service"
+	ResourceConfigMap   = "configmap"
+	ResourceStatefulSet = "statefulset"
+	ResourceListener    = "listener"
+	ResourceDiscovery   = "discovery"
+	ResourceMonitoring  = "monitoring"
+
+	ResourcePodDisruptionBudget   = "poddisruptionbudget"
+	ResourcePersistentVolumeClaim = "persistentvolumeclaim"
+)
+
+var (
+	ReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "subreconcile_duration_seconds",
+			Help:      "Duration of the sub-reconcilers creating or updating the resources of a cluster.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		},
+		[]string{"resource"},
+	)
+
+	ReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subreconcile_errors_total",
+			Help:      "Number of errors of the sub-reconcilers creating or updating the resources of a cluster.",
+		},
+		[]string{"resource"},
+	)
+
+	ListenerAddressWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "listener_address_wait_seconds",
+			Help:      "Time from the first discovery attempt until all bootstrap Listeners of a cluster got an address.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"listener_class"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(ReconcileDuration, ReconcileErrors, ListenerAddressWaitDuration)
+}
+
+var _ reconciler.Reconciler = &InstrumentedReconciler{}
+
+// InstrumentedReconciler records the duration and errors of the wrapped reconciler
+type InstrumentedReconciler struct {
+	reconciler.Reconciler
+	resource string
+}
+
+// Instrument wraps the reconciler of a resource of the given kind, e.g. ResourceStatefulSet
+func Instrument(resource string, r reconciler.Reconciler) reconciler.Reconciler {
+	return &InstrumentedReconciler{Reconciler: r, resource: resource}
+}
+
+func (r *InstrumentedReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.Reconciler.Reconcile(ctx)
+	ReconcileDuration.WithLabelValues(r.resource).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ReconcileErrors.WithLabelValues(r.resource).Inc()
+	}
+	return result, err
+}
+
+// WaitTracker measures how long something is waited for across reconciles, keyed by object
+type WaitTracker struct {
+	mu      sync.Mutex
+	started map[string]time.Time
+	now     func() time.Time
+}
+
+func NewWaitTracker() *WaitTracker {
+	return &WaitTracker{started: map[string]time.Time{}, now: time.Now}
+}
+
+// Waiting marks the key as waiting, the first call starts the clock
+func (t *WaitTracker) Waiting(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.started[key]; !ok {
+		t.started[key] = t.now()
+	}
+}
+
+// Done stops the clock of the key and returns the waited duration,
+// ok is false if the key was not waiting
+func (t *WaitTracker) Done(key string) (waited time.Duration, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.started[key]
+	if !ok {
+		return 0, false
+	}
+	delete(t.started, key)
+	return t.now().Sub(start), true
+}
+
+var listenerAddressWaits = NewWaitTracker()
+
+// ListenerAddressesPending records that the bootstrap Listeners of the discovery ConfigMap `key` have no address yet
+func ListenerAddressesPending(key string) {
+	listenerAddressWaits.Waiting(key)
+}
+
+// ListenerAddressesReady observes the time waited for the addresses of the discovery ConfigMap `key`, if it was waiting
+func ListenerAddressesReady(key, listenerClass string) {
+	if waited, ok := listenerAddressWaits.Done(key); ok {
+		ListenerAddressWaitDuration.WithLabelValues(listenerClass).Observe(waited.Seconds())
+	}
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Defaults are the structural schemas of the CRDs by kind, the API server applies their defaults on every write.
+// The fake client does not, so the objects are defaulted before they are reconciled.
+type Defaults map[schema.GroupVersionKind]*structuralschema.Structural
+
+// LoadDefaults reads the CRDs of the YAML files in dir, e.g. config/crd/bases
+func LoadDefaults(dir string) (Defaults, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no CRD found in " + dir)
+	}
+	defaults := Defaults{}
+	for _, file := range files {
+		if err := defaults.load(file); err != nil {
+			return nil, err
+		}
+	}
+	return defaults, nil
+}
+
+func (d Defaults) load(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(document, crd); err != nil {
+			return err
+		}
+		if crd.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			internal := &apiextensions.JSONSchemaProps{}
+			if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(version.Schema.OpenAPIV3Schema, internal, nil); err != nil {
+				return err
+			}
+			structural, err := structuralschema.NewStructural(internal)
+			if err != nil {
+				return err
+			}
+			d[schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}] = structural
+		}
+	}
+}
+
+// Apply sets the defaults of the CRD of the object, objects of other kinds are left as they are
+func (d Defaults) Apply(object runtime.Object, gvk schema.GroupVersionKind) error {
+	structural, ok := d[gvk]
+	if !ok {
+		return nil
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return err
+	}
+	defaulting.Default(u, structural)
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u, object)
+}
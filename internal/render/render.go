@@ -0,0 +1,214 @@
+// Package render prints the resources the operator creates for a KafkaCluster without a Kubernetes cluster.
+// The KafkaCluster is reconciled against a fake client seeded with stand-ins of the objects it depends on,
+// e.g. the ZooKeeper and vector aggregator discovery ConfigMaps or Listeners with their addresses.
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/cisco-open/k8s-objectmatcher/patch"
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/controller"
+	"github.com/zncdatadev/kafka-operator/internal/event"
+)
+
+// DefaultNamespace is the namespace of the objects without one
+const DefaultNamespace = "default"
+
+// ClusterUID is the UID of a KafkaCluster without one, a fixed UID keeps the owner references stable between renders
+const ClusterUID types.UID = "00000000-0000-0000-0000-000000000000"
+
+// renderedLists are the kinds of resources printed, in this order
+var renderedLists = []func() ctrlclient.ObjectList{
+	func() ctrlclient.ObjectList { return &corev1.ServiceAccountList{} },
+	func() ctrlclient.ObjectList { return &corev1.ServiceList{} },
+	func() ctrlclient.ObjectList { return &corev1.ConfigMapList{} },
+	func() ctrlclient.ObjectList { return &appv1.StatefulSetList{} },
+	func() ctrlclient.ObjectList { return &policyv1.PodDisruptionBudgetList{} },
+	func() ctrlclient.ObjectList { return &listenerv1alpha1.ListenerList{} },
+}
+
+// Decode decodes the objects of a multi-document YAML stream with the types of the scheme
+func Decode(scheme *runtime.Scheme, r io.Reader) ([]ctrlclient.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var objects []ctrlclient.Object
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		decoded, _, err := decoder.Decode(document, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := decoded.(ctrlclient.Object)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a Kubernetes object", decoded)
+		}
+		objects = append(objects, object)
+	}
+}
+
+// Render reconciles the only KafkaCluster of the objects, the other objects are the stand-ins it depends on.
+// It returns the resources created for the KafkaCluster, sorted by kind and name. Warnings are written to warnings.
+func Render(
+	ctx context.Context,
+	scheme *runtime.Scheme,
+	defaults Defaults,
+	objects []ctrlclient.Object,
+	warnings io.Writer,
+) ([]ctrlclient.Object, error) {
+	var cluster *kafkav1alpha1.KafkaCluster
+	for _, object := range objects {
+		if object.GetNamespace() == "" {
+			object.SetNamespace(DefaultNamespace)
+		}
+		gvk, err := apiutil.GVKForObject(object, scheme)
+		if err != nil {
+			return nil, err
+		}
+		if err := defaults.Apply(object, gvk); err != nil {
+			return nil, fmt.Errorf("failed to default %s %s: %w", gvk.Kind, object.GetName(), err)
+		}
+		if kafkaCluster, ok := object.(*kafkav1alpha1.KafkaCluster); ok {
+			if cluster != nil {
+				return nil, fmt.Errorf("only one KafkaCluster can be rendered, got %s and %s", cluster.Name, kafkaCluster.Name)
+			}
+			cluster = kafkaCluster
+		}
+	}
+	if cluster == nil {
+		return nil, errors.New("no KafkaCluster to render")
+	}
+	// the owner references of the resources need the UID the API server would assign
+	if cluster.UID == "" {
+		cluster.UID = ClusterUID
+	}
+
+	// the status of Listener stand-ins carries their addresses, it must survive the updates of the operator
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&kafkav1alpha1.KafkaCluster{}, &listenerv1alpha1.Listener{}).
+		Build()
+	recorder := event.NewRecorder(&warningWriter{out: warnings}, event.DefaultDedupInterval)
+	if err := controller.RenderResources(ctx, c, cluster, recorder); err != nil {
+		return nil, err
+	}
+
+	var rendered []ctrlclient.Object
+	for _, newList := range renderedLists {
+		list := newList()
+		if err := c.List(ctx, list, ctrlclient.InNamespace(cluster.Namespace)); err != nil {
+			return nil, err
+		}
+		items, err := ownedItems(list, cluster)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(items, func(a, b ctrlclient.Object) int { return cmp.Compare(a.GetName(), b.GetName()) })
+		rendered = append(rendered, items...)
+	}
+	for _, object := range rendered {
+		gvk, err := apiutil.GVKForObject(object, scheme)
+		if err != nil {
+			return nil, err
+		}
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+		// fields set by the API server, not by the operator
+		object.SetResourceVersion("")
+		object.SetManagedFields(nil)
+		// the last applied object of the patches of the operator repeats the object.
+		// The pod template shares the annotations of its StatefulSet when it is built, so it has one too.
+		object.SetAnnotations(withoutLastApplied(object.GetAnnotations()))
+		if sts, ok := object.(*appv1.StatefulSet); ok {
+			sts.Spec.Template.Annotations = withoutLastApplied(sts.Spec.Template.Annotations)
+		}
+	}
+	return rendered, nil
+}
+
+func withoutLastApplied(annotations map[string]string) map[string]string {
+	delete(annotations, patch.LastAppliedConfig)
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// ownedItems returns the items of the list owned by the cluster, leaving out the stand-ins
+func ownedItems(list ctrlclient.ObjectList, cluster *kafkav1alpha1.KafkaCluster) ([]ctrlclient.Object, error) {
+	items, err := apimeta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	var owned []ctrlclient.Object
+	for _, runtimeItem := range items {
+		item := runtimeItem.(ctrlclient.Object)
+		for _, owner := range item.GetOwnerReferences() {
+			if owner.Kind == "KafkaCluster" && owner.Name == cluster.Name {
+				owned = append(owned, item)
+				break
+			}
+		}
+	}
+	return owned, nil
+}
+
+// Write writes the objects as a multi-document YAML stream, without their status
+func Write(out io.Writer, objects []ctrlclient.Object) error {
+	for _, object := range objects {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return err
+		}
+		delete(u, "status")
+		data, err := yaml.Marshal(u)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warningWriter writes the warning events of the operator, the events of a render have no API server to go to
+type warningWriter struct {
+	out io.Writer
+}
+
+func (w *warningWriter) Eventf(regarding runtime.Object, _ runtime.Object, eventType, reason, _, note string, args ...any) {
+	if eventType != corev1.EventTypeWarning || w.out == nil {
+		return
+	}
+	fmt.Fprintf(w.out, "Warning %s: %s\n", reason, fmt.Sprintf(note, args...))
+}
@@ -0,0 +1,392 @@
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	appv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/render"
+)
+
+// update rewrites the golden files with the rendered resources: go test ./internal/render -update
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kafkav1alpha1.AddToScheme(scheme))
+	utilruntime.Must(listenerv1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func renderFile(scheme *runtime.Scheme, defaults render.Defaults, input string) (string, string, error) {
+	f, err := os.Open(input)
+	Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+	objects, err := render.Decode(scheme, f)
+	Expect(err).NotTo(HaveOccurred())
+
+	var out, warnings bytes.Buffer
+	rendered, err := render.Render(context.Background(), scheme, defaults, objects, &warnings)
+	if err != nil {
+		return "", warnings.String(), err
+	}
+	Expect(render.Write(&out, rendered)).To(Succeed())
+	return out.String(), warnings.String(), nil
+}
+
+var _ = Describe("Render", func() {
+	var (
+		scheme   *runtime.Scheme
+		defaults render.Defaults
+	)
+
+	BeforeEach(func() {
+		scheme = newScheme()
+		var err error
+		defaults, err = render.LoadDefaults(filepath.Join("..", "..", "config", "crd", "bases"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("renders the resources of a KafkaCluster like the golden file",
+		func(name string) {
+			out, warnings, err := renderFile(scheme, defaults, filepath.Join("testdata", name+".yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+
+			golden := filepath.Join("testdata", name+".golden.yaml")
+			if *update {
+				Expect(os.WriteFile(golden, []byte(out), 0o644)).To(Succeed())
+			}
+			expected, err := os.ReadFile(golden)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(string(expected)), "run go test ./internal/render -update to accept the changes")
+		},
+		Entry("with ZooKeeper and Listener stand-ins", "simple"),
+		Entry("with TLS, role groups with their own bootstrap ListenerClass and vector", "tls-role-groups"),
+	)
+
+	It("renders the same resources every time", func() {
+		input := filepath.Join("testdata", "tls-role-groups.yaml")
+		first, _, err := renderFile(scheme, defaults, input)
+		Expect(err).NotTo(HaveOccurred())
+		second, _, err := renderFile(scheme, defaults, input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(Equal(first))
+	})
+
+	It("leaves the stand-ins out", func() {
+		out, _, err := renderFile(scheme, defaults, filepath.Join("testdata", "simple.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).NotTo(ContainSubstring("\n  name: simple-znode\n"))
+	})
+
+	It("deletes the discovery ConfigMaps no longer published", func() {
+		f, err := os.Open(filepath.Join("testdata", "simple.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		objects, err := render.Decode(scheme, f)
+		Expect(err).NotTo(HaveOccurred())
+		stale, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: simple-nodeport
+  ownerReferences:
+  - apiVersion: kafka.kubedoop.dev/v1alpha1
+    kind: KafkaCluster
+    name: simple
+    uid: 00000000-0000-0000-0000-000000000000
+    controller: true
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: simple-external-unstable-discovery
+  labels:
+    app.kubernetes.io/instance: simple
+    app.kubernetes.io/listener-bootstrap-class: external-unstable
+  ownerReferences:
+  - apiVersion: kafka.kubedoop.dev/v1alpha1
+    kind: KafkaCluster
+    name: simple
+    uid: 00000000-0000-0000-0000-000000000000
+    controller: true
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		rendered, err := render.Render(context.Background(), scheme, defaults, append(objects, stale...), nil)
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, object := range rendered {
+			names = append(names, object.GetName())
+		}
+		Expect(names).To(ContainElement("simple-cluster-internal-discovery"))
+		Expect(names).NotTo(ContainElement("simple-nodeport"))
+		Expect(names).NotTo(ContainElement("simple-external-unstable-discovery"))
+	})
+
+	It("allows one unavailable broker with Durability and no replication margin", func() {
+		objects, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaCluster
+metadata:
+  name: durable
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: durable-znode
+  brokers:
+    podDisruptionBudgetPolicy: Durability
+    roleGroups:
+      default:
+        replicas: 3
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		var warnings bytes.Buffer
+		rendered, err := render.Render(context.Background(), scheme, defaults, objects, &warnings)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.String()).To(ContainSubstring("Warning NoReplicationMargin"))
+		var pdb *policyv1.PodDisruptionBudget
+		for _, object := range rendered {
+			if p, ok := object.(*policyv1.PodDisruptionBudget); ok {
+				pdb = p
+			}
+		}
+		Expect(pdb).NotTo(BeNil())
+		Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+	})
+
+	It("warns about the deprecated properties of the role overrides for every role group", func() {
+		objects, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaCluster
+metadata:
+  name: legacy
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: legacy-znode
+  brokers:
+    configOverrides:
+      server.properties:
+        log.message.format.version: "3.0"
+    roleGroups:
+      first:
+        replicas: 1
+      second:
+        replicas: 1
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		var warnings bytes.Buffer
+		_, err = render.Render(context.Background(), scheme, defaults, objects, &warnings)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.String()).To(ContainSubstring("role group first: log.message.format.version is deprecated"))
+		Expect(warnings.String()).To(ContainSubstring("role group second: log.message.format.version is deprecated"))
+	})
+
+	It("lets only the role groups with a controlled shutdown KafkaSnapshot hold their brokers", func() {
+		objects, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaCluster
+metadata:
+  name: snap
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: snap-znode
+  brokers:
+    roleGroups:
+      held:
+        replicas: 1
+      other:
+        replicas: 1
+---
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaSnapshot
+metadata:
+  name: nightly
+spec:
+  clusterRef: snap
+  roleGroup: held
+  controlledShutdown: true
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		rendered, err := render.Render(context.Background(), scheme, defaults, objects, nil)
+		Expect(err).NotTo(HaveOccurred())
+		holdVolumes := map[string]bool{}
+		for _, object := range rendered {
+			if sts, ok := object.(*appv1.StatefulSet); ok {
+				for _, volume := range sts.Spec.Template.Spec.Volumes {
+					holdVolumes[sts.Name] = holdVolumes[sts.Name] || volume.Name == kafkav1alpha1.KubedoopSnapshotHoldDirName
+				}
+			}
+		}
+		Expect(holdVolumes).To(Equal(map[string]bool{"snap-broker-held": true, "snap-broker-other": false}))
+	})
+
+	It("starts the brokers without a rack if none is assigned in time", func() {
+		objects, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaCluster
+metadata:
+  name: zoned
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: zoned-znode
+    rackAwareness:
+      nodeLabel: topology.kubernetes.io/zone
+  brokers:
+    roleGroups:
+      default:
+        replicas: 3
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		rendered, err := render.Render(context.Background(), scheme, defaults, objects, nil)
+		Expect(err).NotTo(HaveOccurred())
+		var command string
+		for _, object := range rendered {
+			if sts, ok := object.(*appv1.StatefulSet); ok {
+				command = strings.Join(sts.Spec.Template.Spec.Containers[0].Args, "\n")
+			}
+		}
+		Expect(command).To(ContainSubstring(`[ "${rack_wait}" -lt 300 ]`))
+		Expect(command).To(ContainSubstring("starting without a rack"))
+		Expect(command).To(ContainSubstring(`${BROKER_RACK:+--override "broker.rack=${BROKER_RACK}"}`))
+	})
+
+	Describe("broker IDs", func() {
+		launchCommands := func(rendered []ctrlclient.Object) map[string]string {
+			commands := map[string]string{}
+			for _, object := range rendered {
+				if sts, ok := object.(*appv1.StatefulSet); ok {
+					commands[sts.Name] = strings.Join(sts.Spec.Template.Spec.Containers[0].Args, "\n")
+				}
+			}
+			return commands
+		}
+
+		It("keeps the auto-generated IDs of a role group whose brokers already wrote data", func() {
+			f, err := os.Open(filepath.Join("testdata", "simple.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+			objects, err := render.Decode(scheme, f)
+			Expect(err).NotTo(HaveOccurred())
+			existing, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-simple-broker-default-0
+`))
+			Expect(err).NotTo(HaveOccurred())
+
+			rendered, err := render.Render(context.Background(), scheme, defaults, append(objects, existing...), nil)
+			Expect(err).NotTo(HaveOccurred())
+			command := launchCommands(rendered)["simple-broker-default"]
+			Expect(command).To(ContainSubstring("bin/kafka-server-start.sh"))
+			Expect(command).NotTo(ContainSubstring("broker.id="))
+		})
+
+		It("reserves enough ID blocks for role groups with more than 100 replicas", func() {
+			objects, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaCluster
+metadata:
+  name: large
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: large-znode
+  brokers:
+    roleGroups:
+      big:
+        replicas: 150
+      fixed:
+        replicas: 1
+        brokerIdOffset: 100
+`))
+			Expect(err).NotTo(HaveOccurred())
+
+			rendered, err := render.Render(context.Background(), scheme, defaults, objects, nil)
+			Expect(err).NotTo(HaveOccurred())
+			commands := launchCommands(rendered)
+			Expect(commands["large-broker-big"]).To(ContainSubstring("export BROKER_ID=$((${POD_NAME##*-} + 200))"))
+			Expect(commands["large-broker-fixed"]).To(ContainSubstring("export BROKER_ID=$((${POD_NAME##*-} + 100))"))
+		})
+	})
+
+	Describe("upgrades", func() {
+		renderBrokers := func(status string) *appv1.StatefulSet {
+			objects, err := render.Decode(scheme, strings.NewReader(`
+apiVersion: kafka.kubedoop.dev/v1alpha1
+kind: KafkaCluster
+metadata:
+  name: simple
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: simple-znode
+  brokers:
+    roleGroups:
+      default:
+        replicas: 1
+status:
+`+status))
+			Expect(err).NotTo(HaveOccurred())
+
+			rendered, err := render.Render(context.Background(), scheme, defaults, objects, nil)
+			Expect(err).NotTo(HaveOccurred())
+			for _, object := range rendered {
+				if sts, ok := object.(*appv1.StatefulSet); ok {
+					return sts
+				}
+			}
+			Fail("no broker StatefulSet rendered")
+			return nil
+		}
+
+		It("does not pin the protocol version of a cluster running the desired release", func() {
+			sts := renderBrokers(`  currentVersion: 3.9.0`)
+			Expect(sts.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement(ContainSubstring("inter.broker.protocol.version")))
+			Expect(sts.Annotations).To(HaveKeyWithValue(kafkav1alpha1.UpgradeRevisionAnnotation, "3.9.0"))
+		})
+
+		It("pins the protocol version of the former release while the binaries roll", func() {
+			sts := renderBrokers(`  currentVersion: 3.8.1`)
+			Expect(sts.Spec.Template.Spec.Containers[0].Args).To(ContainElement(ContainSubstring(`--override "inter.broker.protocol.version=3.8"`)))
+			Expect(sts.Annotations).To(HaveKeyWithValue(kafkav1alpha1.UpgradeRevisionAnnotation, "3.9.0/3.8"))
+			Expect(sts.Spec.Template.Annotations).NotTo(HaveKey(kafkav1alpha1.UpgradeRevisionAnnotation))
+		})
+
+		It("drops the pin once the upgrade is finalizing", func() {
+			sts := renderBrokers(`  currentVersion: 3.8.1
+  upgrade:
+    phase: Finalizing
+    fromVersion: 3.8.1
+    toVersion: 3.9.0
+    protocolVersion: "3.9"`)
+			Expect(sts.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement(ContainSubstring("inter.broker.protocol.version")))
+			Expect(sts.Annotations).To(HaveKeyWithValue(kafkav1alpha1.UpgradeRevisionAnnotation, "3.9.0"))
+		})
+	})
+
+	It("requires exactly one KafkaCluster", func() {
+		objects, err := render.Decode(scheme, strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: only\n"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = render.Render(context.Background(), scheme, defaults, objects, nil)
+		Expect(err).To(MatchError("no KafkaCluster to render"))
+	})
+})
@@ -0,0 +1,198 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+
+	kafkav1alpha1 "github.com/zncdatadev/kafka-operator/api/v1alpha1"
+	"github.com/zncdatadev/kafka-operator/internal/util"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Client directories, used by Kafka clients deployed by the operator (e.g. Kafka Connect workers)
+const (
+	KubedoopTLSTrustStoreClientDir     = kafkav1alpha1.KubedoopRoot + "/tls_truststore_client"
+	KubedoopTLSTrustStoreClientDirName = "tls-truststore-client"
+	KubedoopKerberosClientDir          = kafkav1alpha1.KubedoopRoot + "/kerberos_client"
+	KubedoopKerberosClientDirName      = "kerberos-client"
+)
+
+const (
+	ClientSecurityProtocolPlaintext = "PLAINTEXT"
+	ClientSecurityProtocolSsl       = "SSL"
+	ClientSecurityProtocolSaslSsl   = "SASL_SSL"
+)
+
+const DefaultBrokerKerberosServiceName = "kafka"
+
+// ClientSecurity describes how a client deployed by the operator connects to the brokers of a Kafka cluster
+type ClientSecurity struct {
+	// Name distinguishes the volumes of several clusters in the same pod, the default volumes are used if empty
+	Name string
+
+	ServerSecretClass   string
+	SSLStorePassword    string
+	KerberosSecretClass string
+	// service name of the broker principals
+	BrokerKerberosServiceName string
+}
+
+// Client returns the security settings of clients of this cluster
+func (k *KafkaSecurity) Client() *ClientSecurity {
+	client := &ClientSecurity{
+		ServerSecretClass: k.TlsServerSecretClass(),
+		SSLStorePassword:  k.SSLStorePassword,
+	}
+	if k.IsKerberosEnabled() {
+		client.KerberosSecretClass = k.KerberosAuth.Role.GetKerberosSecretClass()
+		client.BrokerKerberosServiceName = k.KerberosAuth.Role.KerberosServiceName()
+	}
+	return client
+}
+
+func (c *ClientSecurity) suffix() string {
+	if c.Name == "" {
+		return ""
+	}
+	return "_" + c.Name
+}
+
+func (c *ClientSecurity) TrustStoreDir() string {
+	return KubedoopTLSTrustStoreClientDir + c.suffix()
+}
+
+func (c *ClientSecurity) TrustStoreVolumeName() string {
+	return strings.ReplaceAll(KubedoopTLSTrustStoreClientDirName+c.suffix(), "_", "-")
+}
+
+func (c *ClientSecurity) KerberosDir() string {
+	return KubedoopKerberosClientDir + c.suffix()
+}
+
+func (c *ClientSecurity) KerberosVolumeName() string {
+	return strings.ReplaceAll(KubedoopKerberosClientDirName+c.suffix(), "_", "-")
+}
+
+func (c *ClientSecurity) Krb5Path() string {
+	return c.KerberosDir() + "/krb5.conf"
+}
+
+func (c *ClientSecurity) KeytabPath() string {
+	return c.KerberosDir() + "/keytab"
+}
+
+func (c *ClientSecurity) IsKerberosEnabled() bool {
+	return c.KerberosSecretClass != ""
+}
+
+// SecurityProtocol returns the `security.protocol` a client must use to connect to the brokers
+func (c *ClientSecurity) SecurityProtocol() string {
+	if c.IsKerberosEnabled() {
+		return ClientSecurityProtocolSaslSsl
+	}
+	if c.ServerSecretClass != "" {
+		return ClientSecurityProtocolSsl
+	}
+	return ClientSecurityProtocolPlaintext
+}
+
+// ConfigSettings returns the client properties required to connect to the brokers,
+// every key is prefixed with `prefix`, e.g. `producer.` or `consumer.` for Kafka Connect workers.
+//
+// The Kerberos JAAS configuration is not part of the settings, because the principal
+// is only known at runtime, see KerberosJaasConfig.
+func (c *ClientSecurity) ConfigSettings(prefix string) map[string]string {
+	config := map[string]string{
+		prefix + "security.protocol": c.SecurityProtocol(),
+	}
+
+	if c.ServerSecretClass != "" {
+		config[prefix+"ssl.truststore.location"] = fmt.Sprintf("%s/truststore.p12", c.TrustStoreDir())
+		config[prefix+"ssl.truststore.type"] = PKCS12
+		if c.SSLStorePassword != "" {
+			config[prefix+"ssl.truststore.password"] = c.SSLStorePassword
+		}
+	}
+
+	if c.IsKerberosEnabled() {
+		serviceName := c.BrokerKerberosServiceName
+		if serviceName == "" {
+			serviceName = DefaultBrokerKerberosServiceName
+		}
+		config[prefix+"sasl.mechanism"] = "GSSAPI"
+		config[prefix+"sasl.kerberos.service.name"] = serviceName
+	}
+	return config
+}
+
+// KerberosJaasConfig returns the `sasl.jaas.config` value for a client authenticating with the keytab
+// provisioned by the secret-operator. The principal may contain shell variables, e.g. `$KERBEROS_REALM`.
+func (c *ClientSecurity) KerberosJaasConfig(principal string) string {
+	return fmt.Sprintf(
+		"com.sun.security.auth.module.Krb5LoginModule required useKeyTab=true storeKey=true keyTab=\"%s\" principal=\"%s\";",
+		c.KeytabPath(),
+		principal,
+	)
+}
+
+// Volumes returns the secret-operator volumes holding the client truststore and Kerberos keytab.
+// kerberosServiceName is the service name of the principal requested from the Kerberos SecretClass.
+func (c *ClientSecurity) Volumes(kerberosServiceName string, requestedSecretLifeTime string) []corev1.Volume {
+	var volumes []corev1.Volume
+	if c.ServerSecretClass != "" {
+		builder := util.SecretVolumeBuilder{VolumeName: c.TrustStoreVolumeName()}
+		builder.SetAnnotations(map[string]string{
+			constants.AnnotationSecretsClass:  c.ServerSecretClass,
+			constants.AnnotationSecretsScope:  string(constants.PodScope),
+			constants.AnnotationSecretsFormat: string(constants.TLSP12),
+		})
+		if c.SSLStorePassword != "" {
+			builder.AddAnnotation(constants.AnnotationSecretsPKCS12Password, c.SSLStorePassword)
+		}
+		if requestedSecretLifeTime != "" {
+			builder.AddAnnotation(constants.AnnotationSecretCertLifeTime, requestedSecretLifeTime)
+		}
+		volumes = append(volumes, builder.Build())
+	}
+
+	if c.IsKerberosEnabled() {
+		builder := util.SecretVolumeBuilder{VolumeName: c.KerberosVolumeName()}
+		builder.SetAnnotations(map[string]string{
+			constants.AnnotationSecretsClass:                c.KerberosSecretClass,
+			constants.AnnotationSecretsScope:                strings.Join([]string{string(constants.PodScope), string(constants.NodeScope)}, constants.CommonDelimiter),
+			constants.AnnotationSecretsKerberosServiceNames: kerberosServiceName,
+		})
+		volumes = append(volumes, builder.Build())
+	}
+	return volumes
+}
+
+// VolumeMounts returns the volume mounts for the volumes of Volumes
+func (c *ClientSecurity) VolumeMounts() []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	if c.ServerSecretClass != "" {
+		mounts = append(mounts, corev1.VolumeMount{Name: c.TrustStoreVolumeName(), MountPath: c.TrustStoreDir()})
+	}
+	if c.IsKerberosEnabled() {
+		mounts = append(mounts, corev1.VolumeMount{Name: c.KerberosVolumeName(), MountPath: c.KerberosDir()})
+	}
+	return mounts
+}
+
+// Envs returns the environment variables required by the Kerberos client
+func (c *ClientSecurity) Envs() []corev1.EnvVar {
+	if !c.IsKerberosEnabled() {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  "KRB5_CONFIG",
+			Value: c.Krb5Path(),
+		},
+		{
+			Name:  "KAFKA_OPTS",
+			Value: fmt.Sprintf("-Djava.security.krb5.conf=%s", c.Krb5Path()),
+		},
+	}
+}
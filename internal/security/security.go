@@ -206,6 +206,8 @@ func (k *KafkaSecurity) KcatClientAuthSsl(certDirectory string) []string {
 		"-X", fmt.Sprintf("ssl.key.location=%s/tls.key", certDirectory),
 		"-X", fmt.Sprintf("ssl.certificate.location=%s/tls.crt", certDirectory),
 		"-X", fmt.Sprintf("ssl.ca.location=%s/ca.crt", certDirectory),
+		// the prober connects to localhost, which is not in the certificate
+		"-X", "ssl.endpoint.identification.algorithm=none",
 	}
 }
 
@@ -214,6 +216,7 @@ func (k *KafkaSecurity) KcatClientSsl(certDirectory string) []string {
 	return []string{
 		"-X", "security.protocol=SSL",
 		"-X", fmt.Sprintf("ssl.ca.location=%s/ca.crt", certDirectory),
+		"-X", "ssl.endpoint.identification.algorithm=none",
 	}
 }
 
@@ -221,7 +224,6 @@ func (k *KafkaSecurity) KcatClientSsl(certDirectory string) []string {
 func (k *KafkaSecurity) AddVolumeAndVolumeMounts(sts *appsv1.StatefulSet, requestLifeTime string) {
 	kafkaContainer := k.getContainer(sts.Spec.Template.Spec.Containers, "kafka")
 	if tlsServerSecretClass := k.TlsServerSecretClass(); tlsServerSecretClass != "" {
-		// cbKcatProber.AddVolumeMount(KubedoopTLSCertServerDirName, KubedoopTLSCertServerDir) todo
 		k.AddVolume(sts, CreateTlsKeystoreVolume(
 			KubedoopTLSKeyStoreServerDirName,
 			tlsServerSecretClass,
@@ -229,6 +231,9 @@ func (k *KafkaSecurity) AddVolumeAndVolumeMounts(sts *appsv1.StatefulSet, reques
 			requestLifeTime,
 		))
 		k.AddVolumeMount(kafkaContainer, KubedoopTLSKeyStoreServerDirName, KubedoopTLSKeyStoreServerDir)
+		// PEM certificates of the kcat prober
+		k.AddVolume(sts, CreateTlsCertVolume(KubedoopTLSCertServerDirName, tlsServerSecretClass, requestLifeTime))
+		k.AddVolumeMount(kafkaContainer, KubedoopTLSCertServerDirName, KubedoopTLSCertServerDir)
 	}
 
 	if tlsInternalSecretClass := k.TlsInternalSecretClass(); tlsInternalSecretClass != "" {
@@ -353,3 +358,23 @@ func CreateTlsKeystoreVolume(volumeName, secretClass, sslStorePassword, requeste
 	}
 	return builder.Build()
 }
+
+// CreateTlsCertVolume creates a volume with the PEM certificate, key and CA of the secret class,
+// for clients not reading PKCS12 stores like kcat
+func CreateTlsCertVolume(volumeName, secretClass, requestedSecretLifeTime string) corev1.Volume {
+	builder := util.SecretVolumeBuilder{VolumeName: volumeName}
+
+	secretScopes := []string{
+		string(constants.PodScope),
+		string(constants.NodeScope),
+	}
+	builder.SetAnnotations(map[string]string{
+		constants.AnnotationSecretsClass:  secretClass,
+		constants.AnnotationSecretsScope:  strings.Join(secretScopes, constants.CommonDelimiter),
+		constants.AnnotationSecretsFormat: string(constants.TLSPEM),
+	})
+	if requestedSecretLifeTime != "" {
+		builder.AddAnnotation(constants.AnnotationSecretCertLifeTime, requestedSecretLifeTime)
+	}
+	return builder.Build()
+}
@@ -1,6 +1,10 @@
 package util
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -38,3 +42,19 @@ func ConvertToResourceRequirements(resources *commonsv1alpha1.ResourcesSpec) *co
 func QuantityToMB(quantity resource.Quantity) float64 {
 	return (float64(quantity.Value() / (1024 * 1024)))
 }
+
+// MemoryShare resolves a share of the total memory, given as a percentage, e.g. `50%`, or as a quantity, e.g. `2Gi`, to bytes
+func MemoryShare(share string, total resource.Quantity) (int64, error) {
+	if percent, ok := strings.CutSuffix(share, "%"); ok {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil || value < 0 || value > 100 {
+			return 0, fmt.Errorf("invalid memory percentage %q", share)
+		}
+		return int64(float64(total.Value()) * value / 100), nil
+	}
+	quantity, err := resource.ParseQuantity(share)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory quantity %q: %w", share, err)
+	}
+	return quantity.Value(), nil
+}
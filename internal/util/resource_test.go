@@ -73,3 +73,33 @@ var _ = Describe("QuantityToMB", func() {
 		})
 	})
 })
+
+var _ = Describe("MemoryShare", func() {
+	total := resource.MustParse("4Gi")
+
+	It("should resolve a percentage of the total", func() {
+		result, err := util.MemoryShare("25%", total)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(int64(1024 * 1024 * 1024)))
+	})
+
+	It("should resolve a quantity regardless of the total", func() {
+		result, err := util.MemoryShare("512Mi", total)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(int64(512 * 1024 * 1024)))
+	})
+
+	It("should reject percentages above 100", func() {
+		_, err := util.MemoryShare("120%", total)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject malformed values", func() {
+		_, err := util.MemoryShare("lots", total)
+
+		Expect(err).To(HaveOccurred())
+	})
+})